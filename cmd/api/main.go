@@ -14,8 +14,10 @@ import (
 	"opendomain/internal/config"
 	"opendomain/internal/handler"
 	"opendomain/internal/i18n"
+	"opendomain/internal/redirect"
 	"opendomain/internal/router"
 	"opendomain/internal/scanner"
+	"opendomain/internal/whois"
 	"opendomain/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -75,28 +77,154 @@ func main() {
 		healthScanner.StartPeriodicScanning(scannerCtx, 1*time.Hour)
 	}()
 
-	// 启动过期域名自动清理任务
+	// 启动过期域名生命周期任务（宽限期 -> 赎回期 -> 待删除 -> 释放）
 	domainHandler := handler.NewDomainHandler(db, cfg)
 	go func() {
-		logger.Info("Starting periodic expired domain cleanup (every 24 hours)...")
-		ticker := time.NewTicker(24 * time.Hour)
+		logger.Info("Starting periodic domain lifecycle processing (every 1 hour)...")
+		ticker := time.NewTicker(1 * time.Hour)
 		defer ticker.Stop()
 
-		// 立即执行一次清理
-		domainHandler.CleanupExpiredDomains(30) // 删除过期超过 30 天的域名
+		// 立即执行一次
+		domainHandler.ProcessDomainLifecycle()
 
 		for {
 			select {
 			case <-ticker.C:
-				logger.Info("Running scheduled expired domain cleanup...")
-				domainHandler.CleanupExpiredDomains(30)
+				domainHandler.ProcessDomainLifecycle()
 			case <-scannerCtx.Done():
-				logger.Info("Stopping expired domain cleanup task...")
+				logger.Info("Stopping domain lifecycle task...")
 				return
 			}
 		}
 	}()
 
+	// 启动域名自动续费任务
+	go func() {
+		logger.Info("Starting periodic domain auto-renewal (every 1 hour)...")
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		domainHandler.RunAutoRenewals()
+
+		for {
+			select {
+			case <-ticker.C:
+				domainHandler.RunAutoRenewals()
+			case <-scannerCtx.Done():
+				logger.Info("Stopping domain auto-renewal task...")
+				return
+			}
+		}
+	}()
+
+	// 启动域名到期提醒邮件任务
+	go func() {
+		logger.Info("Starting periodic domain expiry reminders (every 1 hour)...")
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		domainHandler.SendExpiryReminders()
+
+		for {
+			select {
+			case <-ticker.C:
+				domainHandler.SendExpiryReminders()
+			case <-scannerCtx.Done():
+				logger.Info("Stopping domain expiry reminder task...")
+				return
+			}
+		}
+	}()
+
+	// 启动过期域名转移处理任务
+	go func() {
+		logger.Info("Starting periodic domain transfer expiry (every 1 hour)...")
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		domainHandler.ExpireDomainTransfers()
+
+		for {
+			select {
+			case <-ticker.C:
+				domainHandler.ExpireDomainTransfers()
+			case <-scannerCtx.Done():
+				logger.Info("Stopping domain transfer expiry task...")
+				return
+			}
+		}
+	}()
+
+	// 启动过期 ACME 验证记录清理任务
+	dnsHandler := handler.NewDNSHandler(db, cfg)
+	go func() {
+		logger.Info("Starting periodic ACME challenge cleanup (every 10 minutes)...")
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				dnsHandler.CleanupExpiredACMEChallenges()
+			case <-scannerCtx.Done():
+				logger.Info("Stopping ACME challenge cleanup task...")
+				return
+			}
+		}
+	}()
+
+	// 启动数据库与 PowerDNS 的定期对账任务
+	go func() {
+		logger.Info("Starting periodic DNS reconciliation (every 30 minutes)...")
+		ticker := time.NewTicker(30 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				logger.Info("Running scheduled DNS reconciliation...")
+				dnsHandler.ReconcileAll()
+			case <-scannerCtx.Done():
+				logger.Info("Stopping DNS reconciliation task...")
+				return
+			}
+		}
+	}()
+
+	// 启动 DNS 故障转移健康检查任务（每个组按自己的间隔探测）
+	go func() {
+		logger.Info("Starting DNS failover monitor (every 30 seconds)...")
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				dnsHandler.RunFailoverChecks(scannerCtx)
+			case <-scannerCtx.Done():
+				logger.Info("Stopping DNS failover monitor...")
+				return
+			}
+		}
+	}()
+
+	// 启动 URL 重定向服务
+	var redirectServer *redirect.Server
+	if cfg.Redirect.Enabled {
+		redirectServer = redirect.NewServer(db, cfg)
+		redirectServer.Start()
+	}
+
+	// 启动 WHOIS 服务
+	var whoisServer *whois.Server
+	if cfg.WHOIS.Enabled {
+		whoisServer = whois.NewServer(db, rdb, cfg)
+		if err := whoisServer.Start(); err != nil {
+			logger.Errorf("Failed to start WHOIS service on %s: %v", cfg.WHOIS.Addr, err)
+			whoisServer = nil
+		}
+	}
+
 	// 创建 HTTP 服务器
 	srv := &http.Server{
 		Addr:           fmt.Sprintf(":%s", cfg.Port),
@@ -128,6 +256,16 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Errorf("Server forced to shutdown: %v", err)
 	}
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			logger.Errorf("Redirect service forced to shutdown: %v", err)
+		}
+	}
+	if whoisServer != nil {
+		if err := whoisServer.Shutdown(ctx); err != nil {
+			logger.Errorf("WHOIS service forced to shutdown: %v", err)
+		}
+	}
 
 	logger.Info("Server exited")
 }
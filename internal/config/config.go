@@ -29,6 +29,10 @@ type Config struct {
 	Scanner  ScannerConfig
 	Payment  PaymentConfig
 	DNS          DNSConfig
+	Redirect     RedirectConfig
+	Domain       DomainConfig
+	RDAP         RDAPConfig
+	WHOIS        WHOISConfig
 	OAuth        OAuthConfig
 	Telegram     TelegramConfig
 	FOSSBilling  FOSSBillingConfig
@@ -83,10 +87,48 @@ type PaymentConfig struct {
 }
 
 type DNSConfig struct {
+	Provider   string // powerdns 或 memory（仅用于本地开发）
 	DefaultNS1 string
 	DefaultNS2 string
 }
 
+// RedirectConfig URL 重定向服务配置
+type RedirectConfig struct {
+	Enabled      bool
+	HTTPAddr     string
+	HTTPSAddr    string
+	IPv4         string // URL 记录解析到的地址
+	IPv6         string
+	ACMEEmail    string
+	CertCacheDir string
+}
+
+// DomainConfig 域名生命周期配置
+type DomainConfig struct {
+	TransferExpireDays   int    // 转移请求等待接收方确认的天数
+	AutoRenewDays        int    // 到期前多少天开始自动续费
+	AutoRenewMaxAttempts int    // 每个续费周期最多尝试次数
+	AutoRenewRetryHours  int    // 失败后的重试间隔
+	ReminderFirstDays    int    // 到期前多少天发送第一次提醒邮件
+	ReminderSecondDays   int    // 到期前多少天发送第二次提醒邮件
+	RenewalLinkSecret    string // 续期链接的签名密钥，为空时使用 JWT 密钥
+}
+
+// RDAPConfig RDAP 查询服务配置
+type RDAPConfig struct {
+	BaseURL            string // 对外的 RDAP 地址（如 https://example.com/rdap），为空时按请求地址生成
+	RedactPersonalData bool   // 是否隐藏注册人的姓名和邮箱
+	AbuseEmail         string // 滥用举报联系邮箱
+	HandleSecret       string // 生成注册人 handle 的密钥，为空时使用 JWT 密钥
+}
+
+// WHOISConfig WHOIS (端口 43) 查询服务配置，注册人信息的隐藏方式与 RDAP 相同
+type WHOISConfig struct {
+	Enabled   bool
+	Addr      string
+	RateLimit int // 每个客户端 IP 每分钟最多查询次数
+}
+
 type OAuthConfig struct {
 	GithubClientID     string
 	GithubClientSecret string
@@ -185,10 +227,44 @@ func Load() (*Config, error) {
 		},
 
 		DNS: DNSConfig{
+			Provider:   viper.GetString("DNS_PROVIDER"),
 			DefaultNS1: viper.GetString("DEFAULT_NS1"),
 			DefaultNS2: viper.GetString("DEFAULT_NS2"),
 		},
 
+		Redirect: RedirectConfig{
+			Enabled:      viper.GetBool("REDIRECT_ENABLED"),
+			HTTPAddr:     viper.GetString("REDIRECT_HTTP_ADDR"),
+			HTTPSAddr:    viper.GetString("REDIRECT_HTTPS_ADDR"),
+			IPv4:         viper.GetString("REDIRECT_IPV4"),
+			IPv6:         viper.GetString("REDIRECT_IPV6"),
+			ACMEEmail:    viper.GetString("REDIRECT_ACME_EMAIL"),
+			CertCacheDir: viper.GetString("REDIRECT_CERT_CACHE_DIR"),
+		},
+
+		Domain: DomainConfig{
+			TransferExpireDays:   viper.GetInt("DOMAIN_TRANSFER_EXPIRE_DAYS"),
+			AutoRenewDays:        viper.GetInt("DOMAIN_AUTO_RENEW_DAYS"),
+			AutoRenewMaxAttempts: viper.GetInt("DOMAIN_AUTO_RENEW_MAX_ATTEMPTS"),
+			AutoRenewRetryHours:  viper.GetInt("DOMAIN_AUTO_RENEW_RETRY_HOURS"),
+			ReminderFirstDays:    viper.GetInt("DOMAIN_REMINDER_FIRST_DAYS"),
+			ReminderSecondDays:   viper.GetInt("DOMAIN_REMINDER_SECOND_DAYS"),
+			RenewalLinkSecret:    viper.GetString("DOMAIN_RENEWAL_LINK_SECRET"),
+		},
+
+		RDAP: RDAPConfig{
+			BaseURL:            viper.GetString("RDAP_BASE_URL"),
+			RedactPersonalData: viper.GetBool("RDAP_REDACT_PERSONAL_DATA"),
+			AbuseEmail:         viper.GetString("RDAP_ABUSE_EMAIL"),
+			HandleSecret:       viper.GetString("RDAP_HANDLE_SECRET"),
+		},
+
+		WHOIS: WHOISConfig{
+			Enabled:   viper.GetBool("WHOIS_ENABLED"),
+			Addr:      viper.GetString("WHOIS_ADDR"),
+			RateLimit: viper.GetInt("WHOIS_RATE_LIMIT"),
+		},
+
 		OAuth: OAuthConfig{
 			GithubClientID:     viper.GetString("GITHUB_CLIENT_ID"),
 			GithubClientSecret: viper.GetString("GITHUB_CLIENT_SECRET"),
@@ -237,9 +313,28 @@ func setDefaults() {
 	viper.SetDefault("PAYMENT_CALLBACK_URL", fmt.Sprintf("http://localhost:%s/api/payments/callback", viper.GetString("PORT")))
 	viper.SetDefault("PAYMENT_TEST_MODE", false)
 
+	viper.SetDefault("DNS_PROVIDER", "powerdns")
 	viper.SetDefault("DEFAULT_NS1", "ns1.nodelook.com")
 	viper.SetDefault("DEFAULT_NS2", "ns2.nodelook.com")
 
+	viper.SetDefault("REDIRECT_ENABLED", false)
+	viper.SetDefault("REDIRECT_HTTP_ADDR", ":80")
+	viper.SetDefault("REDIRECT_HTTPS_ADDR", ":443")
+	viper.SetDefault("REDIRECT_CERT_CACHE_DIR", "data/redirect-certs")
+
+	viper.SetDefault("DOMAIN_TRANSFER_EXPIRE_DAYS", 7)
+	viper.SetDefault("DOMAIN_AUTO_RENEW_DAYS", 7)
+	viper.SetDefault("DOMAIN_AUTO_RENEW_MAX_ATTEMPTS", 5)
+	viper.SetDefault("DOMAIN_AUTO_RENEW_RETRY_HOURS", 12)
+	viper.SetDefault("DOMAIN_REMINDER_FIRST_DAYS", 30)
+	viper.SetDefault("DOMAIN_REMINDER_SECOND_DAYS", 7)
+
+	viper.SetDefault("RDAP_REDACT_PERSONAL_DATA", true)
+
+	viper.SetDefault("WHOIS_ENABLED", false)
+	viper.SetDefault("WHOIS_ADDR", ":43")
+	viper.SetDefault("WHOIS_RATE_LIMIT", 30)
+
 	viper.SetDefault("FOSSBILLING_ENABLED", false)
 }
 
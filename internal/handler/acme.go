@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+	"opendomain/pkg/timeutil"
+)
+
+const (
+	// acmeChallengeLabel DNS-01 验证记录的标签
+	acmeChallengeLabel = "_acme-challenge"
+	// acmeChallengeLifetime 验证记录的最长保留时间，超时后自动清理
+	acmeChallengeLifetime = time.Hour
+	// acmeChallengeTTL 验证记录的 TTL
+	acmeChallengeTTL = 60
+)
+
+// ACMEPresent 发布 ACME DNS-01 验证 TXT 记录（兼容 lego httpreq 提供者的 /present）
+func (h *DNSHandler) ACMEPresent(c *gin.Context) {
+	h.handleACMEChallenge(c, true)
+}
+
+// ACMECleanup 删除 ACME DNS-01 验证 TXT 记录（兼容 lego httpreq 提供者的 /cleanup）
+func (h *DNSHandler) ACMECleanup(c *gin.Context) {
+	h.handleACMEChallenge(c, false)
+}
+
+// handleACMEChallenge 处理 present/cleanup 请求
+// 使用 HTTP Basic 认证，密码为 acme 类型的域名令牌，只能修改该域名下 _acme-challenge 的 TXT 记录集
+func (h *DNSHandler) handleACMEChallenge(c *gin.Context, present bool) {
+	_, password, hasAuth := c.Request.BasicAuth()
+	if !hasAuth {
+		c.Header("WWW-Authenticate", `Basic realm="ACME"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	token, err := h.authenticateDomainToken(password, "acme")
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").First(&domain, token.DomainID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+	// 与 DynDNS 相同：宽限期内解析仍然生效，证书续期也需要继续工作
+	if domain.Status != "active" && domain.Status != models.DomainStatusGrace {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Domain is not active"})
+		return
+	}
+	if !domain.UseDefaultNameservers {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Cannot manage DNS records for domains using custom nameservers",
+			"use_custom_ns": true,
+		})
+		return
+	}
+
+	var req models.ACMEChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fqdn, value, err := resolveACMEChallenge(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name, err := acmeRecordName(fqdn, domain.FullDomain)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if present {
+		challenge := models.ACMEChallenge{
+			DomainID:  domain.ID,
+			Name:      name,
+			Value:     value,
+			ExpiresAt: timeutil.Now().Add(acmeChallengeLifetime),
+		}
+		if err := h.db.Where("domain_id = ? AND name = ? AND value = ?", domain.ID, name, value).
+			Assign(models.ACMEChallenge{ExpiresAt: challenge.ExpiresAt}).
+			FirstOrCreate(&challenge).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save challenge"})
+			return
+		}
+	} else {
+		if err := h.db.Where("domain_id = ? AND name = ? AND value = ?", domain.ID, name, value).
+			Delete(&models.ACMEChallenge{}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete challenge"})
+			return
+		}
+	}
+
+	if err := h.publishACMEChallenges(&domain, name); err != nil {
+		if present {
+			h.db.Where("domain_id = ? AND name = ? AND value = ?", domain.ID, name, value).Delete(&models.ACMEChallenge{})
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to update PowerDNS: %v", err)})
+		return
+	}
+
+	h.db.Model(token).Updates(map[string]interface{}{
+		"last_used_at": timeutil.Now(),
+		"last_used_ip": c.ClientIP(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "OK", "fqdn": ensureTrailingDot(fqdn)})
+}
+
+// publishACMEChallenges 将某个名称下未过期的验证值与用户自己的 TXT 记录合并后写入 PowerDNS
+func (h *DNSHandler) publishACMEChallenges(domain *models.Domain, name string) error {
+	rrsets, err := h.buildRRsets(h.db, domain, []rrsetKey{{Name: name, Type: "TXT"}})
+	if err != nil {
+		return err
+	}
+	return h.patchZoneRRsets(domain.FullDomain, rrsets)
+}
+
+// restoreACMEChallenges 单条记录同步会整体替换 TXT 记录集，之后需要重新合并未过期的验证值
+func (h *DNSHandler) restoreACMEChallenges(record *models.DNSRecord, domain *models.Domain) {
+	if record.Type != "TXT" || !isACMEChallengeName(record.Name) {
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.ACMEChallenge{}).Where("domain_id = ? AND name = ? AND expires_at > ?",
+		domain.ID, record.Name, timeutil.Now()).Count(&count)
+	if count == 0 {
+		return
+	}
+
+	if err := h.publishACMEChallenges(domain, record.Name); err != nil {
+		fmt.Printf("Warning: Failed to restore ACME challenges for %s.%s: %v\n", record.Name, domain.FullDomain, err)
+	}
+}
+
+// acmeChallengeEntries 返回某个名称下未过期的验证值
+func acmeChallengeEntries(db *gorm.DB, domainID uint, name string) ([]powerdns.RecordEntry, error) {
+	var challenges []models.ACMEChallenge
+	if err := db.Where("domain_id = ? AND name = ? AND expires_at > ?", domainID, name, timeutil.Now()).
+		Order("id ASC").Find(&challenges).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]powerdns.RecordEntry, 0, len(challenges))
+	for _, ch := range challenges {
+		entries = append(entries, powerdns.RecordEntry{Content: `"` + ch.Value + `"`})
+	}
+	return entries, nil
+}
+
+// isACMEChallengeName 判断相对记录名是否为 _acme-challenge 名称
+func isACMEChallengeName(name string) bool {
+	return name == acmeChallengeLabel || strings.HasPrefix(name, acmeChallengeLabel+".")
+}
+
+// CleanupExpiredACMEChallenges 清理过期的 ACME 验证记录
+func (h *DNSHandler) CleanupExpiredACMEChallenges() {
+	var expired []models.ACMEChallenge
+	if err := h.db.Where("expires_at <= ?", timeutil.Now()).Find(&expired).Error; err != nil {
+		fmt.Printf("Warning: Failed to query expired ACME challenges: %v\n", err)
+		return
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	type challengeSet struct {
+		domainID uint
+		name     string
+	}
+	sets := make(map[challengeSet]bool)
+	ids := make([]uint, 0, len(expired))
+	for _, ch := range expired {
+		sets[challengeSet{domainID: ch.DomainID, name: ch.Name}] = true
+		ids = append(ids, ch.ID)
+	}
+
+	if err := h.db.Where("id IN ?", ids).Delete(&models.ACMEChallenge{}).Error; err != nil {
+		fmt.Printf("Warning: Failed to delete expired ACME challenges: %v\n", err)
+		return
+	}
+
+	for set := range sets {
+		var domain models.Domain
+		if err := h.db.First(&domain, set.domainID).Error; err != nil {
+			continue
+		}
+		if err := h.publishACMEChallenges(&domain, set.name); err != nil {
+			fmt.Printf("Warning: Failed to remove expired ACME challenge %s.%s: %v\n", set.name, domain.FullDomain, err)
+		}
+	}
+
+	fmt.Printf("Cleaned up %d expired ACME challenges\n", len(expired))
+}
+
+// resolveACMEChallenge 根据请求模式计算验证记录的 FQDN 和 TXT 值
+func resolveACMEChallenge(req *models.ACMEChallengeRequest) (string, string, error) {
+	// RAW 模式：根据 keyAuth 计算 TXT 值
+	if req.KeyAuth != "" {
+		domain := strings.TrimPrefix(strings.TrimSuffix(req.Domain, "."), "*.")
+		if domain == "" {
+			return "", "", fmt.Errorf("domain is required")
+		}
+		sum := sha256.Sum256([]byte(req.KeyAuth))
+		return acmeChallengeLabel + "." + domain, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+	}
+
+	if req.FQDN == "" || req.Value == "" {
+		return "", "", fmt.Errorf("fqdn and value are required")
+	}
+	if len(req.Value) > 255 || strings.ContainsAny(req.Value, "\" \\") {
+		return "", "", fmt.Errorf("invalid challenge value")
+	}
+	return strings.TrimSuffix(req.FQDN, "."), req.Value, nil
+}
+
+// acmeRecordName 校验 FQDN 是否为域名下的 _acme-challenge 名称，返回相对记录名
+func acmeRecordName(fqdn, fullDomain string) (string, error) {
+	fqdn = strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	if fqdn != fullDomain && !strings.HasSuffix(fqdn, "."+fullDomain) {
+		return "", fmt.Errorf("%s does not belong to %s", fqdn, fullDomain)
+	}
+
+	name := extractRecordName(fqdn, fullDomain)
+	if !isACMEChallengeName(name) {
+		return "", fmt.Errorf("only %s records can be managed with this token", acmeChallengeLabel)
+	}
+	return name, nil
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+)
+
+// errInsufficientBalance 余额不足以完成扣款
+var errInsufficientBalance = errors.New("insufficient balance")
+
+// GetBalance 获取当前用户的余额和最近的余额流水
+func (h *UserHandler) GetBalance(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var transactions []models.BalanceTransaction
+	if err := h.db.Where("user_id = ?", userID).Order("id DESC").Limit(100).Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch balance transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"balance":      user.Balance,
+		"transactions": transactions,
+	})
+}
+
+// AdminAdjustBalance 管理员：为用户充值（正数）或扣减（负数）余额，扣减后不能为负
+func (h *UserHandler) AdminAdjustBalance(c *gin.Context) {
+	var user models.User
+	if err := h.db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req models.BalanceAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if roundMoney(req.Amount) == 0 || math.Abs(req.Amount) > 1000000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be non-zero and at most 1000000"})
+		return
+	}
+
+	var transaction *models.BalanceTransaction
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = adjustBalance(tx, user.ID, req.Amount, models.BalanceTypeAdjust, nil, req.Description)
+		return err
+	})
+	if errors.Is(err, errInsufficientBalance) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Balance cannot become negative"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Balance adjusted successfully",
+		"balance":     transaction.BalanceAfter,
+		"transaction": transaction,
+	})
+}
+
+// adjustBalance 在事务中锁定用户行并变更余额，同时写入流水
+// amount 为负且余额不足时返回 errInsufficientBalance
+func adjustBalance(tx *gorm.DB, userID uint, amount float64, txType string, orderID *uint, description string) (*models.BalanceTransaction, error) {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	amount = roundMoney(amount)
+	balance := roundMoney(user.Balance + amount)
+	if balance < 0 {
+		return nil, errInsufficientBalance
+	}
+
+	if err := tx.Model(&user).UpdateColumn("balance", balance).Error; err != nil {
+		return nil, err
+	}
+
+	transaction := &models.BalanceTransaction{
+		UserID:       userID,
+		Amount:       amount,
+		BalanceAfter: balance,
+		Type:         txType,
+		OrderID:      orderID,
+		Description:  description,
+	}
+	if err := tx.Create(transaction).Error; err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// roundMoney 金额保留两位小数
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
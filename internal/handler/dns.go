@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -12,6 +13,7 @@ import (
 	"opendomain/internal/config"
 	"opendomain/internal/middleware"
 	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
 	"opendomain/pkg/powerdns"
 	"opendomain/pkg/timeutil"
 )
@@ -19,17 +21,41 @@ import (
 type DNSHandler struct {
 	db   *gorm.DB
 	cfg  *config.Config
-	pdns *powerdns.Client
+	pdns dnsprovider.Provider
 }
 
 func NewDNSHandler(db *gorm.DB, cfg *config.Config) *DNSHandler {
+	return NewDNSHandlerWithProvider(db, cfg, defaultDNSProvider(cfg))
+}
+
+// NewDNSHandlerWithProvider 使用指定的 DNS 后端创建处理器
+func NewDNSHandlerWithProvider(db *gorm.DB, cfg *config.Config, provider dnsprovider.Provider) *DNSHandler {
 	return &DNSHandler{
 		db:   db,
 		cfg:  cfg,
-		pdns: powerdns.NewClient(cfg.PowerDNS.APIURL, cfg.PowerDNS.APIKey),
+		pdns: provider,
 	}
 }
 
+var (
+	sharedDNSProvider     dnsprovider.Provider
+	sharedDNSProviderOnce sync.Once
+)
+
+// defaultDNSProvider 按配置创建所有处理器共享的 DNS 后端
+// 内存后端必须共享同一个实例，否则各处理器看到的 zone 不一致
+func defaultDNSProvider(cfg *config.Config) dnsprovider.Provider {
+	sharedDNSProviderOnce.Do(func() {
+		provider, err := dnsprovider.New(cfg.DNS.Provider, cfg.PowerDNS.APIURL, cfg.PowerDNS.APIKey)
+		if err != nil {
+			fmt.Printf("Warning: %v, falling back to PowerDNS\n", err)
+			provider = powerdns.NewClient(cfg.PowerDNS.APIURL, cfg.PowerDNS.APIKey)
+		}
+		sharedDNSProvider = provider
+	})
+	return sharedDNSProvider
+}
+
 // ensureCanonicalNS 确保 nameserver 是规范格式（以 . 结尾）
 func ensureCanonicalNS(nameservers []string) []string {
 	canonical := make([]string, len(nameservers))
@@ -140,6 +166,11 @@ func (h *DNSHandler) CreateRecord(c *gin.Context) {
 		return
 	}
 
+	if models.DNSDisabledStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has expired and its DNS is disabled. Renew it to manage DNS records."})
+		return
+	}
+
 	// 检查是否使用自定义 nameservers
 	if !domain.UseDefaultNameservers {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -157,7 +188,7 @@ func (h *DNSHandler) CreateRecord(c *gin.Context) {
 
 	// 设置默认 TTL
 	if req.TTL == 0 {
-		req.TTL = 3600
+		req.TTL = defaultRecordTTL(&domain)
 	}
 
 	// MX 记录默认优先级
@@ -166,26 +197,17 @@ func (h *DNSHandler) CreateRecord(c *gin.Context) {
 		req.Priority = &defaultPriority
 	}
 
-	// 验证记录内容
-	if err := validateDNSRecord(req.Type, req.Content); err != nil {
+	// 验证记录内容，结构化类型可由 data 生成
+	content, err := normalizeRecordContent(req.Type, req.Content, req.Data)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.Content = content
 
-	// CNAME 冲突检查：CNAME 不能与同名的其他记录共存（仅检查活跃记录）
-	var conflictCount int64
-	if req.Type == "CNAME" {
-		h.db.Model(&models.DNSRecord{}).Where("domain_id = ? AND name = ? AND type != ? AND is_active = ?", domain.ID, req.Name, "CNAME", true).Count(&conflictCount)
-		if conflictCount > 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "CNAME record cannot coexist with other record types at the same name"})
-			return
-		}
-	} else {
-		h.db.Model(&models.DNSRecord{}).Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?", domain.ID, req.Name, "CNAME", true).Count(&conflictCount)
-		if conflictCount > 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot add this record because a CNAME record already exists at the same name"})
-			return
-		}
+	if req.Type == "URL" && !h.redirectEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL redirect records are not enabled on this server"})
+		return
 	}
 
 	// 创建 DNS 记录
@@ -200,11 +222,25 @@ func (h *DNSHandler) CreateRecord(c *gin.Context) {
 		SyncedToPowerDNS: false,
 	}
 
+	// zone 范围的冲突检查（CNAME 独占、MX/SRV 目标、NS 委派等）和根域名 DNS 策略
+	conflicts, err := h.zoneConflictsForRecord(&domain, record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+	if len(conflicts) > 0 {
+		respondZoneConflicts(c, conflicts)
+		return
+	}
+
+	h.snapshotBeforeChange(domain.ID)
+
 	if err := h.db.Create(record).Error; err != nil {
 		fmt.Printf("Failed to create DNS record: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create DNS record: %v", err)})
 		return
 	}
+	h.snapshotAfterChange(domain.ID, "create_record")
 
 	// 同步到 PowerDNS
 	go h.syncRecordSetToPowerDNS(record, &domain)
@@ -228,7 +264,7 @@ func (h *DNSHandler) UpdateRecord(c *gin.Context) {
 
 	// 验证域名所有权
 	var domain models.Domain
-	if err := h.db.First(&domain, domainID).Error; err != nil {
+	if err := h.db.Preload("RootDomain").First(&domain, domainID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
 		return
 	}
@@ -243,6 +279,11 @@ func (h *DNSHandler) UpdateRecord(c *gin.Context) {
 		return
 	}
 
+	if models.DNSDisabledStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has expired and its DNS is disabled. Renew it to manage DNS records."})
+		return
+	}
+
 	// 获取 DNS 记录
 	var record models.DNSRecord
 	if err := h.db.Where("id = ? AND domain_id = ?", recordID, domainID).First(&record).Error; err != nil {
@@ -250,6 +291,11 @@ func (h *DNSHandler) UpdateRecord(c *gin.Context) {
 		return
 	}
 
+	if record.FailoverGroupID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This record is managed by a failover group, change the failover group instead"})
+		return
+	}
+
 	// 检查是否使用自定义 nameservers
 	if !domain.UseDefaultNameservers {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -285,16 +331,45 @@ func (h *DNSHandler) UpdateRecord(c *gin.Context) {
 		record.IsActive = *req.IsActive
 	}
 
+	if !isSupportedRecordType(record.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported record type %s", record.Type)})
+		return
+	}
+	if req.Type != nil && *req.Type == "URL" && !h.redirectEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL redirect records are not enabled on this server"})
+		return
+	}
+
+	// 验证记录内容，结构化类型可由 data 生成
+	content, err := normalizeRecordContent(record.Type, record.Content, req.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	record.Content = content
+
+	conflicts, err := h.zoneConflictsForRecord(&domain, &record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+	if len(conflicts) > 0 {
+		respondZoneConflicts(c, conflicts)
+		return
+	}
+
 	// 标记为未同步
 	record.SyncedToPowerDNS = false
 
+	h.snapshotBeforeChange(domain.ID)
+
 	if err := h.db.Save(&record).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update DNS record"})
 		return
 	}
+	h.snapshotAfterChange(domain.ID, "update_record")
 
 	// 同步到 PowerDNS
-	h.db.Preload("RootDomain").First(&domain, domain.ID)
 	go h.syncRecordSetToPowerDNS(&record, &domain)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -331,6 +406,11 @@ func (h *DNSHandler) DeleteRecord(c *gin.Context) {
 		return
 	}
 
+	if models.DNSDisabledStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has expired and its DNS is disabled. Renew it to manage DNS records."})
+		return
+	}
+
 	// 获取记录信息（删除前）
 	var record models.DNSRecord
 	if err := h.db.Where("id = ? AND domain_id = ?", recordID, domainID).First(&record).Error; err != nil {
@@ -338,6 +418,11 @@ func (h *DNSHandler) DeleteRecord(c *gin.Context) {
 		return
 	}
 
+	if record.FailoverGroupID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This record is managed by a failover group, change the failover group instead"})
+		return
+	}
+
 	// 检查是否使用自定义 nameservers
 	if !domain.UseDefaultNameservers {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -347,11 +432,14 @@ func (h *DNSHandler) DeleteRecord(c *gin.Context) {
 		return
 	}
 
+	h.snapshotBeforeChange(domain.ID)
+
 	// 删除 DNS 记录
 	if err := h.db.Delete(&record).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete DNS record"})
 		return
 	}
+	h.snapshotAfterChange(domain.ID, "delete_record")
 
 	// 从 PowerDNS 删除记录
 	h.db.Preload("RootDomain").First(&domain, domain.ID)
@@ -389,6 +477,11 @@ func validateDNSRecord(recordType, content string) error {
 		if content == "" {
 			return fmt.Errorf("content cannot be empty")
 		}
+	case "HTTPS", "SVCB", "TLSA", "SSHFP", "DS", "NAPTR", "URL":
+		// 结构化类型严格校验各字段
+		if _, err := canonicalRecordContent(recordType, content); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -428,8 +521,13 @@ func (h *DNSHandler) syncRecordSetToPowerDNS(record *models.DNSRecord, domain *m
 	if domain.RootDomain == nil {
 		return
 	}
+	if record.Type == "URL" {
+		h.syncRedirectRecord(record, domain)
+		return
+	}
 	zoneDomain := domain.FullDomain
 	recordFQDN := buildRecordFQDN(record.Name, domain.FullDomain)
+	defer h.restoreACMEChallenges(record, domain)
 
 	// 查询同 domain+name+type 的所有活跃记录
 	var allRecords []models.DNSRecord
@@ -447,16 +545,14 @@ func (h *DNSHandler) syncRecordSetToPowerDNS(record *models.DNSRecord, domain *m
 	err := h.pdns.SetRecords(zoneDomain, recordFQDN, record.Type, entries, record.TTL)
 	if err != nil {
 		// 如果 zone 不存在，尝试创建
-		if strings.Contains(err.Error(), "not found") ||
-			strings.Contains(err.Error(), "Could not find") ||
-			strings.Contains(err.Error(), "404") {
+		if isZoneNotFoundError(err) {
 			fmt.Printf("Zone %s not found in PowerDNS, attempting to create...\n", zoneDomain)
 
 			// 使用默认 nameservers 创建 zone
 			defaultNS := []string{h.cfg.DNS.DefaultNS1, h.cfg.DNS.DefaultNS2}
 			if createErr := h.pdns.CreateZone(zoneDomain, ensureCanonicalNS(defaultNS)); createErr != nil {
 				// 检查是否是因为zone已经存在（并发创建的情况）
-				if !strings.Contains(createErr.Error(), "Conflict") && !strings.Contains(createErr.Error(), "already exists") {
+				if !isZoneExistsError(createErr) {
 					syncErr := fmt.Sprintf("Failed to create zone: %v", createErr)
 					for i := range allRecords {
 						allRecords[i].SyncError = &syncErr
@@ -519,8 +615,13 @@ func (h *DNSHandler) deleteRecordFromPowerDNS(record *models.DNSRecord, domain *
 	if domain.RootDomain == nil {
 		return
 	}
+	if record.Type == "URL" {
+		h.syncRedirectRecord(record, domain)
+		return
+	}
 	zoneDomain := domain.FullDomain
 	recordFQDN := buildRecordFQDN(record.Name, domain.FullDomain)
+	defer h.restoreACMEChallenges(record, domain)
 
 	// 查询同 name+type 的剩余活跃记录
 	var remaining []models.DNSRecord
@@ -546,17 +647,14 @@ func (h *DNSHandler) deleteRecordFromPowerDNS(record *models.DNSRecord, domain *
 		err := h.pdns.SetRecords(zoneDomain, recordFQDN, record.Type, entries, remaining[0].TTL)
 		if err != nil {
 			// 检测 zone 是否不存在
-			if strings.Contains(err.Error(), "not found") ||
-				strings.Contains(err.Error(), "Could not find") ||
-				strings.Contains(err.Error(), "404") {
+			if isZoneNotFoundError(err) {
 				fmt.Printf("Zone %s not found during record deletion/update, attempting to create it\n", zoneDomain)
 
 				// 创建 zone（使用默认 NS）
 				defaultNS := []string{h.cfg.DNS.DefaultNS1, h.cfg.DNS.DefaultNS2}
 				if createErr := h.pdns.CreateZone(zoneDomain, ensureCanonicalNS(defaultNS)); createErr != nil {
 					// 如果出现冲突错误，说明zone已被其他请求创建，这是正常的
-					if !strings.Contains(createErr.Error(), "Conflict") &&
-						!strings.Contains(createErr.Error(), "already exists") {
+					if !isZoneExistsError(createErr) {
 						fmt.Printf("Failed to create zone %s: %v\n", zoneDomain, createErr)
 						return
 					}
@@ -619,16 +717,14 @@ func (h *DNSHandler) SyncFromPowerDNS(c *gin.Context) {
 	zone, err := h.pdns.GetZone(domain.FullDomain)
 	if err != nil {
 		// 如果 zone 不存在，尝试创建
-		if strings.Contains(err.Error(), "not found") ||
-			strings.Contains(err.Error(), "Could not find") ||
-			strings.Contains(err.Error(), "404") {
+		if isZoneNotFoundError(err) {
 			fmt.Printf("Zone %s not found in PowerDNS, attempting to create...\n", domain.FullDomain)
 
 			// 使用默认 nameservers 创建 zone
 			defaultNS := []string{h.cfg.DNS.DefaultNS1, h.cfg.DNS.DefaultNS2}
 			if createErr := h.pdns.CreateZone(domain.FullDomain, ensureCanonicalNS(defaultNS)); createErr != nil {
 				// 检查是否是因为zone已经存在（并发创建的情况）
-				if !strings.Contains(createErr.Error(), "Conflict") && !strings.Contains(createErr.Error(), "already exists") {
+				if !isZoneExistsError(createErr) {
 					c.JSON(http.StatusInternalServerError, gin.H{
 						"error": fmt.Sprintf("Failed to create zone in PowerDNS: %v", createErr),
 					})
@@ -664,6 +760,9 @@ func (h *DNSHandler) SyncFromPowerDNS(c *gin.Context) {
 	}{}
 
 	fullDomainWithDot := ensureTrailingDot(domain.FullDomain)
+	redirectNames := h.redirectNames(domain.ID)
+
+	h.snapshotBeforeChange(domain.ID)
 
 	// 遍历所有 RRsets
 	for _, rrset := range zone.RRsets {
@@ -683,11 +782,31 @@ func (h *DNSHandler) SyncFromPowerDNS(c *gin.Context) {
 		// 解析记录名称（将 FQDN 转换为本地名称）
 		recordName := extractRecordName(rrset.Name, domain.FullDomain)
 
+		// URL 记录发布的重定向服务地址不作为 A/AAAA 记录导入
+		if (rrset.Type == "A" || rrset.Type == "AAAA") && redirectNames[recordName] {
+			continue
+		}
+
+		// ACME 客户端写入的验证值由 acme_challenges 管理，不作为 TXT 记录导入
+		challengeValues := make(map[string]bool)
+		if rrset.Type == "TXT" && isACMEChallengeName(recordName) {
+			entries, err := acmeChallengeEntries(h.db, domain.ID, recordName)
+			if err != nil {
+				fmt.Printf("Warning: Failed to query ACME challenges for %s: %v\n", rrset.Name, err)
+			}
+			for _, entry := range entries {
+				challengeValues[entry.Content] = true
+			}
+		}
+
 		// 处理每条记录
 		for _, record := range rrset.Records {
 			if record.Disabled {
 				continue // 跳过已禁用的记录
 			}
+			if challengeValues[record.Content] {
+				continue
+			}
 
 			// 解析记录内容和优先级
 			content, priority := parseRecordContent(rrset.Type, record.Content)
@@ -741,6 +860,7 @@ func (h *DNSHandler) SyncFromPowerDNS(c *gin.Context) {
 
 	// 更新域名同步状态
 	h.updateDomainSyncStatus(domain.ID)
+	h.snapshotAfterChange(domain.ID, "sync_from_powerdns")
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "DNS records synced from PowerDNS successfully",
@@ -767,7 +887,11 @@ func extractRecordName(fqdn, fullDomain string) string {
 }
 
 // parseRecordContent 解析记录内容，提取优先级（如果有）
+// 结构化类型原样保留（目标域名的结尾点是内容的一部分），保证往返同步无损
 func parseRecordContent(recordType, content string) (string, *int) {
+	if models.IsStructuredRecordType(recordType) {
+		return content, nil
+	}
 	content = strings.TrimSuffix(content, ".")
 
 	// MX 记录格式: "10 mail.example.com."
@@ -793,3 +917,208 @@ func ensureTrailingDot(s string) string {
 	}
 	return s
 }
+
+// supportedRecordTypes 允许用户管理的记录类型
+var supportedRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "HTTPS", "SVCB", "TLSA", "SSHFP", "DS", "NAPTR", "URL", "LUA"}
+
+// isSupportedRecordType 检查记录类型是否允许
+func isSupportedRecordType(recordType string) bool {
+	for _, t := range supportedRecordTypes {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// isZoneNotFoundError 判断 PowerDNS 错误是否表示 zone 不存在
+func isZoneNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "not found") ||
+		strings.Contains(err.Error(), "Could not find") ||
+		strings.Contains(err.Error(), "404")
+}
+
+// isZoneExistsError 判断 PowerDNS 错误是否表示 zone 已存在
+func isZoneExistsError(err error) bool {
+	return strings.Contains(err.Error(), "Conflict") || strings.Contains(err.Error(), "already exists")
+}
+
+// rrsetKey 标识一个记录集（同 name+type 的所有记录）
+type rrsetKey struct {
+	Name string
+	Type string
+}
+
+// loadManagedDomain 加载当前用户可管理 DNS 的域名
+// 校验登录、所有权、挂起状态以及是否使用默认 NS，失败时直接写入响应并返回 false
+func (h *DNSHandler) loadManagedDomain(c *gin.Context) (*models.Domain, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").First(&domain, c.Param("domainId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return nil, false
+	}
+
+	if domain.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	if domain.Status == "suspended" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
+		return nil, false
+	}
+
+	if models.DNSDisabledStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has expired and its DNS is disabled. Renew it to manage DNS records."})
+		return nil, false
+	}
+
+	if !domain.UseDefaultNameservers {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Cannot manage DNS records for domains using custom nameservers. Please manage DNS records on your custom nameserver.",
+			"use_custom_ns": true,
+		})
+		return nil, false
+	}
+
+	return &domain, true
+}
+
+// buildRRsets 根据数据库中的活跃记录构造指定记录集的 PowerDNS RRset
+// 没有活跃记录的记录集会生成 DELETE 操作；URL 记录转换为同名的 A/AAAA 记录集
+func (h *DNSHandler) buildRRsets(db *gorm.DB, domain *models.Domain, keys []rrsetKey) ([]powerdns.RRset, error) {
+	keys = expandRedirectKeys(keys)
+	rrsets := make([]powerdns.RRset, 0, len(keys))
+	for _, key := range keys {
+		var records []models.DNSRecord
+		if err := db.Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?",
+			domain.ID, key.Name, key.Type, true).Order("id ASC").Find(&records).Error; err != nil {
+			return nil, fmt.Errorf("failed to load records for %s/%s: %w", key.Name, key.Type, err)
+		}
+
+		entries := make([]powerdns.RecordEntry, 0, len(records))
+		ttl := 0
+		for _, r := range records {
+			entries = append(entries, powerdns.RecordEntry{
+				Content:  r.Content,
+				Priority: r.Priority,
+			})
+			if ttl == 0 {
+				ttl = r.TTL
+			}
+		}
+		if key.Type == "TXT" && isACMEChallengeName(key.Name) {
+			challenges, err := acmeChallengeEntries(db, domain.ID, key.Name)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, challenges...)
+			if ttl == 0 {
+				ttl = acmeChallengeTTL
+			}
+		}
+		if key.Type == "A" || key.Type == "AAAA" {
+			redirects, redirectTTL, err := h.redirectEntries(db, domain.ID, key)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, redirects...)
+			if ttl == 0 {
+				ttl = redirectTTL
+			}
+		}
+
+		rrsets = append(rrsets, powerdns.BuildRRset(buildRecordFQDN(key.Name, domain.FullDomain), key.Type, entries, ttl))
+	}
+	return rrsets, nil
+}
+
+// patchZoneRRsets 通过一次 PATCH 提交多个 RRset，zone 不存在时先使用默认 NS 创建
+func (h *DNSHandler) patchZoneRRsets(zoneDomain string, rrsets []powerdns.RRset) error {
+	err := h.pdns.PatchRRsets(zoneDomain, rrsets)
+	if err == nil || !isZoneNotFoundError(err) {
+		return err
+	}
+
+	fmt.Printf("Zone %s not found in PowerDNS, attempting to create...\n", zoneDomain)
+	defaultNS := []string{h.cfg.DNS.DefaultNS1, h.cfg.DNS.DefaultNS2}
+	if createErr := h.pdns.CreateZone(zoneDomain, ensureCanonicalNS(defaultNS)); createErr != nil && !isZoneExistsError(createErr) {
+		return fmt.Errorf("failed to create zone: %w", createErr)
+	}
+
+	return h.pdns.PatchRRsets(zoneDomain, rrsets)
+}
+
+// applyRecordChanges 在一个数据库事务中执行 mutate，并将受影响的记录集通过一次 PATCH 推送到 PowerDNS
+// mutate 返回受影响的记录集；PowerDNS 推送失败时回滚事务，
+// 推送成功但事务提交失败时尽力将 PowerDNS 恢复为变更前的状态。
+// 变更成功后以 action 为名保存一个新的记录快照
+func (h *DNSHandler) applyRecordChanges(domain *models.Domain, action string, mutate func(tx *gorm.DB) ([]rrsetKey, error)) error {
+	var previous []powerdns.RRset
+	pushed := false
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := h.ensureDNSBaseline(tx, domain.ID); err != nil {
+			return fmt.Errorf("failed to record DNS snapshot: %w", err)
+		}
+
+		keys, err := mutate(tx)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		// 事务外读取到的是变更前的状态，用于提交失败时恢复 PowerDNS
+		previous, err = h.buildRRsets(h.db, domain, keys)
+		if err != nil {
+			return err
+		}
+		desired, err := h.buildRRsets(tx, domain, keys)
+		if err != nil {
+			return err
+		}
+
+		if err := h.patchZoneRRsets(domain.FullDomain, desired); err != nil {
+			return fmt.Errorf("failed to apply changes to PowerDNS: %w", err)
+		}
+		pushed = true
+
+		now := timeutil.Now()
+		for _, key := range keys {
+			if err := tx.Model(&models.DNSRecord{}).
+				Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?", domain.ID, key.Name, key.Type, true).
+				Updates(map[string]interface{}{
+					"synced_to_powerdns": true,
+					"sync_error":         nil,
+					"last_synced_at":     now,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := h.recordDNSSnapshot(tx, domain.ID, action); err != nil {
+			return fmt.Errorf("failed to record DNS snapshot: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		if pushed {
+			if rbErr := h.patchZoneRRsets(domain.FullDomain, previous); rbErr != nil {
+				fmt.Printf("Warning: Failed to restore PowerDNS state for %s after rollback: %v\n", domain.FullDomain, rbErr)
+			}
+		}
+		return err
+	}
+
+	h.updateDomainSyncStatus(domain.ID)
+	return nil
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+)
+
+// changeError 批量变更中某个操作的校验错误
+type changeError struct {
+	Index    int           `json:"index"`
+	Error    string        `json:"error"`
+	Conflict *zoneConflict `json:"conflict,omitempty"`
+}
+
+// recordChangePlan 校验通过后待执行的变更
+type recordChangePlan struct {
+	creates []*models.DNSRecord
+	updates []*models.DNSRecord
+	deletes []*models.DNSRecord
+	keys    []rrsetKey
+}
+
+// ApplyChanges 原子地执行一组 DNS 记录变更
+// 所有操作先整体校验，然后在一个数据库事务中执行，并通过一次 PATCH 推送到 PowerDNS；
+// 任何一步失败时数据库和 PowerDNS 都不会留下部分变更
+func (h *DNSHandler) ApplyChanges(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req models.DNSChangeSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing []models.DNSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	if !h.redirectEnabled() {
+		for i, op := range req.Changes {
+			if op.Type != nil && *op.Type == "URL" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Changeset validation failed",
+					"details": []changeError{{Index: i, Error: "URL redirect records are not enabled on this server"}},
+				})
+				return
+			}
+		}
+	}
+
+	plan, errs := planRecordChanges(domain, existing, req.Changes)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Changeset validation failed",
+			"details": errs,
+		})
+		return
+	}
+
+	if err := h.applyRecordChanges(domain, "changeset", plan.apply); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to apply changeset: %v", err)})
+		return
+	}
+
+	created := make([]*models.DNSRecordResponse, len(plan.creates))
+	for i, r := range plan.creates {
+		created[i] = r.ToResponse()
+	}
+	updated := make([]*models.DNSRecordResponse, len(plan.updates))
+	for i, r := range plan.updates {
+		updated[i] = r.ToResponse()
+	}
+	deleted := make([]uint, len(plan.deletes))
+	for i, r := range plan.deletes {
+		deleted[i] = r.ID
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "DNS changes applied successfully",
+		"created": created,
+		"updated": updated,
+		"deleted": deleted,
+	})
+}
+
+// planRecordChanges 在内存中按顺序模拟执行所有操作，并校验最终的记录状态
+func planRecordChanges(domain *models.Domain, existing []models.DNSRecord, ops []models.DNSChangeOperation) (*recordChangePlan, []changeError) {
+	plan := &recordChangePlan{}
+	var errs []changeError
+
+	state := make(map[uint]*models.DNSRecord, len(existing))
+	for i := range existing {
+		record := existing[i]
+		state[record.ID] = &record
+	}
+	updated := make(map[uint]bool)
+	deleted := make(map[uint]bool)
+	// 记录每个操作对应的记录，用于最终校验时定位操作序号
+	opRecords := make(map[*models.DNSRecord]int)
+	keySet := make(map[rrsetKey]bool)
+	addKey := func(name, recordType string) {
+		keySet[rrsetKey{Name: name, Type: recordType}] = true
+	}
+
+	for i, op := range ops {
+		switch op.Action {
+		case "create":
+			if op.Name == nil || op.Type == nil || (op.Content == nil && op.Data == nil) {
+				errs = append(errs, changeError{Index: i, Error: "name, type and content (or data) are required for create"})
+				continue
+			}
+			record := &models.DNSRecord{
+				DomainID: domain.ID,
+				Name:     *op.Name,
+				Type:     *op.Type,
+				TTL:      defaultRecordTTL(domain),
+				Priority: op.Priority,
+				IsActive: true,
+			}
+			if op.Content != nil {
+				record.Content = *op.Content
+			}
+			if err := applyChangeData(record, op.Data); err != nil {
+				errs = append(errs, changeError{Index: i, Error: err.Error()})
+				continue
+			}
+			if op.TTL != nil {
+				record.TTL = *op.TTL
+			}
+			if op.IsActive != nil {
+				record.IsActive = *op.IsActive
+			}
+			plan.creates = append(plan.creates, record)
+			opRecords[record] = i
+
+		case "update", "delete":
+			record, found := state[op.RecordID]
+			if op.RecordID == 0 || !found {
+				errs = append(errs, changeError{Index: i, Error: fmt.Sprintf("DNS record %d not found", op.RecordID)})
+				continue
+			}
+			if deleted[op.RecordID] {
+				errs = append(errs, changeError{Index: i, Error: fmt.Sprintf("DNS record %d is deleted earlier in this changeset", op.RecordID)})
+				continue
+			}
+			if record.FailoverGroupID != nil {
+				errs = append(errs, changeError{Index: i, Error: fmt.Sprintf("DNS record %d is managed by a failover group", op.RecordID)})
+				continue
+			}
+			addKey(record.Name, record.Type)
+
+			if op.Action == "delete" {
+				deleted[op.RecordID] = true
+				continue
+			}
+
+			if op.Name != nil {
+				record.Name = *op.Name
+			}
+			if op.Type != nil {
+				record.Type = *op.Type
+			}
+			if op.Content != nil {
+				record.Content = *op.Content
+			}
+			if err := applyChangeData(record, op.Data); err != nil {
+				errs = append(errs, changeError{Index: i, Error: err.Error()})
+				continue
+			}
+			if op.TTL != nil {
+				record.TTL = *op.TTL
+			}
+			if op.Priority != nil {
+				record.Priority = op.Priority
+			}
+			if op.IsActive != nil {
+				record.IsActive = *op.IsActive
+			}
+			record.SyncedToPowerDNS = false
+			updated[op.RecordID] = true
+			opRecords[record] = i
+		}
+	}
+
+	// 校验每条新建或修改后的记录
+	for record, i := range opRecords {
+		if err := normalizeChangedRecord(record); err != nil {
+			errs = append(errs, changeError{Index: i, Error: err.Error()})
+			continue
+		}
+		addKey(record.Name, record.Type)
+	}
+
+	// 校验最终状态下的记录集冲突
+	final := make([]models.DNSRecord, 0, len(state)+len(plan.creates))
+	for _, record := range existing {
+		if !deleted[record.ID] {
+			final = append(final, *state[record.ID])
+		}
+	}
+	for _, record := range plan.creates {
+		final = append(final, *record)
+	}
+	changed := make([]models.DNSRecord, 0, len(opRecords))
+	for record := range opRecords {
+		changed = append(changed, *record)
+	}
+	conflicts := checkZoneChange(domain, existing, final)
+	conflicts = append(conflicts, checkZonePolicy(domain, existing, final, changed)...)
+	for _, conflict := range conflicts {
+		conflict := conflict
+		errs = append(errs, changeError{Index: conflictOpIndex(conflict, state, plan.creates, opRecords), Error: conflict.Message, Conflict: &conflict})
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+		return nil, errs
+	}
+
+	for _, record := range existing {
+		switch {
+		case deleted[record.ID]:
+			plan.deletes = append(plan.deletes, state[record.ID])
+		case updated[record.ID]:
+			plan.updates = append(plan.updates, state[record.ID])
+		}
+	}
+	for key := range keySet {
+		plan.keys = append(plan.keys, key)
+	}
+
+	return plan, nil
+}
+
+// apply 在事务中执行变更计划，返回受影响的记录集
+func (p *recordChangePlan) apply(tx *gorm.DB) ([]rrsetKey, error) {
+	for _, record := range p.deletes {
+		if err := tx.Delete(record).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete DNS record %d: %w", record.ID, err)
+		}
+	}
+	for _, record := range p.updates {
+		if err := tx.Save(record).Error; err != nil {
+			return nil, fmt.Errorf("failed to update DNS record %d: %w", record.ID, err)
+		}
+	}
+	for _, record := range p.creates {
+		if err := tx.Create(record).Error; err != nil {
+			return nil, fmt.Errorf("failed to create DNS record %s/%s: %w", record.Name, record.Type, err)
+		}
+	}
+	return p.keys, nil
+}
+
+// normalizeChangedRecord 规范化并校验单条记录，与 CreateRecord 的规则保持一致
+func normalizeChangedRecord(record *models.DNSRecord) error {
+	record.Name = strings.TrimSpace(record.Name)
+	if record.Name == "" {
+		return fmt.Errorf("record name is required")
+	}
+	if !isSupportedRecordType(record.Type) {
+		return fmt.Errorf("unsupported record type %s", record.Type)
+	}
+	if record.TTL < 60 || record.TTL > 86400 {
+		return fmt.Errorf("TTL must be between 60 and 86400")
+	}
+	if record.Type == "MX" && record.Priority == nil {
+		defaultPriority := 10
+		record.Priority = &defaultPriority
+	}
+	content, err := normalizeRecordContent(record.Type, record.Content, nil)
+	if err != nil {
+		return err
+	}
+	record.Content = content
+	return nil
+}
+
+// applyChangeData 用结构化字段生成记录内容，未提供 data 时不做修改
+func applyChangeData(record *models.DNSRecord, data *models.DNSRecordData) error {
+	if data == nil {
+		return nil
+	}
+	content, err := normalizeRecordContent(record.Type, "", data)
+	if err != nil {
+		return err
+	}
+	record.Content = content
+	return nil
+}
+
+// conflictOpIndex 找到引入冲突的操作序号，无法对应到单个操作时返回 -1
+func conflictOpIndex(conflict zoneConflict, state map[uint]*models.DNSRecord, creates []*models.DNSRecord, opRecords map[*models.DNSRecord]int) int {
+	if conflict.RecordID != 0 {
+		if i, ok := opRecords[state[conflict.RecordID]]; ok {
+			return i
+		}
+		return -1
+	}
+	for _, record := range creates {
+		if normalizeZoneName(record.Name) == conflict.Name && record.Type == conflict.Type {
+			return opRecords[record]
+		}
+	}
+	return -1
+}
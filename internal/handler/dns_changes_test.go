@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"opendomain/internal/models"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestPlanRecordChanges(t *testing.T) {
+	domain := &models.Domain{ID: 1, FullDomain: "foo.example.com", RootDomain: &models.RootDomain{Domain: "example.com"}}
+
+	tests := []struct {
+		name     string
+		ops      []models.DNSChangeOperation
+		errIndex []int
+		creates  int
+		updates  int
+		deletes  int
+		keys     []rrsetKey
+	}{
+		{
+			name: "swap A for CNAME",
+			ops: []models.DNSChangeOperation{
+				{Action: "delete", RecordID: 1},
+				{Action: "create", Name: strPtr("www"), Type: strPtr("CNAME"), Content: strPtr("target.example.org")},
+			},
+			creates: 1,
+			deletes: 1,
+			keys:    []rrsetKey{{Name: "www", Type: "A"}, {Name: "www", Type: "CNAME"}},
+		},
+		{
+			name: "update content and create in one changeset",
+			ops: []models.DNSChangeOperation{
+				{Action: "update", RecordID: 1, Content: strPtr("192.0.2.2")},
+				{Action: "create", Name: strPtr("api"), Type: strPtr("A"), Content: strPtr("192.0.2.20")},
+			},
+			creates: 1,
+			updates: 1,
+			keys:    []rrsetKey{{Name: "api", Type: "A"}, {Name: "www", Type: "A"}},
+		},
+		{
+			name: "rename moves the record between RRsets",
+			ops: []models.DNSChangeOperation{
+				{Action: "update", RecordID: 1, Name: strPtr("web")},
+			},
+			updates: 1,
+			keys:    []rrsetKey{{Name: "web", Type: "A"}, {Name: "www", Type: "A"}},
+		},
+		{
+			name: "CNAME next to existing A",
+			ops: []models.DNSChangeOperation{
+				{Action: "create", Name: strPtr("api"), Type: strPtr("A"), Content: strPtr("192.0.2.20")},
+				{Action: "create", Name: strPtr("www"), Type: strPtr("CNAME"), Content: strPtr("target.example.org")},
+			},
+			errIndex: []int{1},
+		},
+		{
+			name: "unknown record",
+			ops: []models.DNSChangeOperation{
+				{Action: "update", RecordID: 99, Content: strPtr("192.0.2.2")},
+			},
+			errIndex: []int{0},
+		},
+		{
+			name: "record deleted earlier in the changeset",
+			ops: []models.DNSChangeOperation{
+				{Action: "delete", RecordID: 1},
+				{Action: "update", RecordID: 1, Content: strPtr("192.0.2.2")},
+			},
+			errIndex: []int{1},
+		},
+		{
+			name: "invalid content and missing fields",
+			ops: []models.DNSChangeOperation{
+				{Action: "create", Name: strPtr("api"), Type: strPtr("A"), Content: strPtr("not-an-ip")},
+				{Action: "create", Name: strPtr("mail")},
+			},
+			errIndex: []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := []models.DNSRecord{
+				{ID: 1, DomainID: domain.ID, Name: "www", Type: "A", Content: "192.0.2.1", TTL: 600, IsActive: true},
+				{ID: 2, DomainID: domain.ID, Name: "@", Type: "TXT", Content: `"v=spf1 -all"`, TTL: 600, IsActive: true},
+			}
+			plan, errs := planRecordChanges(domain, existing, tt.ops)
+
+			if tt.errIndex != nil {
+				indexes := make([]int, len(errs))
+				for i, e := range errs {
+					indexes[i] = e.Index
+				}
+				if !reflect.DeepEqual(indexes, tt.errIndex) {
+					t.Fatalf("error indexes = %v, want %v (%+v)", indexes, tt.errIndex, errs)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %+v", errs)
+			}
+			if len(plan.creates) != tt.creates || len(plan.updates) != tt.updates || len(plan.deletes) != tt.deletes {
+				t.Errorf("plan has %d creates, %d updates, %d deletes; want %d, %d, %d",
+					len(plan.creates), len(plan.updates), len(plan.deletes), tt.creates, tt.updates, tt.deletes)
+			}
+			sort.Slice(plan.keys, func(i, j int) bool {
+				if plan.keys[i].Name != plan.keys[j].Name {
+					return plan.keys[i].Name < plan.keys[j].Name
+				}
+				return plan.keys[i].Type < plan.keys[j].Type
+			})
+			if !reflect.DeepEqual(plan.keys, tt.keys) {
+				t.Errorf("keys = %v, want %v", plan.keys, tt.keys)
+			}
+		})
+	}
+}
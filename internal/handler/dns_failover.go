@@ -0,0 +1,698 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/internal/services"
+	"opendomain/pkg/timeutil"
+)
+
+const (
+	// maxFailoverGroupsPerDomain 每个域名最多的故障转移组数量
+	maxFailoverGroupsPerDomain = 5
+	// failoverProbeTimeout 单次探测的超时时间
+	failoverProbeTimeout = 10 * time.Second
+	// failoverConcurrency 同时检查的故障转移组数量
+	failoverConcurrency = 10
+)
+
+// ListFailoverGroups 获取域名的故障转移组
+func (h *DNSHandler) ListFailoverGroups(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var groups []models.FailoverGroup
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch failover groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+// GetFailoverGroup 获取故障转移组详情及最近的切换记录
+func (h *DNSHandler) GetFailoverGroup(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	group, ok := h.loadFailoverGroup(c, domain.ID)
+	if !ok {
+		return
+	}
+
+	var events []models.FailoverEvent
+	h.db.Where("group_id = ?", group.ID).Order("created_at DESC").Limit(50).Find(&events)
+
+	c.JSON(http.StatusOK, gin.H{
+		"group":  group,
+		"events": events,
+	})
+}
+
+// ListFailoverEvents 获取域名所有故障转移组的切换记录
+func (h *DNSHandler) ListFailoverEvents(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var events []models.FailoverEvent
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("created_at DESC").Limit(200).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch failover events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// CreateFailoverGroup 创建故障转移组，并创建由其托管的 A/AAAA 记录（初始指向主目标）
+func (h *DNSHandler) CreateFailoverGroup(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req models.FailoverGroupCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := normalizeZoneName(req.Name)
+	if name != "@" && (strings.HasPrefix(name, "*") || !hostnamePattern.MatchString(name) || strings.HasSuffix(name, ".")) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record name"})
+		return
+	}
+
+	group := &models.FailoverGroup{
+		DomainID:         domain.ID,
+		Name:             name,
+		Type:             req.Type,
+		TTL:              req.TTL,
+		Primary:          req.Primary,
+		Backups:          req.Backups,
+		ProbeType:        req.ProbeType,
+		ProbePort:        req.ProbePort,
+		ProbePath:        req.ProbePath,
+		ProbeHost:        req.ProbeHost,
+		Interval:         req.Interval,
+		FailThreshold:    req.FailThreshold,
+		RecoverThreshold: req.RecoverThreshold,
+		AutoFailback:     true,
+		IsEnabled:        true,
+	}
+	if req.AutoFailback != nil {
+		group.AutoFailback = *req.AutoFailback
+	}
+	if err := normalizeFailoverGroup(group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group.ActiveTarget = group.Primary
+
+	var count int64
+	h.db.Model(&models.FailoverGroup{}).Where("domain_id = ?", domain.ID).Count(&count)
+	if count >= maxFailoverGroupsPerDomain {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A domain can have at most %d failover groups", maxFailoverGroupsPerDomain)})
+		return
+	}
+
+	// 托管记录与同名同类型的其他记录互斥，冲突检查时先标记为托管
+	placeholder := uint(0)
+	record := &models.DNSRecord{
+		DomainID:        domain.ID,
+		Name:            group.Name,
+		Type:            group.Type,
+		Content:         group.Primary,
+		TTL:             group.TTL,
+		IsActive:        true,
+		FailoverGroupID: &placeholder,
+	}
+	conflicts, err := h.zoneConflictsForRecord(domain, record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+	if len(conflicts) > 0 {
+		respondZoneConflicts(c, conflicts)
+		return
+	}
+
+	h.snapshotBeforeChange(domain.ID)
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		record.FailoverGroupID = &group.ID
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		group.RecordID = &record.ID
+		return tx.Model(group).Update("record_id", record.ID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create failover group"})
+		return
+	}
+	h.snapshotAfterChange(domain.ID, "create_failover_group")
+
+	go h.syncRecordSetToPowerDNS(record, domain)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Failover group created successfully",
+		"group":   group,
+		"record":  record.ToResponse(),
+	})
+}
+
+// UpdateFailoverGroup 更新故障转移组的目标和探测设置
+// 当前生效的目标被移除时立即切回主目标
+func (h *DNSHandler) UpdateFailoverGroup(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	group, ok := h.loadFailoverGroup(c, domain.ID)
+	if !ok {
+		return
+	}
+
+	var req models.FailoverGroupUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.TTL != nil {
+		group.TTL = *req.TTL
+	}
+	if req.Primary != nil {
+		group.Primary = *req.Primary
+	}
+	if req.Backups != nil {
+		group.Backups = req.Backups
+	}
+	if req.ProbeType != nil {
+		group.ProbeType = *req.ProbeType
+		if req.ProbePort == nil {
+			group.ProbePort = 0
+		}
+	}
+	if req.ProbePort != nil {
+		group.ProbePort = *req.ProbePort
+	}
+	if req.ProbePath != nil {
+		group.ProbePath = *req.ProbePath
+	}
+	if req.ProbeHost != nil {
+		group.ProbeHost = *req.ProbeHost
+	}
+	if req.Interval != nil {
+		group.Interval = *req.Interval
+	}
+	if req.FailThreshold != nil {
+		group.FailThreshold = *req.FailThreshold
+	}
+	if req.RecoverThreshold != nil {
+		group.RecoverThreshold = *req.RecoverThreshold
+	}
+	if req.AutoFailback != nil {
+		group.AutoFailback = *req.AutoFailback
+	}
+	if req.IsEnabled != nil {
+		group.IsEnabled = *req.IsEnabled
+	}
+	if err := normalizeFailoverGroup(group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 移除已不在组内的目标的健康状态
+	targets := make(map[string]bool)
+	for _, t := range group.Targets() {
+		targets[t] = true
+	}
+	for t := range group.TargetHealth {
+		if !targets[t] {
+			delete(group.TargetHealth, t)
+		}
+	}
+
+	previous := group.ActiveTarget
+	if !targets[group.ActiveTarget] {
+		group.ActiveTarget = group.Primary
+	}
+
+	// 与创建时一样校验更新后的托管记录，TTL 等变更需要符合根域名策略
+	var record models.DNSRecord
+	if err := h.db.Where("failover_group_id = ?", group.ID).First(&record).Error; err == nil {
+		record.Content = group.ActiveTarget
+		record.TTL = group.TTL
+		conflicts, err := h.zoneConflictsForRecord(domain, &record)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+			return
+		}
+		if len(conflicts) > 0 {
+			respondZoneConflicts(c, conflicts)
+			return
+		}
+	}
+
+	h.snapshotBeforeChange(domain.ID)
+
+	if err := h.db.Save(group).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update failover group"})
+		return
+	}
+
+	if group.ActiveTarget != previous {
+		h.recordFailoverEvent(group, previous, group.ActiveTarget, "active target was removed from the group")
+	}
+	err := h.updateFailoverRecord(group, domain)
+	h.snapshotAfterChange(domain.ID, "update_failover_group")
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to update failover record: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Failover group updated successfully",
+		"group":   group,
+	})
+}
+
+// DeleteFailoverGroup 删除故障转移组及其托管的记录
+func (h *DNSHandler) DeleteFailoverGroup(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	group, ok := h.loadFailoverGroup(c, domain.ID)
+	if !ok {
+		return
+	}
+
+	var record models.DNSRecord
+	hasRecord := h.db.Where("failover_group_id = ?", group.ID).First(&record).Error == nil
+
+	h.snapshotBeforeChange(domain.ID)
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("failover_group_id = ?", group.ID).Delete(&models.DNSRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.FailoverEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete failover group"})
+		return
+	}
+	h.snapshotAfterChange(domain.ID, "delete_failover_group")
+
+	if hasRecord {
+		go h.deleteRecordFromPowerDNS(&record, domain)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Failover group deleted successfully"})
+}
+
+// RunFailoverChecks 检查所有到期的故障转移组，由后台任务定期调用
+func (h *DNSHandler) RunFailoverChecks(ctx context.Context) {
+	var groups []models.FailoverGroup
+	if err := h.db.Preload("Domain.RootDomain").Preload("Domain.User").
+		Where("is_enabled = ?", true).Find(&groups).Error; err != nil {
+		fmt.Printf("Warning: Failed to load failover groups: %v\n", err)
+		return
+	}
+
+	now := timeutil.Now()
+	sem := make(chan struct{}, failoverConcurrency)
+	var wg sync.WaitGroup
+	for i := range groups {
+		group := &groups[i]
+		if group.Domain == nil || group.Domain.Status != "active" || !group.Domain.UseDefaultNameservers {
+			continue
+		}
+		if group.LastCheckedAt != nil && now.Sub(*group.LastCheckedAt) < time.Duration(group.Interval)*time.Second {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			h.checkFailoverGroup(ctx, group)
+		}()
+	}
+	wg.Wait()
+}
+
+// checkFailoverGroup 探测组内所有目标，更新健康状态，并在需要时切换生效的目标
+// 目标连续失败 FailThreshold 次才判定为不健康，连续成功 RecoverThreshold 次才恢复，避免抖动
+func (h *DNSHandler) checkFailoverGroup(ctx context.Context, group *models.FailoverGroup) {
+	domain := group.Domain
+	targets := group.Targets()
+	fqdn := buildRecordFQDN(group.Name, domain.FullDomain)
+
+	results := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			results[i] = probeFailoverTarget(ctx, group, fqdn, target)
+		}(i, target)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	if group.TargetHealth == nil {
+		group.TargetHealth = make(map[string]*models.FailoverTargetState)
+	}
+	wasAllDown := allFailoverTargetsDown(group)
+
+	now := timeutil.Now()
+	for i, target := range targets {
+		state := group.TargetHealth[target]
+		if state == nil {
+			state = &models.FailoverTargetState{Healthy: true}
+			group.TargetHealth[target] = state
+		}
+		state.LastCheckedAt = &now
+		if results[i] == nil {
+			state.ConsecutiveFailures = 0
+			state.ConsecutiveSuccesses++
+			state.LastError = ""
+			if !state.Healthy && state.ConsecutiveSuccesses >= group.RecoverThreshold {
+				state.Healthy = true
+			}
+		} else {
+			state.ConsecutiveSuccesses = 0
+			state.ConsecutiveFailures++
+			state.LastError = results[i].Error()
+			if state.Healthy && state.ConsecutiveFailures >= group.FailThreshold {
+				state.Healthy = false
+			}
+		}
+	}
+
+	group.LastCheckedAt = &now
+	if err := h.db.Model(group).Select("target_health", "last_checked_at").Updates(group).Error; err != nil {
+		fmt.Printf("Warning: Failed to save failover health for %s: %v\n", fqdn, err)
+		return
+	}
+
+	if allFailoverTargetsDown(group) {
+		if !wasAllDown {
+			reason := fmt.Sprintf("all targets are unhealthy, keeping %s", group.ActiveTarget)
+			h.recordFailoverEvent(group, group.ActiveTarget, group.ActiveTarget, reason)
+		}
+		return
+	}
+
+	next := selectFailoverTarget(group)
+	if next == "" || next == group.ActiveTarget {
+		return
+	}
+
+	reason := fmt.Sprintf("%s recovered", next)
+	if state := group.TargetHealth[group.ActiveTarget]; state != nil && !state.Healthy {
+		reason = fmt.Sprintf("%s is unhealthy: %s", group.ActiveTarget, state.LastError)
+	}
+
+	previous := group.ActiveTarget
+	group.ActiveTarget = next
+	if err := h.db.Model(group).Update("active_target", next).Error; err != nil {
+		fmt.Printf("Warning: Failed to switch failover target for %s: %v\n", fqdn, err)
+		return
+	}
+	if err := h.updateFailoverRecord(group, domain); err != nil {
+		reason += fmt.Sprintf(" (DNS update failed: %v)", err)
+	}
+	h.recordFailoverEvent(group, previous, next, reason)
+}
+
+// selectFailoverTarget 按优先级选出应生效的健康目标
+// 关闭自动回切时，只要当前目标仍健康就保持不变
+func selectFailoverTarget(group *models.FailoverGroup) string {
+	healthy := func(target string) bool {
+		state := group.TargetHealth[target]
+		return state == nil || state.Healthy
+	}
+
+	if !group.AutoFailback && healthy(group.ActiveTarget) {
+		return group.ActiveTarget
+	}
+	for _, target := range group.Targets() {
+		if healthy(target) {
+			return target
+		}
+	}
+	return ""
+}
+
+// allFailoverTargetsDown 判断组内是否所有目标都不健康
+func allFailoverTargetsDown(group *models.FailoverGroup) bool {
+	for _, target := range group.Targets() {
+		if state := group.TargetHealth[target]; state == nil || state.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// updateFailoverRecord 将托管记录更新为当前生效的目标，并通过 SetRecords 推送到 PowerDNS
+func (h *DNSHandler) updateFailoverRecord(group *models.FailoverGroup, domain *models.Domain) error {
+	var record models.DNSRecord
+	if err := h.db.Where("failover_group_id = ?", group.ID).First(&record).Error; err != nil {
+		return fmt.Errorf("failover record not found: %w", err)
+	}
+
+	if record.Content == group.ActiveTarget && record.TTL == group.TTL && record.SyncedToPowerDNS {
+		return nil
+	}
+
+	record.Content = group.ActiveTarget
+	record.TTL = group.TTL
+	record.SyncedToPowerDNS = false
+	if err := h.db.Save(&record).Error; err != nil {
+		return err
+	}
+
+	h.syncRecordSetToPowerDNS(&record, domain)
+
+	if err := h.db.First(&record, record.ID).Error; err == nil && !record.SyncedToPowerDNS {
+		if record.SyncError != nil {
+			return fmt.Errorf("%s", *record.SyncError)
+		}
+		return fmt.Errorf("record was not synced to PowerDNS")
+	}
+	return nil
+}
+
+// recordFailoverEvent 记录切换事件并通知域名所有者
+func (h *DNSHandler) recordFailoverEvent(group *models.FailoverGroup, from, to, reason string) {
+	event := &models.FailoverEvent{
+		GroupID:    group.ID,
+		DomainID:   group.DomainID,
+		FromTarget: from,
+		ToTarget:   to,
+		Reason:     reason,
+	}
+	if err := h.db.Create(event).Error; err != nil {
+		fmt.Printf("Warning: Failed to record failover event for group %d: %v\n", group.ID, err)
+	}
+
+	domain := group.Domain
+	if domain == nil {
+		domain = &models.Domain{}
+		if err := h.db.Preload("User").First(domain, group.DomainID).Error; err != nil {
+			return
+		}
+	} else if domain.User == nil {
+		h.db.Preload("User").First(domain, domain.ID)
+	}
+	if domain.User == nil {
+		return
+	}
+
+	fqdn := buildRecordFQDN(group.Name, domain.FullDomain)
+	subject := fmt.Sprintf("[%s] DNS failover for %s", h.cfg.SiteName, fqdn)
+	if from == to {
+		subject = fmt.Sprintf("[%s] All failover targets for %s are down", h.cfg.SiteName, fqdn)
+	}
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"The %s record for %s was changed by DNS failover.\n\n"+
+		"Previous target: %s\n"+
+		"Current target:  %s\n"+
+		"Reason:          %s\n"+
+		"Time:            %s\n\n"+
+		"You can review the failover group in the DNS settings of your domain.\n",
+		domain.User.Username, group.Type, fqdn, from, to, reason,
+		timeutil.Now().UTC().Format("2006-01-02 15:04:05 UTC"))
+
+	go func() {
+		if err := services.NewEmailService(h.cfg).Send(domain.User.Email, subject, body); err != nil {
+			fmt.Printf("Warning: Failed to send failover notification for %s: %v\n", fqdn, err)
+		}
+	}()
+}
+
+// loadFailoverGroup 根据路由参数加载故障转移组，失败时直接写入响应
+func (h *DNSHandler) loadFailoverGroup(c *gin.Context, domainID uint) (*models.FailoverGroup, bool) {
+	var group models.FailoverGroup
+	if err := h.db.Where("id = ? AND domain_id = ?", c.Param("groupId"), domainID).First(&group).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failover group not found"})
+		return nil, false
+	}
+	return &group, true
+}
+
+// normalizeFailoverGroup 补全默认值并校验目标地址
+func normalizeFailoverGroup(group *models.FailoverGroup) error {
+	if group.TTL == 0 {
+		group.TTL = 60
+	}
+	if group.Interval == 0 {
+		group.Interval = 60
+	}
+	if group.FailThreshold == 0 {
+		group.FailThreshold = 3
+	}
+	if group.RecoverThreshold == 0 {
+		group.RecoverThreshold = 2
+	}
+	if group.Backups == nil {
+		group.Backups = []string{}
+	}
+	if group.TargetHealth == nil {
+		group.TargetHealth = make(map[string]*models.FailoverTargetState)
+	}
+
+	switch group.ProbeType {
+	case models.FailoverProbeHTTP, models.FailoverProbeHTTPS:
+		if group.ProbePath == "" {
+			group.ProbePath = "/"
+		}
+		if group.ProbePort == 0 && group.ProbeType == models.FailoverProbeHTTP {
+			group.ProbePort = 80
+		}
+		if group.ProbePort == 0 {
+			group.ProbePort = 443
+		}
+	case models.FailoverProbeTCP:
+		if group.ProbePort == 0 {
+			return fmt.Errorf("probe_port is required for TCP probes")
+		}
+		group.ProbePath = ""
+	default:
+		return fmt.Errorf("unsupported probe type %s", group.ProbeType)
+	}
+	if group.ProbeHost != "" && !hostnamePattern.MatchString(strings.ToLower(group.ProbeHost)) {
+		return fmt.Errorf("invalid probe_host")
+	}
+
+	seen := make(map[string]bool)
+	for i, target := range group.Targets() {
+		ip := net.ParseIP(target)
+		if ip == nil {
+			return fmt.Errorf("invalid target address %q", target)
+		}
+		if (group.Type == "A") != (ip.To4() != nil) {
+			return fmt.Errorf("target %s does not match record type %s", target, group.Type)
+		}
+		// 不探测内网地址
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() || ip.IsMulticast() {
+			return fmt.Errorf("target %s must be a public IP address", target)
+		}
+		canonical := ip.String()
+		if seen[canonical] {
+			return fmt.Errorf("target %s is listed more than once", target)
+		}
+		seen[canonical] = true
+		if i == 0 {
+			group.Primary = canonical
+		} else {
+			group.Backups[i-1] = canonical
+		}
+	}
+	return nil
+}
+
+// probeFailoverTarget 探测单个目标：TCP 只检查能否建立连接；HTTP(S) 带上记录的主机名请求，5xx 视为失败
+func probeFailoverTarget(ctx context.Context, group *models.FailoverGroup, fqdn, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, failoverProbeTimeout)
+	defer cancel()
+
+	address := net.JoinHostPort(target, strconv.Itoa(group.ProbePort))
+	if group.ProbeType == models.FailoverProbeTCP {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	host := group.ProbeHost
+	if host == "" {
+		host = fqdn
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, group.ProbeType+"://"+address+group.ProbePath, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = host
+	req.Header.Set("User-Agent", "OpenDomain-Failover/1.0")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			// 证书有效性不影响可用性判断
+			TLSClientConfig:   &tls.Config{ServerName: host, InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"opendomain/internal/models"
+)
+
+// maxDNSSnapshots 每个域名保留的 DNS 快照数量
+const maxDNSSnapshots = 100
+
+// GetHistory 获取域名 DNS 记录的版本历史，每个版本附带与上一版本的差异
+func (h *DNSHandler) GetHistory(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	page := 1
+	pageSize := 20
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	var total int64
+	if err := h.db.Model(&models.DNSSnapshot{}).Where("domain_id = ?", domain.ID).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS history"})
+		return
+	}
+
+	// 多取一个更早的版本用于计算本页最后一个版本的差异
+	var snapshots []models.DNSSnapshot
+	if err := h.db.Where("domain_id = ?", domain.ID).
+		Order("version DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize + 1).
+		Find(&snapshots).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS history"})
+		return
+	}
+
+	versions := make([]gin.H, 0, pageSize)
+	for i := 0; i < len(snapshots) && i < pageSize; i++ {
+		current, err := decodeSnapshotRecords(snapshots[i].Records)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode DNS snapshot"})
+			return
+		}
+
+		var previous []models.DNSSnapshotRecord
+		if i+1 < len(snapshots) {
+			previous, err = decodeSnapshotRecords(snapshots[i+1].Records)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode DNS snapshot"})
+				return
+			}
+		}
+
+		versions = append(versions, gin.H{
+			"id":           snapshots[i].ID,
+			"version":      snapshots[i].Version,
+			"action":       snapshots[i].Action,
+			"record_count": len(current),
+			"created_at":   snapshots[i].CreatedAt,
+			"changes":      diffSnapshots(previous, current),
+		})
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": versions,
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+// GetSnapshot 获取某个版本的完整记录集
+func (h *DNSHandler) GetSnapshot(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	snapshot, records, ok := h.loadSnapshot(c, domain.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         snapshot.ID,
+		"version":    snapshot.Version,
+		"action":     snapshot.Action,
+		"created_at": snapshot.CreatedAt,
+		"records":    records,
+	})
+}
+
+// RestoreSnapshot 将域名的 DNS 记录恢复到指定版本，同时更新数据库和 PowerDNS
+// 恢复本身也会生成一个新版本，因此可以再次回滚
+func (h *DNSHandler) RestoreSnapshot(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	snapshot, records, ok := h.loadSnapshot(c, domain.ID)
+	if !ok {
+		return
+	}
+
+	// 恢复前按当前的 zone 规则、根域名策略和功能开关重新校验快照内容
+	current, err := h.loadRecordViews(h.db, domain.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	var managedRecords []models.DNSRecord
+	if err := h.db.Where("domain_id = ? AND failover_group_id IS NOT NULL", domain.ID).Find(&managedRecords).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+	managedKeys := make(map[rrsetKey]bool)
+	for _, r := range managedRecords {
+		managedKeys[rrsetKey{Name: r.Name, Type: r.Type}] = true
+	}
+
+	var diffs []rrsetDiff
+	for _, d := range diffRecordViews(current, snapshotViews(records), true) {
+		if managedKeys[rrsetKey{Name: d.Name, Type: d.Type}] {
+			continue
+		}
+		if d.Type == "URL" && len(d.After) > 0 && !h.redirectEnabled() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL redirect records are not enabled on this server"})
+			return
+		}
+		diffs = append(diffs, d)
+	}
+
+	conflicts, err := h.zoneConflictsForDiffs(domain, diffs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+	if len(conflicts) > 0 {
+		respondZoneConflicts(c, conflicts)
+		return
+	}
+
+	err = h.applyRecordChanges(domain, fmt.Sprintf("restore:%d", snapshot.Version), func(tx *gorm.DB) ([]rrsetKey, error) {
+		var current []models.DNSRecord
+		if err := tx.Where("domain_id = ?", domain.ID).Find(&current).Error; err != nil {
+			return nil, err
+		}
+
+		// 故障转移托管的记录集不参与恢复，继续由故障转移组维护
+		keySet := make(map[rrsetKey]bool)
+		managed := make(map[rrsetKey]bool)
+		for _, r := range current {
+			if r.FailoverGroupID != nil {
+				managed[rrsetKey{Name: r.Name, Type: r.Type}] = true
+				continue
+			}
+			keySet[rrsetKey{Name: r.Name, Type: r.Type}] = true
+		}
+		for _, r := range records {
+			if !managed[rrsetKey{Name: r.Name, Type: r.Type}] {
+				keySet[rrsetKey{Name: r.Name, Type: r.Type}] = true
+			}
+		}
+
+		if err := tx.Where("domain_id = ? AND failover_group_id IS NULL", domain.ID).Delete(&models.DNSRecord{}).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			if managed[rrsetKey{Name: r.Name, Type: r.Type}] {
+				continue
+			}
+			record := &models.DNSRecord{
+				DomainID: domain.ID,
+				Name:     r.Name,
+				Type:     r.Type,
+				Content:  r.Content,
+				TTL:      r.TTL,
+				Priority: r.Priority,
+				IsActive: r.IsActive,
+			}
+			if err := tx.Create(record).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		keys := make([]rrsetKey, 0, len(keySet))
+		for key := range keySet {
+			keys = append(keys, key)
+		}
+		return keys, nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to restore DNS snapshot: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("DNS records restored to version %d", snapshot.Version),
+	})
+}
+
+// loadSnapshot 根据路由参数加载快照，失败时直接写入响应
+func (h *DNSHandler) loadSnapshot(c *gin.Context, domainID uint) (*models.DNSSnapshot, []models.DNSSnapshotRecord, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return nil, nil, false
+	}
+
+	var snapshot models.DNSSnapshot
+	if err := h.db.Where("domain_id = ? AND version = ?", domainID, version).First(&snapshot).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "DNS snapshot not found"})
+		return nil, nil, false
+	}
+
+	records, err := decodeSnapshotRecords(snapshot.Records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode DNS snapshot"})
+		return nil, nil, false
+	}
+
+	return &snapshot, records, true
+}
+
+// ensureDNSBaseline 域名还没有任何快照时，先保存变更前的记录作为基线版本
+func (h *DNSHandler) ensureDNSBaseline(db *gorm.DB, domainID uint) error {
+	var count int64
+	if err := db.Model(&models.DNSSnapshot{}).Where("domain_id = ?", domainID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return h.recordDNSSnapshot(db, domainID, "baseline")
+}
+
+// recordDNSSnapshot 保存域名当前的完整记录集为新版本，与最新版本相同时不保存
+// 先锁定域名行，使并发的记录变更按顺序分配版本号
+func (h *DNSHandler) recordDNSSnapshot(db *gorm.DB, domainID uint, action string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var domain models.Domain
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&domain, domainID).Error; err != nil {
+			return err
+		}
+
+		var records []models.DNSRecord
+		if err := tx.Where("domain_id = ?", domainID).Find(&records).Error; err != nil {
+			return err
+		}
+
+		snapshotRecords := make([]models.DNSSnapshotRecord, 0, len(records))
+		for _, r := range records {
+			snapshotRecords = append(snapshotRecords, models.DNSSnapshotRecord{
+				Name:     r.Name,
+				Type:     r.Type,
+				Content:  r.Content,
+				TTL:      r.TTL,
+				Priority: r.Priority,
+				IsActive: r.IsActive,
+			})
+		}
+		sortSnapshotRecords(snapshotRecords)
+
+		data, err := json.Marshal(snapshotRecords)
+		if err != nil {
+			return err
+		}
+
+		var latest models.DNSSnapshot
+		err = tx.Where("domain_id = ?", domainID).Order("version DESC").First(&latest).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if err == nil && latest.Records == string(data) {
+			return nil
+		}
+
+		version := latest.Version + 1
+		if err := tx.Create(&models.DNSSnapshot{
+			DomainID: domainID,
+			Version:  version,
+			Action:   action,
+			Records:  string(data),
+		}).Error; err != nil {
+			return err
+		}
+
+		// 每个域名只保留最近的 maxDNSSnapshots 个版本
+		return tx.Where("domain_id = ? AND version <= ?", domainID, version-maxDNSSnapshots).
+			Delete(&models.DNSSnapshot{}).Error
+	})
+}
+
+// snapshotBeforeChange 在非事务的单条记录变更前调用，确保存在基线版本
+func (h *DNSHandler) snapshotBeforeChange(domainID uint) {
+	if err := h.ensureDNSBaseline(h.db, domainID); err != nil {
+		fmt.Printf("Warning: Failed to record DNS baseline snapshot for domain %d: %v\n", domainID, err)
+	}
+}
+
+// snapshotAfterChange 在非事务的单条记录变更后调用，保存新版本
+func (h *DNSHandler) snapshotAfterChange(domainID uint, action string) {
+	if err := h.recordDNSSnapshot(h.db, domainID, action); err != nil {
+		fmt.Printf("Warning: Failed to record DNS snapshot for domain %d: %v\n", domainID, err)
+	}
+}
+
+// decodeSnapshotRecords 解析快照中的记录
+func decodeSnapshotRecords(data string) ([]models.DNSSnapshotRecord, error) {
+	var records []models.DNSSnapshotRecord
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// sortSnapshotRecords 按 name/type/content 排序，保证相同记录集的快照内容一致
+func sortSnapshotRecords(records []models.DNSSnapshotRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		return records[i].Content < records[j].Content
+	})
+}
+
+// diffSnapshots 计算两个版本之间生效记录集的差异
+func diffSnapshots(previous, current []models.DNSSnapshotRecord) []rrsetDiff {
+	return diffRecordViews(snapshotViews(previous), snapshotViews(current), true)
+}
+
+// snapshotViews 将快照中的活跃记录按记录集分组
+func snapshotViews(records []models.DNSSnapshotRecord) map[rrsetKey][]zoneRecordView {
+	views := make(map[rrsetKey][]zoneRecordView)
+	for _, r := range records {
+		if !r.IsActive {
+			continue
+		}
+		key := rrsetKey{Name: r.Name, Type: r.Type}
+		views[key] = append(views[key], zoneRecordView{
+			Name:     r.Name,
+			Type:     r.Type,
+			Content:  r.Content,
+			TTL:      r.TTL,
+			Priority: r.Priority,
+		})
+	}
+	return views
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"opendomain/internal/models"
+)
+
+// 策略规则，与 zone 冲突使用相同的返回格式
+const (
+	conflictPolicyType       = "policy_type"
+	conflictPolicyTTL        = "policy_ttl"
+	conflictPolicyWildcard   = "policy_wildcard"
+	conflictPolicyApexOnly   = "policy_apex_only"
+	conflictPolicyMaxRecords = "policy_max_records"
+	conflictLuaDisabled      = "lua_disabled"
+)
+
+// defaultRecordTTL 未指定 TTL 时使用的默认值，会被限制在根域名策略的范围内
+func defaultRecordTTL(domain *models.Domain) int {
+	ttl := 3600
+	if policy := domainPolicy(domain); policy != nil {
+		if policy.MaxTTL > 0 && ttl > policy.MaxTTL {
+			ttl = policy.MaxTTL
+		}
+		if ttl < policy.MinTTL {
+			ttl = policy.MinTTL
+		}
+	}
+	return ttl
+}
+
+// recordTTLBounds 域名允许的 TTL 范围，未加载根域名时使用全局范围 60 至 86400
+func recordTTLBounds(domain *models.Domain) (int, int) {
+	minTTL, maxTTL := 60, 86400
+	if policy := domainPolicy(domain); policy != nil {
+		if policy.MinTTL > minTTL {
+			minTTL = policy.MinTTL
+		}
+		if policy.MaxTTL > 0 && policy.MaxTTL < maxTTL {
+			maxTTL = policy.MaxTTL
+		}
+	}
+	return minTTL, maxTTL
+}
+
+// domainPolicy 返回域名所属根域名的 DNS 策略，未加载根域名时返回 nil
+func domainPolicy(domain *models.Domain) *models.DNSPolicy {
+	if domain.RootDomain == nil {
+		return nil
+	}
+	return &domain.RootDomain.DNSPolicy
+}
+
+// checkZonePolicy 检查变更是否符合根域名的 DNS 策略及功能开关（LUA 记录）
+// 类型、TTL、通配符和 apex 限制只检查新建或修改的记录（changed），收紧策略不影响已有记录；
+// 记录数上限只在活跃记录数增加时检查
+func checkZonePolicy(domain *models.Domain, before, after, changed []models.DNSRecord) []zoneConflict {
+	policy := domainPolicy(domain)
+	if policy == nil {
+		return nil
+	}
+
+	var conflicts []zoneConflict
+	add := func(rule string, r models.DNSRecord, format string, args ...interface{}) {
+		conflicts = append(conflicts, zoneConflict{
+			Rule:     rule,
+			Name:     normalizeZoneName(r.Name),
+			Type:     r.Type,
+			RecordID: r.ID,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	rootDomain := domain.RootDomain.Domain
+	for _, r := range changed {
+		if !r.IsActive {
+			continue
+		}
+		name := normalizeZoneName(r.Name)
+
+		if !policy.AllowsType(r.Type) {
+			add(conflictPolicyType, r, "%s records are not allowed under %s (allowed: %s)", r.Type, rootDomain, strings.Join(policy.AllowedTypes, ", "))
+		}
+		if r.TTL < policy.MinTTL || (policy.MaxTTL > 0 && r.TTL > policy.MaxTTL) {
+			add(conflictPolicyTTL, r, "TTL for domains under %s must be between %d and %d", rootDomain, policy.MinTTL, policy.MaxTTL)
+		}
+		if !policy.AllowWildcard && (name == "*" || strings.HasPrefix(name, "*.")) {
+			add(conflictPolicyWildcard, r, "wildcard records are not allowed under %s", rootDomain)
+		}
+		if name != "@" && policy.IsApexOnly(r.Type) {
+			add(conflictPolicyApexOnly, r, "%s records can only be created at the domain apex (@) under %s", r.Type, rootDomain)
+		}
+		if r.Type == "LUA" && !domain.RootDomain.LuaRecordsEnabled {
+			add(conflictLuaDisabled, r, "LUA records are not enabled for domains under %s", rootDomain)
+		}
+	}
+
+	if policy.MaxRecords > 0 {
+		countBefore, countAfter := countActiveRecords(before), countActiveRecords(after)
+		if countAfter > policy.MaxRecords && countAfter > countBefore {
+			conflicts = append(conflicts, zoneConflict{
+				Rule:    conflictPolicyMaxRecords,
+				Message: fmt.Sprintf("domains under %s can have at most %d DNS records", rootDomain, policy.MaxRecords),
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// validateDNSPolicy 校验管理员提交的策略
+func validateDNSPolicy(policy *models.DNSPolicy) error {
+	for i, t := range policy.AllowedTypes {
+		policy.AllowedTypes[i] = strings.ToUpper(strings.TrimSpace(t))
+		if !isSupportedRecordType(policy.AllowedTypes[i]) {
+			return fmt.Errorf("unsupported record type %q in allowed_types", t)
+		}
+	}
+	for i, t := range policy.ApexOnlyTypes {
+		policy.ApexOnlyTypes[i] = strings.ToUpper(strings.TrimSpace(t))
+		if !isSupportedRecordType(policy.ApexOnlyTypes[i]) {
+			return fmt.Errorf("unsupported record type %q in apex_only_types", t)
+		}
+	}
+	if policy.AllowedTypes == nil {
+		policy.AllowedTypes = []string{}
+	}
+	if policy.ApexOnlyTypes == nil {
+		policy.ApexOnlyTypes = []string{}
+	}
+	if policy.MinTTL < 60 || policy.MaxTTL > 86400 || policy.MinTTL > policy.MaxTTL {
+		return fmt.Errorf("TTL bounds must satisfy 60 <= min_ttl <= max_ttl <= 86400")
+	}
+	if policy.MaxRecords < 0 {
+		return fmt.Errorf("max_records must not be negative")
+	}
+	return nil
+}
+
+func countActiveRecords(records []models.DNSRecord) int {
+	count := 0
+	for _, r := range records {
+		if r.IsActive {
+			count++
+		}
+	}
+	return count
+}
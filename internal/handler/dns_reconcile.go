@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+	"opendomain/pkg/timeutil"
+)
+
+const (
+	// reconcileBaseBackoff 对账失败后的初始重试间隔，每次失败翻倍
+	reconcileBaseBackoff = 5 * time.Minute
+	// reconcileMaxBackoff 对账失败后的最大重试间隔
+	reconcileMaxBackoff = 12 * time.Hour
+)
+
+// rrsetDrift 描述一个记录集在数据库与 PowerDNS 之间的差异
+type rrsetDrift struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Kind     string   `json:"kind"` // missing/mismatch/extra
+	Expected []string `json:"expected,omitempty"`
+	Actual   []string `json:"actual,omitempty"`
+}
+
+// ReconcileAll 将所有使用默认 NS 的活跃和宽限期域名与 PowerDNS 对账
+// 以数据库为准重新推送缺失、不一致或多余的记录集；推送后仍不一致的记录集记为漂移，
+// 失败的域名按指数退避延后重试
+func (h *DNSHandler) ReconcileAll() {
+	now := timeutil.Now()
+	checked, repaired, drifted, failed := 0, 0, 0, 0
+
+	backoffDomains := h.db.Model(&models.DNSReconcileStatus{}).Select("domain_id").Where("next_attempt_at > ?", now)
+
+	var domains []models.Domain
+	err := h.db.Preload("RootDomain").
+		Where("status IN ? AND use_default_nameservers = ?", []string{"active", models.DomainStatusGrace}, true).
+		Where("id NOT IN (?)", backoffDomains).
+		FindInBatches(&domains, 100, func(tx *gorm.DB, batch int) error {
+			for i := range domains {
+				fixed, drift, err := h.reconcileDomain(&domains[i])
+				checked++
+				repaired += fixed
+				switch {
+				case err != nil:
+					failed++
+					fmt.Printf("Warning: DNS reconciliation failed for %s: %v\n", domains[i].FullDomain, err)
+				case len(drift) > 0:
+					drifted++
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		fmt.Printf("Warning: Failed to load domains for DNS reconciliation: %v\n", err)
+		return
+	}
+
+	fmt.Printf("DNS reconciliation finished: %d domains checked, %d rrsets repaired, %d domains drifted, %d domains failed\n",
+		checked, repaired, drifted, failed)
+}
+
+// reconcileDomain 对账单个域名，返回修复的记录集数量和无法修复的漂移
+func (h *DNSHandler) reconcileDomain(domain *models.Domain) (int, []rrsetDrift, error) {
+	desired, err := h.desiredRRsets(domain)
+	if err != nil {
+		h.saveReconcileStatus(domain.ID, nil, err)
+		return 0, nil, err
+	}
+
+	zone, err := h.fetchZoneForReconcile(domain.FullDomain)
+	if err != nil {
+		h.saveReconcileStatus(domain.ID, nil, err)
+		return 0, nil, err
+	}
+
+	pushes, _ := compareZoneRRsets(domain, desired, zone)
+	if len(pushes) > 0 {
+		if err := h.patchZoneRRsets(domain.FullDomain, pushes); err != nil {
+			h.markRRsetsSyncError(domain, pushes, err.Error())
+			h.saveReconcileStatus(domain.ID, nil, err)
+			return 0, nil, err
+		}
+
+		// 推送后重新读取，确认是否真正一致
+		zone, err = h.fetchZoneForReconcile(domain.FullDomain)
+		if err != nil {
+			h.saveReconcileStatus(domain.ID, nil, err)
+			return 0, nil, err
+		}
+	}
+
+	remaining, drift := compareZoneRRsets(domain, desired, zone)
+	if len(remaining) > 0 {
+		h.markRRsetsSyncError(domain, remaining, "record set differs from PowerDNS after reconciliation")
+	}
+
+	// 其余记录集已经与 PowerDNS 一致
+	now := timeutil.Now()
+	driftKeys := make(map[rrsetKey]bool, len(drift))
+	for _, d := range drift {
+		driftKeys[rrsetKey{Name: d.Name, Type: d.Type}] = true
+	}
+	var records []models.DNSRecord
+	h.db.Where("domain_id = ? AND is_active = ? AND synced_to_powerdns = ?", domain.ID, true, false).Find(&records)
+	for _, r := range records {
+		if driftKeys[rrsetKey{Name: r.Name, Type: r.Type}] {
+			continue
+		}
+		h.db.Model(&r).Updates(map[string]interface{}{
+			"synced_to_powerdns": true,
+			"sync_error":         nil,
+			"last_synced_at":     now,
+		})
+	}
+	h.updateDomainSyncStatus(domain.ID)
+
+	h.saveReconcileStatus(domain.ID, drift, nil)
+	return max(len(pushes)-len(remaining), 0), drift, nil
+}
+
+// desiredRRsets 根据数据库中的活跃记录构造域名应有的所有记录集
+func (h *DNSHandler) desiredRRsets(domain *models.Domain) ([]powerdns.RRset, error) {
+	var keys []rrsetKey
+	if err := h.db.Model(&models.DNSRecord{}).
+		Select("DISTINCT name, type").
+		Where("domain_id = ? AND is_active = ?", domain.ID, true).
+		Scan(&keys).Error; err != nil {
+		return nil, err
+	}
+	return h.buildRRsets(h.db, domain, keys)
+}
+
+// fetchZoneForReconcile 获取 zone，zone 不存在时返回空 zone 以便全部重新推送
+func (h *DNSHandler) fetchZoneForReconcile(zoneDomain string) (*powerdns.Zone, error) {
+	zone, err := h.pdns.GetZone(zoneDomain)
+	if err != nil {
+		if isZoneNotFoundError(err) {
+			return &powerdns.Zone{Name: ensureTrailingDot(zoneDomain)}, nil
+		}
+		return nil, err
+	}
+	return zone, nil
+}
+
+// compareZoneRRsets 比较期望的记录集与 zone 中的实际记录集
+// 返回需要推送的 RRset（REPLACE 或 DELETE）以及对应的差异描述
+// SOA、apex NS 和 ACME 验证记录由其他流程管理，不参与比较
+func compareZoneRRsets(domain *models.Domain, desired []powerdns.RRset, zone *powerdns.Zone) ([]powerdns.RRset, []rrsetDrift) {
+	apex := ensureTrailingDot(strings.ToLower(domain.FullDomain))
+
+	actual := make(map[string]powerdns.RRset)
+	for _, rrset := range zone.RRsets {
+		name := strings.ToLower(rrset.Name)
+		if rrset.Type == "SOA" || (rrset.Type == "NS" && name == apex) {
+			continue
+		}
+		actual[name+"|"+rrset.Type] = rrset
+	}
+
+	var pushes []powerdns.RRset
+	var drift []rrsetDrift
+	seen := make(map[string]bool)
+
+	for _, want := range desired {
+		key := strings.ToLower(want.Name) + "|" + want.Type
+		if want.Type == "NS" && strings.ToLower(want.Name) == apex {
+			continue
+		}
+		seen[key] = true
+		name := extractRecordName(want.Name, domain.FullDomain)
+		expected := rrsetContents(want)
+
+		have, exists := actual[key]
+		if want.ChangeType == "DELETE" {
+			if exists {
+				pushes = append(pushes, want)
+				drift = append(drift, rrsetDrift{Name: name, Type: want.Type, Kind: "extra", Actual: rrsetContents(have)})
+			}
+			continue
+		}
+
+		switch {
+		case !exists:
+			pushes = append(pushes, want)
+			drift = append(drift, rrsetDrift{Name: name, Type: want.Type, Kind: "missing", Expected: expected})
+		case have.TTL != want.TTL || !sameContents(want.Type, expected, rrsetContents(have)):
+			pushes = append(pushes, want)
+			drift = append(drift, rrsetDrift{Name: name, Type: want.Type, Kind: "mismatch", Expected: expected, Actual: rrsetContents(have)})
+		}
+	}
+
+	for key, have := range actual {
+		if seen[key] {
+			continue
+		}
+		name := extractRecordName(have.Name, domain.FullDomain)
+		if have.Type == "TXT" && isACMEChallengeName(name) {
+			continue
+		}
+		pushes = append(pushes, powerdns.BuildRRset(have.Name, have.Type, nil, 0))
+		drift = append(drift, rrsetDrift{Name: name, Type: have.Type, Kind: "extra", Actual: rrsetContents(have)})
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Name != drift[j].Name {
+			return drift[i].Name < drift[j].Name
+		}
+		return drift[i].Type < drift[j].Type
+	})
+	return pushes, drift
+}
+
+// rrsetContents 返回记录集中启用记录的内容，已排序
+func rrsetContents(rrset powerdns.RRset) []string {
+	contents := make([]string, 0, len(rrset.Records))
+	for _, r := range rrset.Records {
+		if !r.Disabled {
+			contents = append(contents, r.Content)
+		}
+	}
+	sort.Strings(contents)
+	return contents
+}
+
+// sameContents 比较两组记录内容，比较前先按记录类型转换为规范形式
+func sameContents(recordType string, a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	canonicalA := make([]string, len(a))
+	canonicalB := make([]string, len(b))
+	for i := range a {
+		canonicalA[i] = canonicalContent(recordType, a[i])
+		canonicalB[i] = canonicalContent(recordType, b[i])
+	}
+	sort.Strings(canonicalA)
+	sort.Strings(canonicalB)
+	for i := range canonicalA {
+		if canonicalA[i] != canonicalB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalContent 记录内容的规范形式：PowerDNS 返回的 TXT 带引号且可能拆分为多个字符串，
+// IPv6 地址会被压缩，其余类型忽略大小写
+func canonicalContent(recordType, content string) string {
+	switch recordType {
+	case "A", "AAAA":
+		if ip := net.ParseIP(content); ip != nil {
+			return ip.String()
+		}
+	case "TXT", "SPF":
+		if strings.HasPrefix(content, `"`) {
+			var text strings.Builder
+			for _, field := range models.SplitRecordFields(content) {
+				text.WriteString(models.UnquoteRecordString(field))
+			}
+			return text.String()
+		}
+		return content
+	}
+	return strings.ToLower(content)
+}
+
+// markRRsetsSyncError 将推送失败或仍不一致的记录集标记为未同步
+func (h *DNSHandler) markRRsetsSyncError(domain *models.Domain, rrsets []powerdns.RRset, message string) {
+	for _, rrset := range rrsets {
+		h.db.Model(&models.DNSRecord{}).
+			Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?",
+				domain.ID, extractRecordName(rrset.Name, domain.FullDomain), rrset.Type, true).
+			Updates(map[string]interface{}{
+				"synced_to_powerdns": false,
+				"sync_error":         message,
+			})
+	}
+	h.updateDomainSyncStatus(domain.ID)
+}
+
+// saveReconcileStatus 保存对账结果，出错或存在无法修复的漂移时按指数退避安排下一次重试
+func (h *DNSHandler) saveReconcileStatus(domainID uint, drift []rrsetDrift, reconcileErr error) {
+	now := timeutil.Now()
+
+	var status models.DNSReconcileStatus
+	if err := h.db.Where("domain_id = ?", domainID).First(&status).Error; err != nil {
+		status = models.DNSReconcileStatus{DomainID: domainID}
+	}
+
+	status.LastCheckedAt = &now
+	status.DriftCount = len(drift)
+	status.Drift = ""
+	if len(drift) > 0 {
+		if data, err := json.Marshal(drift); err == nil {
+			status.Drift = string(data)
+		}
+	}
+
+	if reconcileErr == nil && len(drift) == 0 {
+		status.LastSuccessAt = &now
+		status.ConsecutiveFailures = 0
+		status.NextAttemptAt = nil
+		status.LastError = nil
+	} else {
+		status.ConsecutiveFailures++
+		backoff := reconcileBaseBackoff << uint(min(status.ConsecutiveFailures-1, 16))
+		if backoff > reconcileMaxBackoff {
+			backoff = reconcileMaxBackoff
+		}
+		next := now.Add(backoff)
+		status.NextAttemptAt = &next
+
+		message := fmt.Sprintf("%d record sets still differ from PowerDNS", len(drift))
+		if reconcileErr != nil {
+			message = reconcileErr.Error()
+		}
+		status.LastError = &message
+	}
+
+	if err := h.db.Save(&status).Error; err != nil {
+		fmt.Printf("Warning: Failed to save DNS reconcile status for domain %d: %v\n", domainID, err)
+	}
+}
+
+// GetDNSDriftSummary 管理员：按根域名汇总 DNS 对账结果
+// 指定 root_domain_id 时同时返回该根域名下存在漂移或对账失败的域名明细
+func (h *DNSHandler) GetDNSDriftSummary(c *gin.Context) {
+	type rootSummary struct {
+		RootDomainID  uint       `json:"root_domain_id"`
+		Domain        string     `json:"domain"`
+		TotalDomains  int        `json:"total_domains"`
+		Checked       int        `json:"checked"`
+		Drifted       int        `json:"drifted"`
+		Failing       int        `json:"failing"`
+		Unsynced      int        `json:"unsynced"`
+		LastCheckedAt *time.Time `json:"last_checked_at"`
+	}
+
+	var summaries []rootSummary
+	if err := h.db.Table("domains").
+		Select(`root_domains.id AS root_domain_id,
+			root_domains.domain AS domain,
+			COUNT(domains.id) AS total_domains,
+			COUNT(dns_reconcile_status.domain_id) AS checked,
+			COUNT(*) FILTER (WHERE dns_reconcile_status.drift_count > 0) AS drifted,
+			COUNT(*) FILTER (WHERE dns_reconcile_status.consecutive_failures > 0) AS failing,
+			COUNT(*) FILTER (WHERE domains.dns_synced = false) AS unsynced,
+			MAX(dns_reconcile_status.last_checked_at) AS last_checked_at`).
+		Joins("JOIN root_domains ON root_domains.id = domains.root_domain_id").
+		Joins("LEFT JOIN dns_reconcile_status ON dns_reconcile_status.domain_id = domains.id").
+		Where("domains.deleted_at IS NULL AND domains.status IN ? AND domains.use_default_nameservers = ?",
+			[]string{"active", models.DomainStatusGrace}, true).
+		Group("root_domains.id, root_domains.domain").
+		Order("root_domains.id ASC").
+		Scan(&summaries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS drift summary"})
+		return
+	}
+
+	response := gin.H{"root_domains": summaries}
+
+	if rootDomainID := c.Query("root_domain_id"); rootDomainID != "" {
+		var statuses []models.DNSReconcileStatus
+		if err := h.db.Preload("Domain").
+			Joins("JOIN domains ON domains.id = dns_reconcile_status.domain_id").
+			Where("domains.root_domain_id = ? AND domains.deleted_at IS NULL", rootDomainID).
+			Where("dns_reconcile_status.drift_count > 0 OR dns_reconcile_status.consecutive_failures > 0").
+			Order("dns_reconcile_status.consecutive_failures DESC, dns_reconcile_status.drift_count DESC").
+			Limit(200).
+			Find(&statuses).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS drift details"})
+			return
+		}
+
+		details := make([]gin.H, 0, len(statuses))
+		for _, s := range statuses {
+			var drift []rrsetDrift
+			if s.Drift != "" {
+				_ = json.Unmarshal([]byte(s.Drift), &drift)
+			}
+			domainName := ""
+			if s.Domain != nil {
+				domainName = s.Domain.FullDomain
+			}
+			details = append(details, gin.H{
+				"domain_id":            s.DomainID,
+				"domain":               domainName,
+				"consecutive_failures": s.ConsecutiveFailures,
+				"last_checked_at":      s.LastCheckedAt,
+				"last_success_at":      s.LastSuccessAt,
+				"next_attempt_at":      s.NextAttemptAt,
+				"last_error":           s.LastError,
+				"drift":                drift,
+			})
+		}
+		response["domains"] = details
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"opendomain/internal/models"
+)
+
+// hostnamePattern 记录内容中的目标域名（允许下划线和通配符标签）
+var hostnamePattern = regexp.MustCompile(`^(\*|[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?)(\.[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?)*\.?$`)
+
+// naptrFlagsPattern NAPTR flags 只能由字母和数字组成
+var naptrFlagsPattern = regexp.MustCompile(`^[A-Za-z0-9]*$`)
+
+// normalizeRecordContent 确定记录内容：提供 data 时由结构化字段生成，
+// 然后校验内容，结构化类型转换为与 PowerDNS 输出一致的规范格式
+func normalizeRecordContent(recordType, content string, data *models.DNSRecordData) (string, error) {
+	if data != nil {
+		if !models.IsStructuredRecordType(recordType) {
+			return "", fmt.Errorf("structured data is not supported for %s records", recordType)
+		}
+		built, err := data.Content(recordType)
+		if err != nil {
+			return "", err
+		}
+		content = built
+	}
+
+	content = strings.TrimSpace(content)
+	if models.IsStructuredRecordType(recordType) {
+		return canonicalRecordContent(recordType, content)
+	}
+	if err := validateDNSRecord(recordType, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// canonicalRecordContent 严格校验结构化类型的记录内容，并转换为规范格式：
+// 目标域名小写且以点结尾，十六进制小写，SVCB 参数按键序号排列
+func canonicalRecordContent(recordType, content string) (string, error) {
+	fields := models.SplitRecordFields(content)
+
+	switch recordType {
+	case "HTTPS", "SVCB":
+		return canonicalSVCB(fields)
+	case "TLSA":
+		if len(fields) < 4 {
+			return "", fmt.Errorf("TLSA record must be: usage selector matching-type certificate-data")
+		}
+		usage, err := parseRecordUint(fields[0], "usage", 3)
+		if err != nil {
+			return "", err
+		}
+		selector, err := parseRecordUint(fields[1], "selector", 1)
+		if err != nil {
+			return "", err
+		}
+		matchingType, err := parseRecordUint(fields[2], "matching type", 2)
+		if err != nil {
+			return "", err
+		}
+		data, err := parseRecordHex(strings.Join(fields[3:], ""), "certificate data", map[int]int{1: 64, 2: 128}[matchingType])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %d %d %s", usage, selector, matchingType, data), nil
+	case "SSHFP":
+		if len(fields) < 3 {
+			return "", fmt.Errorf("SSHFP record must be: algorithm fingerprint-type fingerprint")
+		}
+		algorithm, err := parseRecordUint(fields[0], "algorithm", 255)
+		if err != nil {
+			return "", err
+		}
+		switch algorithm {
+		case 1, 2, 3, 4, 6:
+		default:
+			return "", fmt.Errorf("unsupported SSHFP algorithm %d", algorithm)
+		}
+		fingerprintType, err := parseRecordUint(fields[1], "fingerprint type", 255)
+		if err != nil {
+			return "", err
+		}
+		length, ok := map[int]int{1: 40, 2: 64}[fingerprintType]
+		if !ok {
+			return "", fmt.Errorf("unsupported SSHFP fingerprint type %d", fingerprintType)
+		}
+		fingerprint, err := parseRecordHex(strings.Join(fields[2:], ""), "fingerprint", length)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %d %s", algorithm, fingerprintType, fingerprint), nil
+	case "DS":
+		if len(fields) < 4 {
+			return "", fmt.Errorf("DS record must be: key-tag algorithm digest-type digest")
+		}
+		keyTag, err := parseRecordUint(fields[0], "key tag", 65535)
+		if err != nil {
+			return "", err
+		}
+		ds := models.DomainDSRecordCreateRequest{KeyTag: keyTag, Digest: strings.Join(fields[3:], "")}
+		if ds.Algorithm, err = parseRecordUint(fields[1], "algorithm", 255); err != nil {
+			return "", err
+		}
+		if ds.DigestType, err = parseRecordUint(fields[2], "digest type", 255); err != nil {
+			return "", err
+		}
+		if err := validateDSRecord(&ds); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %d %d %s", ds.KeyTag, ds.Algorithm, ds.DigestType, strings.ToLower(ds.Digest)), nil
+	case "NAPTR":
+		if len(fields) != 6 {
+			return "", fmt.Errorf("NAPTR record must be: order preference \"flags\" \"service\" \"regexp\" replacement")
+		}
+		order, err := parseRecordUint(fields[0], "order", 65535)
+		if err != nil {
+			return "", err
+		}
+		preference, err := parseRecordUint(fields[1], "preference", 65535)
+		if err != nil {
+			return "", err
+		}
+		for i, name := range []string{"flags", "service", "regexp"} {
+			if !strings.HasPrefix(fields[2+i], `"`) || !strings.HasSuffix(fields[2+i], `"`) || len(fields[2+i]) < 2 {
+				return "", fmt.Errorf("NAPTR %s must be a quoted string", name)
+			}
+		}
+		flags := models.UnquoteRecordString(fields[2])
+		service := models.UnquoteRecordString(fields[3])
+		regex := models.UnquoteRecordString(fields[4])
+		if !naptrFlagsPattern.MatchString(flags) {
+			return "", fmt.Errorf("NAPTR flags must be alphanumeric")
+		}
+		replacement, err := canonicalRecordTarget(fields[5], "replacement")
+		if err != nil {
+			return "", err
+		}
+		if regex != "" && replacement != "." {
+			return "", fmt.Errorf("NAPTR record cannot have both regexp and replacement")
+		}
+		return fmt.Sprintf("%d %d %s %s %s %s", order, preference,
+			models.QuoteRecordString(flags), models.QuoteRecordString(service), models.QuoteRecordString(regex), replacement), nil
+	case "URL":
+		return canonicalRedirect(fields)
+	case "LUA":
+		return canonicalLua(fields)
+	}
+	return content, nil
+}
+
+// canonicalRedirect 校验 URL 重定向记录：模式 "目标地址" [preserve-path]
+func canonicalRedirect(fields []string) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("URL record must be: 301|302|frame \"target-url\" [preserve-path]")
+	}
+	data := &models.DNSRecordData{Redirect: strings.ToLower(fields[0]), URL: models.UnquoteRecordString(fields[1])}
+	switch data.Redirect {
+	case models.RedirectPermanent, models.RedirectTemporary, models.RedirectFrame:
+	default:
+		return "", fmt.Errorf("redirect must be 301, 302 or frame")
+	}
+
+	if len(data.URL) > 2048 {
+		return "", fmt.Errorf("target URL is too long")
+	}
+	target, err := url.Parse(data.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", fmt.Errorf("target must be an absolute http or https URL")
+	}
+	if strings.ContainsAny(data.URL, " \t\r\n") {
+		return "", fmt.Errorf("target URL cannot contain whitespace")
+	}
+
+	for _, flag := range fields[2:] {
+		if strings.ToLower(flag) != "preserve-path" {
+			return "", fmt.Errorf("unknown URL record option %q", flag)
+		}
+		data.PreservePath = true
+	}
+	return data.Content("URL")
+}
+
+// countryCodePattern ISO 3166-1 alpha-2 国家代码
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// maxLuaAddresses LUA 记录中每个地址列表的最大长度
+const maxLuaAddresses = 16
+
+// canonicalLua 只接受由结构化字段生成的 LUA 记录：内容必须能解析为支持的函数，
+// 地址、权重、端口和国家代码校验后重新生成，因此不会写入任意 Lua 代码
+func canonicalLua(fields []string) (string, error) {
+	data := models.ParseDNSRecordData("LUA", strings.Join(fields, " "))
+	if data == nil {
+		return "", fmt.Errorf("LUA records must be built from structured data using %s, %s or %s",
+			models.LuaPickWeightedRandom, models.LuaCountry, models.LuaIfPortUp)
+	}
+	if data.LuaType != "A" && data.LuaType != "AAAA" {
+		return "", fmt.Errorf("LUA record type must be A or AAAA")
+	}
+
+	addresses := func(values []string, field string) error {
+		if len(values) == 0 || len(values) > maxLuaAddresses {
+			return fmt.Errorf("%s must contain between 1 and %d addresses", field, maxLuaAddresses)
+		}
+		for i, v := range values {
+			ip := net.ParseIP(v)
+			if ip == nil || (data.LuaType == "A") != (ip.To4() != nil) {
+				return fmt.Errorf("%s: %q is not a valid %s address", field, v, data.LuaType)
+			}
+			values[i] = ip.String()
+		}
+		return nil
+	}
+
+	switch data.LuaFunction {
+	case models.LuaPickWeightedRandom:
+		if len(data.Weighted) == 0 || len(data.Weighted) > maxLuaAddresses {
+			return "", fmt.Errorf("weighted must contain between 1 and %d targets", maxLuaAddresses)
+		}
+		for i := range data.Weighted {
+			target := &data.Weighted[i]
+			if target.Weight < 1 || target.Weight > 1000 {
+				return "", fmt.Errorf("weight must be between 1 and 1000")
+			}
+			single := []string{target.Address}
+			if err := addresses(single, "weighted"); err != nil {
+				return "", err
+			}
+			target.Address = single[0]
+		}
+	case models.LuaIfPortUp:
+		if data.Port == nil || *data.Port < 1 || *data.Port > 65535 {
+			return "", fmt.Errorf("port must be between 1 and 65535")
+		}
+		if err := addresses(data.Addresses, "addresses"); err != nil {
+			return "", err
+		}
+	case models.LuaCountry:
+		if len(data.Countries) == 0 || len(data.Countries) > 50 {
+			return "", fmt.Errorf("countries must contain between 1 and 50 rules")
+		}
+		for i := range data.Countries {
+			rule := &data.Countries[i]
+			if len(rule.Countries) == 0 {
+				return "", fmt.Errorf("each country rule needs at least one country code")
+			}
+			for j, code := range rule.Countries {
+				rule.Countries[j] = strings.ToUpper(code)
+				if !countryCodePattern.MatchString(rule.Countries[j]) {
+					return "", fmt.Errorf("invalid country code %q", code)
+				}
+			}
+			if err := addresses(rule.Addresses, "country addresses"); err != nil {
+				return "", err
+			}
+		}
+		if err := addresses(data.Addresses, "default addresses"); err != nil {
+			return "", err
+		}
+	}
+	return data.Content("LUA")
+}
+
+// canonicalSVCB 校验 SVCB/HTTPS 记录：优先级为 0 时为别名模式，不允许带参数
+func canonicalSVCB(fields []string) (string, error) {
+	if len(fields) < 2 {
+		return "", fmt.Errorf("SVCB/HTTPS record must be: priority target [params...]")
+	}
+	priority, err := parseRecordUint(fields[0], "priority", 65535)
+	if err != nil {
+		return "", err
+	}
+	target, err := canonicalRecordTarget(fields[1], "target")
+	if err != nil {
+		return "", err
+	}
+	if priority == 0 && len(fields) > 2 {
+		return "", fmt.Errorf("alias mode (priority 0) does not allow parameters")
+	}
+
+	params := make(map[string]string)
+	keys := make([]string, 0, len(fields)-2)
+	for _, field := range fields[2:] {
+		key, value, hasValue := strings.Cut(field, "=")
+		key = strings.ToLower(key)
+		value = models.UnquoteRecordString(value)
+		if _, exists := params[key]; exists {
+			return "", fmt.Errorf("duplicate SVCB parameter %s", key)
+		}
+		if hasValue && value == "" {
+			return "", fmt.Errorf("SVCB parameter %s has an empty value", key)
+		}
+		normalized, err := canonicalSvcParam(key, value)
+		if err != nil {
+			return "", err
+		}
+		params[key] = normalized
+		keys = append(keys, key)
+	}
+
+	if mandatory, ok := params["mandatory"]; ok {
+		for _, key := range strings.Split(mandatory, ",") {
+			if _, present := params[key]; !present {
+				return "", fmt.Errorf("mandatory SVCB parameter %s is missing", key)
+			}
+		}
+	}
+	if _, ok := params["no-default-alpn"]; ok {
+		if _, hasALPN := params["alpn"]; !hasALPN {
+			return "", fmt.Errorf("no-default-alpn requires alpn")
+		}
+	}
+
+	models.SortSvcParamKeys(keys)
+	parts := []string{strconv.Itoa(priority), target}
+	for _, key := range keys {
+		if params[key] == "" {
+			parts = append(parts, key)
+		} else {
+			parts = append(parts, key+"="+params[key])
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// canonicalSvcParam 校验单个 SVCB 参数并返回规范化的值
+func canonicalSvcParam(key, value string) (string, error) {
+	list := func() []string {
+		if value == "" {
+			return nil
+		}
+		return strings.Split(value, ",")
+	}
+
+	switch key {
+	case "mandatory":
+		keys := list()
+		if len(keys) == 0 {
+			return "", fmt.Errorf("mandatory requires at least one key")
+		}
+		for i, k := range keys {
+			k = strings.ToLower(k)
+			if k == "mandatory" || !isKnownSvcParamKey(k) {
+				return "", fmt.Errorf("invalid key %q in mandatory", k)
+			}
+			keys[i] = k
+		}
+		models.SortSvcParamKeys(keys)
+		return strings.Join(keys, ","), nil
+	case "alpn":
+		ids := list()
+		if len(ids) == 0 {
+			return "", fmt.Errorf("alpn requires at least one protocol")
+		}
+		for _, id := range ids {
+			if id == "" || len(id) > 255 {
+				return "", fmt.Errorf("invalid alpn protocol %q", id)
+			}
+		}
+		return value, nil
+	case "no-default-alpn":
+		if value != "" {
+			return "", fmt.Errorf("no-default-alpn does not take a value")
+		}
+		return "", nil
+	case "port":
+		port, err := parseRecordUint(value, "port", 65535)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(port), nil
+	case "ipv4hint", "ipv6hint":
+		hints := list()
+		if len(hints) == 0 {
+			return "", fmt.Errorf("%s requires at least one address", key)
+		}
+		for i, hint := range hints {
+			ip := net.ParseIP(hint)
+			isIPv4 := ip != nil && ip.To4() != nil && !strings.Contains(hint, ":")
+			if ip == nil || (key == "ipv4hint") != isIPv4 {
+				return "", fmt.Errorf("invalid address %q in %s", hint, key)
+			}
+			hints[i] = ip.String()
+		}
+		return strings.Join(hints, ","), nil
+	case "ech":
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil || value == "" {
+			return "", fmt.Errorf("ech must be base64 encoded")
+		}
+		return value, nil
+	}
+
+	if strings.HasPrefix(key, "key") {
+		if _, err := parseRecordUint(strings.TrimPrefix(key, "key"), "SVCB parameter key", 65535); err == nil {
+			if strings.ContainsAny(value, " \t") {
+				return models.QuoteRecordString(value), nil
+			}
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("unknown SVCB parameter %s", key)
+}
+
+// isKnownSvcParamKey 判断是否为已知的 SVCB 参数键（包括 keyNNNNN）
+func isKnownSvcParamKey(key string) bool {
+	switch key {
+	case "mandatory", "alpn", "no-default-alpn", "port", "ipv4hint", "ech", "ipv6hint":
+		return true
+	}
+	if strings.HasPrefix(key, "key") {
+		_, err := parseRecordUint(strings.TrimPrefix(key, "key"), "SVCB parameter key", 65535)
+		return err == nil
+	}
+	return false
+}
+
+// canonicalRecordTarget 校验记录中的目标域名，返回小写且以点结尾的形式，"." 表示无目标
+func canonicalRecordTarget(name, field string) (string, error) {
+	name = strings.ToLower(name)
+	if name == "." {
+		return name, nil
+	}
+	if len(name) > 254 || !hostnamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid %s %q", field, name)
+	}
+	return ensureTrailingDot(name), nil
+}
+
+// parseRecordUint 解析记录中的无符号整数字段并检查上限
+func parseRecordUint(value, field string, maxValue int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || n > maxValue || strings.HasPrefix(value, "+") {
+		return 0, fmt.Errorf("%s must be an integer between 0 and %d", field, maxValue)
+	}
+	return n, nil
+}
+
+// parseRecordHex 校验十六进制字段，length 为 0 时只要求非空，返回小写形式
+func parseRecordHex(value, field string, length int) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("%s cannot be empty", field)
+	}
+	if length > 0 && len(value) != length {
+		return "", fmt.Errorf("%s must be %d hex characters", field, length)
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return "", fmt.Errorf("%s must be hexadecimal", field)
+	}
+	return strings.ToLower(value), nil
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+	"opendomain/pkg/timeutil"
+)
+
+// redirectEnabled 判断是否配置了 URL 重定向服务
+func (h *DNSHandler) redirectEnabled() bool {
+	return h.cfg.Redirect.Enabled && (h.cfg.Redirect.IPv4 != "" || h.cfg.Redirect.IPv6 != "")
+}
+
+// redirectAddress 返回 URL 记录在 A/AAAA 记录集中发布的重定向服务地址，未配置时返回空
+func (h *DNSHandler) redirectAddress(recordType string) string {
+	if !h.redirectEnabled() {
+		return ""
+	}
+	switch recordType {
+	case "A":
+		return h.cfg.Redirect.IPv4
+	case "AAAA":
+		return h.cfg.Redirect.IPv6
+	}
+	return ""
+}
+
+// expandRedirectKeys URL 记录不是真正的 DNS 记录，对应的记录集是同名的 A 和 AAAA
+func expandRedirectKeys(keys []rrsetKey) []rrsetKey {
+	expanded := make([]rrsetKey, 0, len(keys))
+	seen := make(map[rrsetKey]bool, len(keys))
+	add := func(key rrsetKey) {
+		if !seen[key] {
+			seen[key] = true
+			expanded = append(expanded, key)
+		}
+	}
+	for _, key := range keys {
+		if key.Type == "URL" {
+			add(rrsetKey{Name: key.Name, Type: "A"})
+			add(rrsetKey{Name: key.Name, Type: "AAAA"})
+			continue
+		}
+		add(key)
+	}
+	return expanded
+}
+
+// redirectEntries 名称下有活跃的 URL 记录时，返回需要合并到 A/AAAA 记录集的重定向服务地址及 TTL
+func (h *DNSHandler) redirectEntries(db *gorm.DB, domainID uint, key rrsetKey) ([]powerdns.RecordEntry, int, error) {
+	address := h.redirectAddress(key.Type)
+	if address == "" {
+		return nil, 0, nil
+	}
+
+	var record models.DNSRecord
+	err := db.Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?", domainID, key.Name, "URL", true).
+		Order("id ASC").First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load URL record for %s: %w", key.Name, err)
+	}
+	return []powerdns.RecordEntry{{Content: address}}, record.TTL, nil
+}
+
+// syncRedirectRecord 将 URL 记录对应的 A/AAAA 记录集推送到 PowerDNS 并更新同步状态
+func (h *DNSHandler) syncRedirectRecord(record *models.DNSRecord, domain *models.Domain) {
+	rrsets, err := h.buildRRsets(h.db, domain, []rrsetKey{{Name: record.Name, Type: "URL"}})
+	if err == nil {
+		err = h.patchZoneRRsets(domain.FullDomain, rrsets)
+	}
+
+	updates := map[string]interface{}{
+		"synced_to_powerdns": true,
+		"sync_error":         nil,
+		"last_synced_at":     timeutil.Now(),
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to sync URL record %s.%s: %v\n", record.Name, domain.FullDomain, err)
+		updates = map[string]interface{}{
+			"synced_to_powerdns": false,
+			"sync_error":         err.Error(),
+		}
+	}
+	h.db.Model(&models.DNSRecord{}).
+		Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?", record.DomainID, record.Name, "URL", true).
+		Updates(updates)
+
+	h.updateDomainSyncStatus(record.DomainID)
+}
+
+// redirectNames 返回域名下有活跃 URL 记录的名称
+func (h *DNSHandler) redirectNames(domainID uint) map[string]bool {
+	var names []string
+	h.db.Model(&models.DNSRecord{}).
+		Where("domain_id = ? AND type = ? AND is_active = ?", domainID, "URL", true).
+		Distinct().Pluck("name", &names)
+
+	result := make(map[string]bool, len(names))
+	for _, name := range names {
+		result[name] = true
+	}
+	return result
+}
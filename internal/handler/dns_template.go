@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/models"
+)
+
+// templatePlaceholder 匹配模板中的 {{变量}}
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+
+// templateSlugPattern 模板标识只能包含小写字母、数字和连字符
+var templateSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`)
+
+// templateVariableName 变量名格式
+var templateVariableName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// templateValuePattern 变量值不能包含空白、引号、反斜杠和花括号，避免拼接出额外的字段或记录
+var templateValuePattern = regexp.MustCompile(`^[^\s"\\{}]{1,255}$`)
+
+// builtinTemplateVariables 由系统提供的变量：域名、记录所在名称及其完整主机名
+var builtinTemplateVariables = map[string]bool{"domain": true, "subdomain": true, "host": true}
+
+// ListDNSTemplates 获取可用的 DNS 模板
+func (h *DNSHandler) ListDNSTemplates(c *gin.Context) {
+	var templates []models.DNSTemplate
+	query := h.db.Order("category ASC, name ASC")
+	if c.Query("category") != "" {
+		query = query.Where("category = ?", c.Query("category"))
+	}
+	if err := query.Where("is_active = ?", true).Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// ApplyTemplate 将模板展开为 DNS 记录并原子地添加到域名
+// 与已有记录完全相同的记录会被跳过，因此重复应用同一模板是安全的
+func (h *DNSHandler) ApplyTemplate(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req models.DNSTemplateApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var template models.DNSTemplate
+	query := h.db.Where("is_active = ?", true)
+	switch {
+	case req.TemplateID != 0:
+		query = query.Where("id = ?", req.TemplateID)
+	case req.Slug != "":
+		query = query.Where("slug = ?", req.Slug)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template_id or slug is required"})
+		return
+	}
+	if err := query.First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "DNS template not found"})
+		return
+	}
+
+	records, err := expandTemplate(&template, domain, req.Subdomain, req.Variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existing []models.DNSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	// 展开后的记录转换为批量创建操作，复用 ApplyChanges 的校验和原子提交
+	var ops []models.DNSChangeOperation
+	var opRecordIndex []int
+	skipped := []models.DNSTemplateRecord{}
+	for i := range records {
+		r := records[i]
+		if templateRecordExists(r, existing) {
+			skipped = append(skipped, r)
+			continue
+		}
+		if r.Type == "URL" && !h.redirectEnabled() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL redirect records are not enabled on this server"})
+			return
+		}
+		op := models.DNSChangeOperation{
+			Action:   "create",
+			Name:     &r.Name,
+			Type:     &r.Type,
+			Content:  &r.Content,
+			Priority: r.Priority,
+		}
+		if r.TTL > 0 {
+			op.TTL = &r.TTL
+		}
+		ops = append(ops, op)
+		opRecordIndex = append(opRecordIndex, i)
+	}
+
+	if len(ops) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "All records from this template already exist",
+			"created": []*models.DNSRecordResponse{},
+			"skipped": skipped,
+		})
+		return
+	}
+
+	plan, errs := planRecordChanges(domain, existing, ops)
+	if len(errs) > 0 {
+		// 错误序号对应模板展开后的记录
+		for i := range errs {
+			if errs[i].Index >= 0 {
+				errs[i].Index = opRecordIndex[errs[i].Index]
+			}
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Template records failed validation",
+			"records": records,
+			"details": errs,
+		})
+		return
+	}
+
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run": true,
+			"records": records,
+			"skipped": skipped,
+		})
+		return
+	}
+
+	if err := h.applyRecordChanges(domain, "apply_template", plan.apply); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to apply template: %v", err)})
+		return
+	}
+
+	created := make([]*models.DNSRecordResponse, len(plan.creates))
+	for i, r := range plan.creates {
+		created[i] = r.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "DNS template applied successfully",
+		"created": created,
+		"skipped": skipped,
+	})
+}
+
+// AdminListDNSTemplates 管理员：获取所有 DNS 模板
+func (h *DNSHandler) AdminListDNSTemplates(c *gin.Context) {
+	var templates []models.DNSTemplate
+	if err := h.db.Order("category ASC, name ASC").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// AdminCreateDNSTemplate 管理员：创建 DNS 模板
+func (h *DNSHandler) AdminCreateDNSTemplate(c *gin.Context) {
+	var req models.DNSTemplateCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := &models.DNSTemplate{
+		Slug:        strings.ToLower(strings.TrimSpace(req.Slug)),
+		Name:        req.Name,
+		Description: req.Description,
+		Category:    req.Category,
+		Variables:   req.Variables,
+		Records:     req.Records,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+	if err := validateTemplate(template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.DNSTemplate{}).Where("slug = ?", template.Slug).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "DNS template slug already exists"})
+		return
+	}
+
+	if err := h.db.Create(template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create DNS template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "DNS template created successfully",
+		"template": template,
+	})
+}
+
+// AdminUpdateDNSTemplate 管理员：更新 DNS 模板，内置模板同样可以修改
+func (h *DNSHandler) AdminUpdateDNSTemplate(c *gin.Context) {
+	var template models.DNSTemplate
+	if err := h.db.First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "DNS template not found"})
+		return
+	}
+
+	var req models.DNSTemplateUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		template.Name = *req.Name
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.Category != nil {
+		template.Category = *req.Category
+	}
+	if req.Variables != nil {
+		template.Variables = req.Variables
+	}
+	if req.Records != nil {
+		template.Records = req.Records
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+	if err := validateTemplate(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Save(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update DNS template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "DNS template updated successfully",
+		"template": template,
+	})
+}
+
+// AdminDeleteDNSTemplate 管理员：删除 DNS 模板，内置模板只能停用
+func (h *DNSHandler) AdminDeleteDNSTemplate(c *gin.Context) {
+	var template models.DNSTemplate
+	if err := h.db.First(&template, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "DNS template not found"})
+		return
+	}
+
+	if template.IsBuiltin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in templates cannot be deleted, deactivate them instead"})
+		return
+	}
+
+	if err := h.db.Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete DNS template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "DNS template deleted successfully"})
+}
+
+// validateTemplate 校验模板定义：变量声明合法，记录类型受支持，引用的变量都已声明
+func validateTemplate(template *models.DNSTemplate) error {
+	if !templateSlugPattern.MatchString(template.Slug) {
+		return fmt.Errorf("slug may only contain lowercase letters, digits and hyphens")
+	}
+	if len(template.Records) == 0 {
+		return fmt.Errorf("template must contain at least one record")
+	}
+	if template.Variables == nil {
+		template.Variables = []models.DNSTemplateVariable{}
+	}
+
+	declared := make(map[string]bool, len(template.Variables))
+	for _, v := range template.Variables {
+		if !templateVariableName.MatchString(v.Name) {
+			return fmt.Errorf("invalid variable name %q", v.Name)
+		}
+		if builtinTemplateVariables[v.Name] {
+			return fmt.Errorf("variable %q is provided by the system and cannot be declared", v.Name)
+		}
+		if declared[v.Name] {
+			return fmt.Errorf("variable %q is declared more than once", v.Name)
+		}
+		if v.Default != "" && !templateValuePattern.MatchString(v.Default) {
+			return fmt.Errorf("default value of variable %q must not contain whitespace, quotes, backslashes or braces", v.Name)
+		}
+		declared[v.Name] = true
+	}
+
+	for i := range template.Records {
+		r := &template.Records[i]
+		r.Type = strings.ToUpper(strings.TrimSpace(r.Type))
+		if !isSupportedRecordType(r.Type) {
+			return fmt.Errorf("record %d: unsupported record type %s", i+1, r.Type)
+		}
+		if r.TTL != 0 && (r.TTL < 60 || r.TTL > 86400) {
+			return fmt.Errorf("record %d: TTL must be between 60 and 86400", i+1)
+		}
+		for _, field := range []string{r.Name, r.Content} {
+			for _, m := range templatePlaceholder.FindAllStringSubmatch(field, -1) {
+				if !declared[m[1]] && !builtinTemplateVariables[m[1]] {
+					return fmt.Errorf("record %d uses undeclared variable {{%s}}", i+1, m[1])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// expandTemplate 用变量值替换模板中的占位符，返回展开后的记录
+// subdomain 为空时记录添加在域名 apex；模板中形如 "_dmarc.{{subdomain}}" 的名称在 apex 上展开为 "_dmarc"
+func expandTemplate(template *models.DNSTemplate, domain *models.Domain, subdomain string, provided map[string]string) ([]models.DNSTemplateRecord, error) {
+	subdomain = strings.ToLower(strings.TrimSpace(subdomain))
+	if subdomain == "" {
+		subdomain = "@"
+	}
+	if subdomain != "@" && (!hostnamePattern.MatchString(subdomain) || strings.HasSuffix(subdomain, ".")) {
+		return nil, fmt.Errorf("invalid subdomain %q", subdomain)
+	}
+
+	host := domain.FullDomain
+	if subdomain != "@" {
+		host = subdomain + "." + domain.FullDomain
+	}
+	values := map[string]string{
+		"domain":    domain.FullDomain,
+		"subdomain": subdomain,
+		"host":      host,
+	}
+
+	var missing []string
+	for _, v := range template.Variables {
+		value := strings.TrimSpace(provided[v.Name])
+		if value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			if v.Required {
+				missing = append(missing, v.Name)
+			}
+			continue
+		}
+		if !templateValuePattern.MatchString(value) {
+			return nil, fmt.Errorf("value of variable %q must not contain whitespace, quotes, backslashes or braces", v.Name)
+		}
+		values[v.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required variables: %s", strings.Join(missing, ", "))
+	}
+
+	var unresolved string
+	replace := func(s string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+			name := templatePlaceholder.FindStringSubmatch(m)[1]
+			value, ok := values[name]
+			if !ok {
+				unresolved = name
+				return m
+			}
+			return value
+		})
+	}
+
+	records := make([]models.DNSTemplateRecord, len(template.Records))
+	for i, r := range template.Records {
+		name := strings.ToLower(replace(r.Name))
+		if name == "" {
+			name = "@"
+		}
+		name = strings.TrimSuffix(name, ".@")
+		records[i] = models.DNSTemplateRecord{
+			Name:     name,
+			Type:     r.Type,
+			Content:  replace(r.Content),
+			TTL:      r.TTL,
+			Priority: r.Priority,
+		}
+	}
+	if unresolved != "" {
+		return nil, fmt.Errorf("variable {{%s}} has no value", unresolved)
+	}
+	return records, nil
+}
+
+// templateRecordExists 判断展开后的记录是否已经存在（按规范化后的内容比较）
+func templateRecordExists(r models.DNSTemplateRecord, existing []models.DNSRecord) bool {
+	candidate := models.DNSRecord{Name: r.Name, Type: r.Type, Content: r.Content, TTL: 3600, Priority: r.Priority}
+	if err := normalizeChangedRecord(&candidate); err != nil {
+		return false
+	}
+	name := normalizeZoneName(candidate.Name)
+	for _, e := range existing {
+		if !e.IsActive || e.Type != candidate.Type || normalizeZoneName(e.Name) != name || e.Content != candidate.Content {
+			continue
+		}
+		if (e.Priority == nil) != (candidate.Priority == nil) || (e.Priority != nil && *e.Priority != *candidate.Priority) {
+			continue
+		}
+		return true
+	}
+	return false
+}
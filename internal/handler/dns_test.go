@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
+	"opendomain/pkg/powerdns"
+)
+
+// failingProvider 所有 PATCH 都失败的 DNS 后端，用于验证回滚
+type failingProvider struct {
+	*dnsprovider.MemoryProvider
+}
+
+func (p failingProvider) PatchRRsets(domain string, rrsets []powerdns.RRset) error {
+	return errors.New("PowerDNS API error: 500 Internal Server Error")
+}
+
+func TestRecordSync(t *testing.T) {
+	tests := []struct {
+		name   string
+		seed   []string // www 的已有 A 记录
+		target int      // 操作的已有记录下标
+		method string
+		body   interface{}
+		want   []string // 操作后内存后端中 www 的 A 记录集
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			body:   models.DNSRecordCreateRequest{Name: "www", Type: "A", Content: "192.0.2.10", TTL: 600},
+			want:   []string{"192.0.2.10"},
+		},
+		{
+			name:   "create joins existing RRset",
+			seed:   []string{"192.0.2.1"},
+			method: http.MethodPost,
+			body:   models.DNSRecordCreateRequest{Name: "www", Type: "A", Content: "192.0.2.10", TTL: 600},
+			want:   []string{"192.0.2.1", "192.0.2.10"},
+		},
+		{
+			name:   "update",
+			seed:   []string{"192.0.2.1"},
+			method: http.MethodPut,
+			body:   models.DNSRecordUpdateRequest{Content: strPtr("192.0.2.2")},
+			want:   []string{"192.0.2.2"},
+		},
+		{
+			name:   "delete keeps remaining records",
+			seed:   []string{"192.0.2.1", "192.0.2.2"},
+			method: http.MethodDelete,
+			want:   []string{"192.0.2.2"},
+		},
+		{
+			name:   "delete last record removes RRset",
+			seed:   []string{"192.0.2.1"},
+			method: http.MethodDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.createUser(t, "alice")
+			domain := env.createDomain(t, user, "foo", "active")
+			var seeded []*models.DNSRecord
+			for _, content := range tt.seed {
+				seeded = append(seeded, env.createRecord(t, domain, "www", "A", content))
+			}
+			h := NewDNSHandlerWithProvider(env.db, env.cfg, env.provider)
+
+			params := gin.Params{idParam("domainId", domain.ID)}
+			if tt.method != http.MethodPost {
+				params = append(params, idParam("recordId", seeded[tt.target].ID))
+			}
+			c, w := newTestContext(t, tt.method, "/", tt.body, user.ID, params)
+			switch tt.method {
+			case http.MethodPost:
+				h.CreateRecord(c)
+			case http.MethodPut:
+				h.UpdateRecord(c)
+			case http.MethodDelete:
+				h.DeleteRecord(c)
+			}
+			expectStatus(t, w, http.StatusOK)
+
+			// 单条记录变更在后台同步
+			waitFor(t, "provider sync", func() bool {
+				return reflect.DeepEqual(env.rrsetContents(t, domain.FullDomain, "www.foo.example.com", "A"), tt.want)
+			})
+			waitFor(t, "records marked synced", func() bool {
+				var unsynced int64
+				env.db.Model(&models.DNSRecord{}).Where("domain_id = ? AND synced_to_powerdns = ?", domain.ID, false).Count(&unsynced)
+				return unsynced == 0
+			})
+		})
+	}
+}
+
+func TestProviderFailureRollsBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   func(www *models.DNSRecord) interface{}
+		handle func(h *DNSHandler, c *gin.Context)
+	}{
+		{
+			name: "changeset",
+			body: func(www *models.DNSRecord) interface{} {
+				return models.DNSChangeSetRequest{Changes: []models.DNSChangeOperation{
+					{Action: "create", Name: strPtr("api"), Type: strPtr("A"), Content: strPtr("192.0.2.20")},
+					{Action: "update", RecordID: www.ID, Content: strPtr("192.0.2.2")},
+				}}
+			},
+			handle: (*DNSHandler).ApplyChanges,
+		},
+		{
+			name: "zone file import",
+			body: func(*models.DNSRecord) interface{} {
+				return gin.H{"zone": "api 600 IN A 192.0.2.20\nwww 600 IN A 192.0.2.2\n"}
+			},
+			handle: (*DNSHandler).ImportZoneFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.createUser(t, "alice")
+			domain := env.createDomain(t, user, "foo", "active")
+			www := env.createRecord(t, domain, "www", "A", "192.0.2.1")
+			h := NewDNSHandlerWithProvider(env.db, env.cfg, failingProvider{env.provider})
+
+			c, w := newTestContext(t, http.MethodPost, "/", tt.body(www), user.ID, gin.Params{idParam("domainId", domain.ID)})
+			tt.handle(h, c)
+			expectStatus(t, w, http.StatusBadGateway)
+
+			var records []models.DNSRecord
+			env.db.Where("domain_id = ?", domain.ID).Find(&records)
+			if len(records) != 1 || records[0].Content != "192.0.2.1" {
+				t.Fatalf("database should be unchanged after rollback, got %+v", records)
+			}
+
+			var snapshots int64
+			env.db.Model(&models.DNSSnapshot{}).Where("domain_id = ?", domain.ID).Count(&snapshots)
+			if snapshots != 0 {
+				t.Errorf("expected no snapshots after rollback, got %d", snapshots)
+			}
+
+			if got := env.rrsetContents(t, domain.FullDomain, "www.foo.example.com", "A"); !reflect.DeepEqual(got, []string{"192.0.2.1"}) {
+				t.Errorf("provider should be unchanged, got %v", got)
+			}
+			if got := env.rrsetContents(t, domain.FullDomain, "api.foo.example.com", "A"); got != nil {
+				t.Errorf("provider should not contain api record, got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/models"
+)
+
+// zoneConflict 整个 zone 范围内的一处记录冲突
+type zoneConflict struct {
+	Rule     string `json:"rule"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	RecordID uint   `json:"record_id,omitempty"`
+	Message  string `json:"message"`
+}
+
+// 冲突规则
+const (
+	conflictDuplicateRecord      = "duplicate_record"
+	conflictCNAMEExclusive       = "cname_exclusive"
+	conflictMultipleCNAME        = "multiple_cname"
+	conflictCNAMEAtApex          = "cname_at_apex"
+	conflictTargetIsCNAME        = "target_is_cname"
+	conflictNSAtApex             = "ns_at_apex"
+	conflictDelegationExclusive  = "delegation_exclusive"
+	conflictOccludedByDelegation = "occluded_by_delegation"
+	conflictDSWithoutDelegation  = "ds_without_delegation"
+	conflictMultipleURL          = "multiple_url"
+	conflictURLExclusive         = "url_exclusive"
+	conflictFailoverManaged      = "failover_managed"
+	conflictLuaExclusive         = "lua_exclusive"
+)
+
+// key 用于比较变更前后的冲突
+func (c zoneConflict) key() string {
+	return strings.Join([]string{c.Rule, c.Name, c.Type, c.Message}, "\x00")
+}
+
+// ListConflicts 检查域名当前所有活跃记录，返回 zone 范围的冲突列表
+func (h *DNSHandler) ListConflicts(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var records []models.DNSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	conflicts := validateZoneRecords(domain, records)
+	c.JSON(http.StatusOK, gin.H{
+		"valid":     len(conflicts) == 0,
+		"conflicts": conflicts,
+	})
+}
+
+// checkZoneChange 校验变更后的记录状态，只返回变更引入的新冲突
+// 变更前已经存在的冲突（历史数据）不阻止与其无关的修改
+func checkZoneChange(domain *models.Domain, before, after []models.DNSRecord) []zoneConflict {
+	existing := make(map[string]bool)
+	for _, c := range validateZoneRecords(domain, before) {
+		existing[c.key()] = true
+	}
+
+	var introduced []zoneConflict
+	for _, c := range validateZoneRecords(domain, after) {
+		if !existing[c.key()] {
+			introduced = append(introduced, c)
+		}
+	}
+	return introduced
+}
+
+// zoneConflictsForRecord 校验写入 record 后的 zone，返回新引入的冲突和违反根域名策略的地方
+// record.ID 不为 0 时视为修改已有记录
+func (h *DNSHandler) zoneConflictsForRecord(domain *models.Domain, record *models.DNSRecord) ([]zoneConflict, error) {
+	var before []models.DNSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Find(&before).Error; err != nil {
+		return nil, err
+	}
+
+	after := make([]models.DNSRecord, 0, len(before)+1)
+	for _, r := range before {
+		if record.ID == 0 || r.ID != record.ID {
+			after = append(after, r)
+		}
+	}
+	after = append(after, *record)
+	conflicts := checkZoneChange(domain, before, after)
+	return append(conflicts, checkZonePolicy(domain, before, after, []models.DNSRecord{*record})...), nil
+}
+
+// zoneConflictsForDiffs 校验按记录集差异替换后的 zone，返回新引入的冲突和违反根域名策略的地方
+func (h *DNSHandler) zoneConflictsForDiffs(domain *models.Domain, diffs []rrsetDiff) ([]zoneConflict, error) {
+	var before []models.DNSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Find(&before).Error; err != nil {
+		return nil, err
+	}
+
+	replaced := make(map[rrsetKey]bool, len(diffs))
+	for _, d := range diffs {
+		replaced[rrsetKey{Name: d.Name, Type: d.Type}] = true
+	}
+
+	// 故障转移托管的记录集只能通过故障转移组修改
+	var conflicts []zoneConflict
+	for _, r := range before {
+		key := rrsetKey{Name: r.Name, Type: r.Type}
+		if r.FailoverGroupID != nil && replaced[key] {
+			conflicts = append(conflicts, zoneConflict{
+				Rule:     conflictFailoverManaged,
+				Name:     normalizeZoneName(r.Name),
+				Type:     r.Type,
+				RecordID: r.ID,
+				Message:  fmt.Sprintf("%s records at %s are managed by a failover group and cannot be replaced", r.Type, r.Name),
+			})
+			delete(replaced, key)
+		}
+	}
+
+	after := make([]models.DNSRecord, 0, len(before))
+	for _, r := range before {
+		if !replaced[rrsetKey{Name: r.Name, Type: r.Type}] {
+			after = append(after, r)
+		}
+	}
+	var changed []models.DNSRecord
+	for _, d := range diffs {
+		for _, view := range d.After {
+			changed = append(changed, models.DNSRecord{
+				DomainID: domain.ID,
+				Name:     view.Name,
+				Type:     view.Type,
+				Content:  view.Content,
+				TTL:      view.TTL,
+				Priority: view.Priority,
+				IsActive: true,
+			})
+		}
+	}
+	after = append(after, changed...)
+	conflicts = append(conflicts, checkZoneChange(domain, before, after)...)
+	return append(conflicts, checkZonePolicy(domain, before, after, changed)...), nil
+}
+
+// respondZoneConflicts 以统一格式返回冲突列表
+func respondZoneConflicts(c *gin.Context, conflicts []zoneConflict) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":     conflicts[0].Message,
+		"conflicts": conflicts,
+	})
+}
+
+// validateZoneRecords 对一个域名的全部活跃记录做 zone 范围的校验：
+//   - 同一记录集中不能有重复记录
+//   - CNAME 不能与同名的其他记录共存，每个名称只能有一个 CNAME，apex 不能使用 CNAME
+//   - MX/SRV 的目标如果在本 zone 内，不能指向 CNAME
+//   - apex NS 由平台管理；子名称的 NS 表示委派，委派点只能有 NS/DS，委派点以下只允许 glue A/AAAA
+//   - DS 只能出现在委派点
+//   - URL 记录占用同名的 A/AAAA 记录集，每个名称只能有一个
+//   - 故障转移组托管的记录集中不能有其他记录
+//   - LUA 记录生成的类型在同一名称下不能再有同类型的普通记录或 LUA 记录
+func validateZoneRecords(domain *models.Domain, records []models.DNSRecord) []zoneConflict {
+	var conflicts []zoneConflict
+	add := func(rule string, r models.DNSRecord, format string, args ...interface{}) {
+		conflicts = append(conflicts, zoneConflict{
+			Rule:     rule,
+			Name:     r.Name,
+			Type:     r.Type,
+			RecordID: r.ID,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	active := make([]models.DNSRecord, 0, len(records))
+	typesByName := make(map[string]map[string]int)
+	seen := make(map[string]bool)
+	managed := make(map[rrsetKey]bool)
+	luaTypes := make(map[rrsetKey]int)
+	for _, r := range records {
+		if !r.IsActive {
+			continue
+		}
+		r.Name = normalizeZoneName(r.Name)
+		active = append(active, r)
+		if r.FailoverGroupID != nil {
+			managed[rrsetKey{Name: r.Name, Type: r.Type}] = true
+		}
+		if r.Type == "LUA" {
+			luaTypes[rrsetKey{Name: r.Name, Type: strings.SplitN(r.Content, " ", 2)[0]}]++
+		}
+
+		if typesByName[r.Name] == nil {
+			typesByName[r.Name] = make(map[string]int)
+		}
+		typesByName[r.Name][r.Type]++
+
+		priority := ""
+		if r.Priority != nil {
+			priority = fmt.Sprintf("%d", *r.Priority)
+		}
+		dedupKey := strings.Join([]string{r.Name, r.Type, priority, r.Content}, "\x00")
+		if seen[dedupKey] {
+			add(conflictDuplicateRecord, r, "duplicate %s record at %s: %s", r.Type, r.Name, r.Content)
+		}
+		seen[dedupKey] = true
+	}
+
+	// 委派点：apex 以外有 NS 记录的名称
+	var delegations []string
+	for name, types := range typesByName {
+		if name != "@" && types["NS"] > 0 {
+			delegations = append(delegations, name)
+		}
+	}
+	sort.Strings(delegations)
+	delegationOf := func(name string) string {
+		for _, d := range delegations {
+			if strings.HasSuffix(name, "."+d) {
+				return d
+			}
+		}
+		return ""
+	}
+
+	// 同一名称的同一规则只报告一次
+	reported := make(map[string]bool)
+	first := func(rule, name string) bool {
+		key := rule + "\x00" + name
+		if reported[key] {
+			return false
+		}
+		reported[key] = true
+		return true
+	}
+
+	for _, r := range active {
+		types := typesByName[r.Name]
+
+		switch r.Type {
+		case "CNAME":
+			if r.Name == "@" {
+				add(conflictCNAMEAtApex, r, "CNAME record is not allowed at the zone apex, use A/AAAA or a URL record instead")
+			}
+			if types["CNAME"] > 1 && first(conflictMultipleCNAME, r.Name) {
+				add(conflictMultipleCNAME, r, "only one CNAME record is allowed at %s", r.Name)
+			}
+			if len(types) > 1 && first(conflictCNAMEExclusive, r.Name) {
+				add(conflictCNAMEExclusive, r, "CNAME record cannot coexist with other record types at %s (%s)", r.Name, strings.Join(otherTypes(types, "CNAME"), ", "))
+			}
+		case "MX", "SRV":
+			target := recordTarget(r)
+			if targetName, inZone := zoneRelativeName(target, domain.FullDomain); inZone && typesByName[targetName]["CNAME"] > 0 {
+				add(conflictTargetIsCNAME, r, "%s target %s is a CNAME, it must point to a name with A/AAAA records", r.Type, target)
+			}
+		case "NS":
+			if r.Name == "@" {
+				add(conflictNSAtApex, r, "NS records at the zone apex are managed by the platform, use custom nameservers to delegate the whole domain")
+			} else if others := otherTypes(types, "NS", "DS"); len(others) > 0 && first(conflictDelegationExclusive, r.Name) {
+				add(conflictDelegationExclusive, r, "%s is delegated with NS records and cannot have other records (%s)", r.Name, strings.Join(others, ", "))
+			}
+		case "DS":
+			if types["NS"] == 0 {
+				add(conflictDSWithoutDelegation, r, "DS record at %s requires NS records delegating that name", r.Name)
+			}
+		case "LUA":
+			luaType := strings.SplitN(r.Content, " ", 2)[0]
+			if types[luaType] > 0 && first(conflictLuaExclusive, r.Name+"/"+luaType) {
+				add(conflictLuaExclusive, r, "LUA %s record cannot coexist with %s records at %s", luaType, luaType, r.Name)
+			} else if luaTypes[rrsetKey{Name: r.Name, Type: luaType}] > 1 && first(conflictLuaExclusive, r.Name+"/"+luaType) {
+				add(conflictLuaExclusive, r, "only one LUA %s record is allowed at %s", luaType, r.Name)
+			}
+		case "URL":
+			if types["URL"] > 1 && first(conflictMultipleURL, r.Name) {
+				add(conflictMultipleURL, r, "only one URL record is allowed at %s", r.Name)
+			}
+			if types["A"] > 0 || types["AAAA"] > 0 {
+				add(conflictURLExclusive, r, "URL record cannot coexist with A or AAAA records at %s", r.Name)
+			}
+		}
+
+		if managed[rrsetKey{Name: r.Name, Type: r.Type}] && r.FailoverGroupID == nil {
+			add(conflictFailoverManaged, r, "%s records at %s are managed by a failover group", r.Type, r.Name)
+		}
+
+		if parent := delegationOf(r.Name); parent != "" && r.Type != "A" && r.Type != "AAAA" {
+			add(conflictOccludedByDelegation, r, "%s record at %s is hidden by the delegation of %s, only glue A/AAAA records are allowed below it", r.Type, r.Name, parent)
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if conflicts[i].Name != conflicts[j].Name {
+			return conflicts[i].Name < conflicts[j].Name
+		}
+		return conflicts[i].Type < conflicts[j].Type
+	})
+	return conflicts
+}
+
+// normalizeZoneName 统一记录名的大小写和空白，空名称视为 apex
+func normalizeZoneName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// otherTypes 返回名称下除 exclude 以外的记录类型
+func otherTypes(types map[string]int, exclude ...string) []string {
+	var others []string
+	for t := range types {
+		skip := false
+		for _, e := range exclude {
+			if t == e {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			others = append(others, t)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// recordTarget 返回 MX/SRV 记录的目标主机名（小写，不带结尾的点）
+// MX 内容即目标主机；SRV 内容为 "weight port target"（也兼容带优先级的四段格式），目标为最后一段
+func recordTarget(r models.DNSRecord) string {
+	fields := strings.Fields(r.Content)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(fields[len(fields)-1]), ".")
+}
+
+// zoneRelativeName 将完整主机名转换为 zone 内的相对名称，不在 zone 内时返回 false
+func zoneRelativeName(fqdn, fullDomain string) (string, bool) {
+	fullDomain = strings.ToLower(fullDomain)
+	if fqdn == fullDomain {
+		return "@", true
+	}
+	if strings.HasSuffix(fqdn, "."+fullDomain) {
+		return strings.TrimSuffix(fqdn, "."+fullDomain), true
+	}
+	return "", false
+}
@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+	"opendomain/pkg/zonefile"
+)
+
+// maxZoneFileSize 导入的 zone 文件大小上限
+const maxZoneFileSize = 1 << 20
+
+// zoneRecordView 记录集比较和差异展示使用的记录视图
+type zoneRecordView struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Priority *int   `json:"priority,omitempty"`
+}
+
+// rrsetDiff 表示一个记录集的变化
+type rrsetDiff struct {
+	Name   string           `json:"name"`
+	Type   string           `json:"type"`
+	Action string           `json:"action"` // add/update/delete
+	Before []zoneRecordView `json:"before,omitempty"`
+	After  []zoneRecordView `json:"after,omitempty"`
+}
+
+// ExportZoneFile 将域名的活跃 DNS 记录导出为 RFC 1035 zone 文件，URL 等非标准类型以注释输出
+func (h *DNSHandler) ExportZoneFile(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var records []models.DNSRecord
+	if err := h.db.Where("domain_id = ? AND is_active = ?", domain.ID, true).Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	zoneRecords := make([]zonefile.Record, 0, len(records))
+	for _, r := range records {
+		zoneRecords = append(zoneRecords, zonefile.Record{
+			Name:  ensureTrailingDot(buildRecordFQDN(r.Name, domain.FullDomain)),
+			TTL:   r.TTL,
+			Class: "IN",
+			Type:  r.Type,
+			Data:  zoneRecordData(r.Type, r.Content, r.Priority),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := zonefile.Write(&buf, domain.FullDomain, 3600, zoneRecords); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate zone file"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", domain.FullDomain+".zone"))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", buf.Bytes())
+}
+
+// ImportZoneFile 从 RFC 1035 zone 文件导入 DNS 记录
+// 支持 JSON 请求体 {"zone": "...", "dry_run": true, "replace": false}，
+// 也支持直接以文本提交 zone 文件并通过 ?dry_run=1&replace=1 指定参数。
+// 默认只替换文件中出现的记录集；replace 为 true 时删除文件中未出现的记录集。
+// dry_run 为 true 时只返回差异，不做任何修改。
+func (h *DNSHandler) ImportZoneFile(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Zone    string `json:"zone" binding:"required"`
+		DryRun  bool   `json:"dry_run"`
+		Replace bool   `json:"replace"`
+	}
+
+	if strings.Contains(c.ContentType(), "json") {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxZoneFileSize+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read zone file"})
+			return
+		}
+		req.Zone = string(body)
+		req.DryRun = isTruthy(c.Query("dry_run"))
+		req.Replace = isTruthy(c.Query("replace"))
+	}
+
+	if len(req.Zone) > maxZoneFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Zone file is too large"})
+		return
+	}
+
+	desired, skipped, err := h.parseZoneFile(domain, req.Zone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := h.loadRecordViews(h.db, domain.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	// 非标准类型导出为注释，文件中不会出现，replace 时保留这些记录集
+	if req.Replace {
+		for key, views := range current {
+			if _, ok := desired[key]; !ok && !zonefile.IsStandardType(key.Type) {
+				desired[key] = views
+			}
+		}
+	}
+
+	diffs := diffRecordViews(current, desired, req.Replace)
+
+	conflicts, err := h.zoneConflictsForDiffs(domain, diffs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DNS records"})
+		return
+	}
+
+	response := gin.H{
+		"dry_run":   req.DryRun,
+		"changes":   diffs,
+		"summary":   summarizeDiffs(diffs),
+		"skipped":   skipped,
+		"conflicts": conflicts,
+	}
+
+	if req.DryRun || len(diffs) == 0 {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if len(conflicts) > 0 {
+		response["error"] = "Zone file conflicts with existing records: " + conflicts[0].Message
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	err = h.applyRecordChanges(domain, "import_zonefile", func(tx *gorm.DB) ([]rrsetKey, error) {
+		return replaceRecordSets(tx, domain.ID, diffs)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to import zone file: %v", err)})
+		return
+	}
+
+	response["message"] = "Zone file imported successfully"
+	c.JSON(http.StatusOK, response)
+}
+
+// parseZoneFile 解析并校验 zone 文件，返回按记录集分组的期望状态以及被跳过的记录说明
+func (h *DNSHandler) parseZoneFile(domain *models.Domain, zoneText string) (map[rrsetKey][]zoneRecordView, []string, error) {
+	parsed, err := zonefile.Parse(strings.NewReader(zoneText), domain.FullDomain, 3600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid zone file: %w", err)
+	}
+
+	apex := ensureTrailingDot(strings.ToLower(domain.FullDomain))
+	minTTL, maxTTL := recordTTLBounds(domain)
+	desired := make(map[rrsetKey][]zoneRecordView)
+	var skipped []string
+
+	for _, rec := range parsed {
+		if rec.Name != apex && !strings.HasSuffix(rec.Name, "."+apex) {
+			return nil, nil, fmt.Errorf("record %s is outside of zone %s", rec.Name, domain.FullDomain)
+		}
+		if rec.Class != "IN" {
+			return nil, nil, fmt.Errorf("record %s %s: only class IN is supported", rec.Name, rec.Type)
+		}
+
+		// SOA 和 apex NS 由平台管理
+		if rec.Type == "SOA" || (rec.Type == "NS" && rec.Name == apex) {
+			skipped = append(skipped, fmt.Sprintf("%s %s (managed by the platform)", rec.Name, rec.Type))
+			continue
+		}
+
+		if !isSupportedRecordType(rec.Type) {
+			return nil, nil, fmt.Errorf("record %s: unsupported record type %s", rec.Name, rec.Type)
+		}
+		if rec.TTL < minTTL || rec.TTL > maxTTL {
+			return nil, nil, fmt.Errorf("record %s %s: TTL must be between %d and %d", rec.Name, rec.Type, minTTL, maxTTL)
+		}
+
+		content, priority := parseRecordContent(rec.Type, rec.Data)
+		content, err := normalizeRecordContent(rec.Type, content, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("record %s %s: %v", rec.Name, rec.Type, err)
+		}
+
+		view := zoneRecordView{
+			Name:     extractRecordName(rec.Name, domain.FullDomain),
+			Type:     rec.Type,
+			Content:  content,
+			TTL:      rec.TTL,
+			Priority: priority,
+		}
+		key := rrsetKey{Name: view.Name, Type: view.Type}
+
+		// 同一记录集只能有一个 TTL，以第一条为准；重复记录忽略
+		duplicate := false
+		for _, existing := range desired[key] {
+			view.TTL = existing.TTL
+			if recordViewData(existing) == recordViewData(view) {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			desired[key] = append(desired[key], view)
+		}
+	}
+
+	return desired, skipped, nil
+}
+
+// loadRecordViews 加载域名当前的活跃记录，按记录集分组
+func (h *DNSHandler) loadRecordViews(db *gorm.DB, domainID uint) (map[rrsetKey][]zoneRecordView, error) {
+	var records []models.DNSRecord
+	if err := db.Where("domain_id = ? AND is_active = ?", domainID, true).Order("id ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	views := make(map[rrsetKey][]zoneRecordView)
+	for _, r := range records {
+		key := rrsetKey{Name: r.Name, Type: r.Type}
+		views[key] = append(views[key], zoneRecordView{
+			Name:     r.Name,
+			Type:     r.Type,
+			Content:  r.Content,
+			TTL:      r.TTL,
+			Priority: r.Priority,
+		})
+	}
+	return views, nil
+}
+
+// replaceRecordSets 在事务中按差异替换记录集：删除该记录集的所有记录后写入新记录
+func replaceRecordSets(tx *gorm.DB, domainID uint, diffs []rrsetDiff) ([]rrsetKey, error) {
+	keys := make([]rrsetKey, 0, len(diffs))
+	for _, d := range diffs {
+		if err := tx.Where("domain_id = ? AND name = ? AND type = ?", domainID, d.Name, d.Type).
+			Delete(&models.DNSRecord{}).Error; err != nil {
+			return nil, err
+		}
+
+		for _, view := range d.After {
+			record := &models.DNSRecord{
+				DomainID: domainID,
+				Name:     view.Name,
+				Type:     view.Type,
+				Content:  view.Content,
+				TTL:      view.TTL,
+				Priority: view.Priority,
+				IsActive: true,
+			}
+			if err := tx.Create(record).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		keys = append(keys, rrsetKey{Name: d.Name, Type: d.Type})
+	}
+	return keys, nil
+}
+
+// diffRecordViews 比较两个记录集合
+// replace 为 false 时只比较 desired 中出现的记录集
+func diffRecordViews(current, desired map[rrsetKey][]zoneRecordView, replace bool) []rrsetDiff {
+	var diffs []rrsetDiff
+
+	for key, after := range desired {
+		before := current[key]
+		switch {
+		case len(before) == 0:
+			diffs = append(diffs, rrsetDiff{Name: key.Name, Type: key.Type, Action: "add", After: after})
+		case !sameRecordViews(before, after):
+			diffs = append(diffs, rrsetDiff{Name: key.Name, Type: key.Type, Action: "update", Before: before, After: after})
+		}
+	}
+
+	if replace {
+		for key, before := range current {
+			if _, exists := desired[key]; !exists {
+				diffs = append(diffs, rrsetDiff{Name: key.Name, Type: key.Type, Action: "delete", Before: before})
+			}
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Name != diffs[j].Name {
+			return diffs[i].Name < diffs[j].Name
+		}
+		return diffs[i].Type < diffs[j].Type
+	})
+	return diffs
+}
+
+// summarizeDiffs 统计各类变化的数量
+func summarizeDiffs(diffs []rrsetDiff) gin.H {
+	summary := map[string]int{"add": 0, "update": 0, "delete": 0}
+	for _, d := range diffs {
+		summary[d.Action]++
+	}
+	return gin.H{
+		"added":   summary["add"],
+		"updated": summary["update"],
+		"deleted": summary["delete"],
+	}
+}
+
+// sameRecordViews 判断两个记录集内容是否一致（忽略顺序）
+func sameRecordViews(a, b []zoneRecordView) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keysA := make([]string, len(a))
+	keysB := make([]string, len(b))
+	for i := range a {
+		keysA[i] = fmt.Sprintf("%d %s", a[i].TTL, recordViewData(a[i]))
+		keysB[i] = fmt.Sprintf("%d %s", b[i].TTL, recordViewData(b[i]))
+	}
+	sort.Strings(keysA)
+	sort.Strings(keysB)
+	for i := range keysA {
+		if keysA[i] != keysB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordViewData 返回记录在 zone 文件中的 RDATA，用于比较
+func recordViewData(v zoneRecordView) string {
+	return zoneRecordData(v.Type, v.Content, v.Priority)
+}
+
+// zoneRecordData 将数据库记录转换为 zone 文件中的 RDATA
+func zoneRecordData(recordType, content string, priority *int) string {
+	if recordType == "TXT" && !strings.HasPrefix(content, "\"") {
+		return quoteTXT(content)
+	}
+	return powerdns.FormatContent(recordType, content, priority)
+}
+
+// quoteTXT 将文本转为 TXT 记录的字符串格式，超过 255 字节时拆分为多个字符串
+func quoteTXT(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	var parts []string
+	for len(s) > 255 {
+		parts = append(parts, `"`+escaped.Replace(s[:255])+`"`)
+		s = s[255:]
+	}
+	parts = append(parts, `"`+escaped.Replace(s)+`"`)
+	return strings.Join(parts, " ")
+}
+
+// isTruthy 解析查询参数中的布尔值
+func isTruthy(v string) bool {
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
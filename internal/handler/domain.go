@@ -18,6 +18,8 @@ import (
 	"opendomain/internal/config"
 	"opendomain/internal/middleware"
 	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
+	"opendomain/pkg/idn"
 	"opendomain/pkg/powerdns"
 	"opendomain/pkg/timeutil"
 )
@@ -25,14 +27,19 @@ import (
 type DomainHandler struct {
 	db   *gorm.DB
 	cfg  *config.Config
-	pdns *powerdns.Client
+	pdns dnsprovider.Provider
 }
 
 func NewDomainHandler(db *gorm.DB, cfg *config.Config) *DomainHandler {
+	return NewDomainHandlerWithProvider(db, cfg, defaultDNSProvider(cfg))
+}
+
+// NewDomainHandlerWithProvider 使用指定的 DNS 后端创建处理器
+func NewDomainHandlerWithProvider(db *gorm.DB, cfg *config.Config, provider dnsprovider.Provider) *DomainHandler {
 	return &DomainHandler{
 		db:   db,
 		cfg:  cfg,
-		pdns: powerdns.NewClient(cfg.PowerDNS.APIURL, cfg.PowerDNS.APIKey),
+		pdns: provider,
 	}
 }
 
@@ -80,31 +87,24 @@ func (h *DomainHandler) SearchDomain(c *gin.Context) {
 		return
 	}
 
-	// 验证子域名格式
-	if !isValidSubdomain(req.Subdomain) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":     middleware.T(c, "error.domain_invalid"),
-			"available": false,
-		})
+	// 国际化域名转换为 punycode 并检查长度
+	displaySubdomain, ok := normalizeRequestSubdomain(c, &req.Subdomain, &rootDomain)
+	if !ok {
 		return
 	}
 
-	// 检查长度
-	if len(req.Subdomain) < rootDomain.MinLength || len(req.Subdomain) > rootDomain.MaxLength {
+	// 验证子域名格式
+	if !isValidSubdomain(req.Subdomain) {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": fmt.Sprintf("Subdomain length must be between %d and %d characters",
-				rootDomain.MinLength, rootDomain.MaxLength),
+			"error":     middleware.T(c, "error.domain_invalid"),
 			"available": false,
 		})
 		return
 	}
 
-	// 检查黑名单
-	if isBlacklisted(req.Subdomain) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":     "This subdomain is reserved",
-			"available": false,
-		})
+	// 检查保留名称，溢价名称可以购买
+	premium, ok := checkReservedName(c, h.db, req.Subdomain, rootDomain.ID)
+	if !ok {
 		return
 	}
 
@@ -127,10 +127,12 @@ func (h *DomainHandler) SearchDomain(c *gin.Context) {
 	}
 
 	response := gin.H{
-		"available":   available,
-		"subdomain":   req.Subdomain,
-		"root_domain": rootDomain.Domain,
-		"full_domain": fullDomain,
+		"available":           available,
+		"subdomain":           req.Subdomain,
+		"root_domain":         rootDomain.Domain,
+		"full_domain":         fullDomain,
+		"subdomain_unicode":   displaySubdomain,
+		"full_domain_unicode": displaySubdomain + "." + idn.ToUnicode(rootDomain.Domain),
 	}
 
 	if reserved {
@@ -138,6 +140,12 @@ func (h *DomainHandler) SearchDomain(c *gin.Context) {
 		response["message"] = "This domain is reserved and can only be activated by syncing from FOSSBilling"
 	}
 
+	if premium != nil {
+		response["premium"] = true
+		response["price_per_year"] = premium.PricePerYear
+		response["lifetime_price"] = premium.LifetimePrice
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -193,14 +201,30 @@ func (h *DomainHandler) RegisterDomain(c *gin.Context) {
 		return
 	}
 
+	// 国际化域名转换为 punycode 并检查长度
+	displaySubdomain, ok := normalizeRequestSubdomain(c, &req.Subdomain, &rootDomain)
+	if !ok {
+		return
+	}
+
 	// 验证域名
 	if !isValidSubdomain(req.Subdomain) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subdomain format"})
 		return
 	}
 
-	if isBlacklisted(req.Subdomain) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "This subdomain is reserved"})
+	premium, ok := checkReservedName(c, h.db, req.Subdomain, rootDomain.ID)
+	if !ok {
+		return
+	}
+	if premium != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "This is a premium name. Please create an order first.",
+			"requires_payment": true,
+			"premium":          true,
+			"price_per_year":   premium.PricePerYear,
+			"lifetime_price":   premium.LifetimePrice,
+		})
 		return
 	}
 
@@ -273,6 +297,8 @@ func (h *DomainHandler) RegisterDomain(c *gin.Context) {
 			RootDomainID:          req.RootDomainID,
 			Subdomain:             req.Subdomain,
 			FullDomain:            fullDomain,
+			SubdomainUnicode:      displaySubdomain,
+			FullDomainUnicode:     displaySubdomain + "." + idn.ToUnicode(rootDomain.Domain),
 			Status:                "active",
 			RegisteredAt:          now,
 			ExpiresAt:             now.AddDate(1, 0, 0), // 1 年后过期
@@ -433,6 +459,8 @@ func (h *DomainHandler) ListMyDomains(c *gin.Context) {
 			"root_domain_id":          resp.RootDomainID,
 			"subdomain":               resp.Subdomain,
 			"full_domain":             resp.FullDomain,
+			"subdomain_unicode":       resp.SubdomainUnicode,
+			"full_domain_unicode":     resp.FullDomainUnicode,
 			"status":                  resp.Status,
 			"registered_at":           resp.RegisteredAt,
 			"expires_at":              resp.ExpiresAt,
@@ -440,6 +468,7 @@ func (h *DomainHandler) ListMyDomains(c *gin.Context) {
 			"nameservers":             resp.Nameservers,
 			"use_default_nameservers": resp.UseDefaultNameservers,
 			"dns_synced":              resp.DNSSynced,
+			"lifecycle":               resp.Lifecycle,
 			"root_domain":             resp.RootDomain,
 		}
 
@@ -602,6 +631,11 @@ func (h *DomainHandler) ModifyNameservers(c *gin.Context) {
 		return
 	}
 
+	// 切回默认 NS 后 DS 记录不再有意义
+	if isDefault {
+		h.db.Where("domain_id = ?", domain.ID).Delete(&models.DomainDSRecord{})
+	}
+
 	// 在 PowerDNS 中更新 NS 记录
 	if domain.RootDomain != nil {
 		go h.updateDomainNSRecordsInPowerDNS(&domain, req.Nameservers, isDefault)
@@ -635,8 +669,8 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 		return
 	}
 
-	if domain.Status == "suspended" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
+	if reason := renewalBlockedReason(&domain); reason != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": reason})
 		return
 	}
 
@@ -657,11 +691,20 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 		return
 	}
 
-	// 如果是付费域名，需要创建续费订单
-	if !domain.RootDomain.IsFree {
-		// 计算价格
+	// 赎回期续费需额外支付赎回费
+	fee := redemptionFee(&domain)
+	previousStatus := domain.Status
+
+	// 如果是付费域名或需要支付赎回费，需要创建续费订单
+	if !domain.RootDomain.IsFree || fee > 0 {
+		// 计算价格，免费域名只收取赎回费
 		var basePrice float64
-		if req.IsLifetime {
+		if domain.RootDomain.IsFree {
+			if req.IsLifetime {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Lifetime renewal is not available for free domains"})
+				return
+			}
+		} else if req.IsLifetime {
 			if domain.RootDomain.LifetimePrice == nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Lifetime pricing not available"})
 				return
@@ -674,6 +717,7 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 			}
 			basePrice = *domain.RootDomain.PricePerYear * float64(req.Years)
 		}
+		basePrice += fee
 
 		// 应用优惠券
 		var discountAmount float64
@@ -763,11 +807,12 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 				newExpiry = domain.ExpiresAt.AddDate(req.Years, 0, 0)
 			}
 
-			if err := h.db.Model(&domain).Update("expires_at", newExpiry).Error; err != nil {
+			if err := h.db.Model(&domain).Updates(renewalUpdates(&domain, newExpiry)).Error; err != nil {
 				fmt.Printf("Failed to update domain expires_at: %v\n", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew domain", "details": err.Error()})
 				return
 			}
+			restoreRenewedDNS(h.pdns, &domain, previousStatus)
 
 			c.JSON(http.StatusOK, gin.H{
 				"message":          "Domain renewed successfully",
@@ -775,6 +820,7 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 				"order_id":         order.ID,
 				"order_number":     order.OrderNumber,
 				"base_price":       basePrice,
+				"redemption_fee":   fee,
 				"discount_amount":  discountAmount,
 				"final_price":      finalPrice,
 				"coupon_applied":   couponID != nil,
@@ -790,6 +836,7 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 			"order_id":         order.ID,
 			"order_number":     order.OrderNumber,
 			"base_price":       basePrice,
+			"redemption_fee":   fee,
 			"discount_amount":  discountAmount,
 			"final_price":      finalPrice,
 			"coupon_applied":   couponID != nil,
@@ -800,11 +847,12 @@ func (h *DomainHandler) RenewDomain(c *gin.Context) {
 
 	// 免费域名直接续费
 	newExpiry := domain.ExpiresAt.AddDate(req.Years, 0, 0)
-	if err := h.db.Model(&domain).Update("expires_at", newExpiry).Error; err != nil {
+	if err := h.db.Model(&domain).Updates(renewalUpdates(&domain, newExpiry)).Error; err != nil {
 		fmt.Printf("Failed to update free domain expires_at: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew domain", "details": err.Error()})
 		return
 	}
+	restoreRenewedDNS(h.pdns, &domain, previousStatus)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    "Domain renewed successfully",
@@ -857,7 +905,7 @@ func (h *DomainHandler) generateOrderNumber() string {
 	return fmt.Sprintf("ORD%d%s", timestamp, randomStr[:8])
 }
 
-// TransferDomain 转移域名（站内转移）
+// TransferDomain 发起域名转移（站内转移），接收方确认后才会变更所有者
 func (h *DomainHandler) TransferDomain(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
 	if !exists {
@@ -883,11 +931,12 @@ func (h *DomainHandler) TransferDomain(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
 		return
 	}
-
-	var req struct {
-		Target string `json:"target" binding:"required"` // Email or username
+	if models.IsLifecycleStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Expired domains must be renewed before they can be transferred"})
+		return
 	}
 
+	var req models.DomainTransferRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -906,16 +955,65 @@ func (h *DomainHandler) TransferDomain(c *gin.Context) {
 		return
 	}
 
-	// 执行转移
-	if err := h.db.Model(&domain).Update("user_id", targetUser.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer domain"})
+	if targetUser.Status != "" && targetUser.Status != "active" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target user cannot receive domains"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Domain transferred successfully",
-		"new_owner": targetUser.Email,
-		"domain":    domain.FullDomain,
+	// 每个域名同时只能有一个待处理的转移
+	var pendingCount int64
+	h.db.Model(&models.DomainTransfer{}).Where("domain_id = ? AND status = ?", domain.ID, models.TransferStatusPending).Count(&pendingCount)
+	if pendingCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This domain already has a pending transfer"})
+		return
+	}
+
+	// 提前检查接收方配额，接受时还会再检查一次
+	if activeDomainCount(h.db, targetUser.ID) >= int64(targetUser.DomainQuota) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          "Target user has reached their domain quota",
+			"quota_exceeded": true,
+		})
+		return
+	}
+
+	var sender models.User
+	h.db.First(&sender, userID)
+
+	expireDays := h.cfg.Domain.TransferExpireDays
+	if expireDays <= 0 {
+		expireDays = 7
+	}
+	transfer := &models.DomainTransfer{
+		DomainID:   domain.ID,
+		FromUserID: userID,
+		ToUserID:   targetUser.ID,
+		Status:     models.TransferStatusPending,
+		Message:    strings.TrimSpace(req.Message),
+		ExpiresAt:  timeutil.Now().AddDate(0, 0, expireDays),
+		Domain:     &domain,
+		FromUser:   &sender,
+		ToUser:     &targetUser,
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Domain", "FromUser", "ToUser").Create(transfer).Error; err != nil {
+			return err
+		}
+		return recordTransferHistory(tx, transfer, "transfer_requested", "transfer_received",
+			fmt.Sprintf("Transfer to %s requested", targetUser.Username),
+			fmt.Sprintf("Transfer from %s received", sender.Username))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transfer"})
+		return
+	}
+
+	h.notifyTransferRecipient(transfer)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Transfer created. The recipient must accept it before it expires.",
+		"transfer": transfer.ToResponse(userID),
 	})
 }
 
@@ -941,7 +1039,7 @@ func (h *DomainHandler) ListAllDomains(c *gin.Context) {
 	query := h.db.Model(&models.Domain{})
 
 	// 状态筛选
-	if status != "" && (status == "active" || status == "expired" || status == "suspended") {
+	if isFilterableDomainStatus(status) {
 		query = query.Where("status = ?", status)
 	}
 
@@ -965,7 +1063,7 @@ func (h *DomainHandler) ListAllDomains(c *gin.Context) {
 	domainQuery := h.db.Preload("RootDomain").Preload("User")
 	
 	// 状态筛选
-	if status != "" && (status == "active" || status == "expired" || status == "suspended") {
+	if isFilterableDomainStatus(status) {
 		domainQuery = domainQuery.Where("status = ?", status)
 	}
 	
@@ -1018,9 +1116,12 @@ func (h *DomainHandler) GetDomainStatusStats(c *gin.Context) {
 
 	// 构建统计结果，确保所有状态都有值
 	statusMap := map[string]int64{
-		"active":    0,
-		"expired":   0,
-		"suspended": 0,
+		"active":                         0,
+		"expired":                        0,
+		models.DomainStatusGrace:         0,
+		models.DomainStatusRedemption:    0,
+		models.DomainStatusPendingDelete: 0,
+		"suspended":                      0,
 	}
 
 	for _, stat := range stats {
@@ -1034,13 +1135,25 @@ func (h *DomainHandler) GetDomainStatusStats(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total":     total,
-		"active":    statusMap["active"],
-		"expired":   statusMap["expired"],
-		"suspended": statusMap["suspended"],
+		"total":          total,
+		"active":         statusMap["active"],
+		"expired":        statusMap["expired"],
+		"grace":          statusMap[models.DomainStatusGrace],
+		"redemption":     statusMap[models.DomainStatusRedemption],
+		"pending_delete": statusMap[models.DomainStatusPendingDelete],
+		"suspended":      statusMap["suspended"],
 	})
 }
 
+// isFilterableDomainStatus 管理员域名列表支持筛选的状态
+func isFilterableDomainStatus(status string) bool {
+	switch status {
+	case "active", "expired", "suspended":
+		return true
+	}
+	return models.IsLifecycleStatus(status)
+}
+
 // isValidSubdomain 验证子域名格式
 func isValidSubdomain(subdomain string) bool {
 	// 长度检查
@@ -1054,22 +1167,6 @@ func isValidSubdomain(subdomain string) bool {
 	return matched
 }
 
-// isBlacklisted 检查是否在黑名单中
-func isBlacklisted(subdomain string) bool {
-	blacklist := []string{
-		"admin", "root", "api", "www", "mail", "smtp", "ftp", "ssh", "dns",
-		"test", "demo", "dev", "stage", "prod", "blog", "forum", "shop",
-		"status", "support", "help", "docs", "cdn", "static", "assets",
-	}
-
-	for _, word := range blacklist {
-		if subdomain == word {
-			return true
-		}
-	}
-	return false
-}
-
 // Admin Root Domain Management
 
 // ListAllRootDomains 管理员：获取所有根域名列表
@@ -1086,17 +1183,20 @@ func (h *DomainHandler) ListAllRootDomains(c *gin.Context) {
 // CreateRootDomain 管理员：创建根域名
 func (h *DomainHandler) CreateRootDomain(c *gin.Context) {
 	var req struct {
-		Domain                string   `json:"domain" binding:"required"`
-		Description           *string  `json:"description"`
-		Priority              int      `json:"priority"`
-		IsActive              bool     `json:"is_active"`
-		IsHot                 bool     `json:"is_hot"`
-		IsNew                 bool     `json:"is_new"`
-		IsFree                bool     `json:"is_free"`
-		PricePerYear          *float64 `json:"price_per_year"`
-		LifetimePrice         *float64 `json:"lifetime_price"`
-		UseDefaultNameservers bool     `json:"use_default_nameservers"`
-		Nameservers           []string `json:"nameservers"`
+		Domain                string                  `json:"domain" binding:"required"`
+		Description           *string                 `json:"description"`
+		Priority              int                     `json:"priority"`
+		IsActive              bool                    `json:"is_active"`
+		IsHot                 bool                    `json:"is_hot"`
+		IsNew                 bool                    `json:"is_new"`
+		IsFree                bool                    `json:"is_free"`
+		PricePerYear          *float64                `json:"price_per_year"`
+		LifetimePrice         *float64                `json:"lifetime_price"`
+		UseDefaultNameservers bool                    `json:"use_default_nameservers"`
+		Nameservers           []string                `json:"nameservers"`
+		DNSPolicy             *models.DNSPolicy       `json:"dns_policy"`
+		LuaRecordsEnabled     bool                    `json:"lua_records_enabled"`
+		Lifecycle             *models.DomainLifecycle `json:"lifecycle"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1104,6 +1204,24 @@ func (h *DomainHandler) CreateRootDomain(c *gin.Context) {
 		return
 	}
 
+	dnsPolicy := models.DefaultDNSPolicy()
+	if req.DNSPolicy != nil {
+		if err := validateDNSPolicy(req.DNSPolicy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		dnsPolicy = *req.DNSPolicy
+	}
+
+	lifecycle := models.DefaultDomainLifecycle()
+	if req.Lifecycle != nil {
+		if err := validateDomainLifecycle(req.Lifecycle); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		lifecycle = *req.Lifecycle
+	}
+
 	// 检查域名是否已存在
 	var existing models.RootDomain
 	if err := h.db.Where("domain = ?", req.Domain).First(&existing).Error; err == nil {
@@ -1148,6 +1266,9 @@ func (h *DomainHandler) CreateRootDomain(c *gin.Context) {
 		LifetimePrice:         req.LifetimePrice,
 		UseDefaultNameservers: req.UseDefaultNameservers,
 		Nameservers:           nameserversJSON,
+		DNSPolicy:             dnsPolicy,
+		LuaRecordsEnabled:     req.LuaRecordsEnabled,
+		Lifecycle:             lifecycle,
 	}
 
 	if err := h.db.Create(rootDomain).Error; err != nil {
@@ -1180,16 +1301,19 @@ func (h *DomainHandler) UpdateRootDomain(c *gin.Context) {
 	}
 
 	var req struct {
-		Description           *string  `json:"description"`
-		Priority              *int     `json:"priority"`
-		IsActive              *bool    `json:"is_active"`
-		IsHot                 *bool    `json:"is_hot"`
-		IsNew                 *bool    `json:"is_new"`
-		IsFree                *bool    `json:"is_free"`
-		PricePerYear          *float64 `json:"price_per_year"`
-		LifetimePrice         *float64 `json:"lifetime_price"`
-		UseDefaultNameservers *bool    `json:"use_default_nameservers"`
-		Nameservers           []string `json:"nameservers"`
+		Description           *string         `json:"description"`
+		Priority              *int            `json:"priority"`
+		IsActive              *bool           `json:"is_active"`
+		IsHot                 *bool           `json:"is_hot"`
+		IsNew                 *bool           `json:"is_new"`
+		IsFree                *bool           `json:"is_free"`
+		PricePerYear          *float64        `json:"price_per_year"`
+		LifetimePrice         *float64        `json:"lifetime_price"`
+		UseDefaultNameservers *bool           `json:"use_default_nameservers"`
+		Nameservers           []string        `json:"nameservers"`
+		DNSPolicy             json.RawMessage `json:"dns_policy"` // 只需提供要修改的策略字段
+		LuaRecordsEnabled     *bool           `json:"lua_records_enabled"`
+		Lifecycle             json.RawMessage `json:"lifecycle"` // 只需提供要修改的字段
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1261,6 +1385,47 @@ func (h *DomainHandler) UpdateRootDomain(c *gin.Context) {
 		}
 	}
 
+	if len(req.DNSPolicy) > 0 && string(req.DNSPolicy) != "null" {
+		policy := rootDomain.DNSPolicy
+		if err := json.Unmarshal(req.DNSPolicy, &policy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid dns_policy: %v", err)})
+			return
+		}
+		if err := validateDNSPolicy(&policy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		allowedTypes, _ := json.Marshal(policy.AllowedTypes)
+		apexOnlyTypes, _ := json.Marshal(policy.ApexOnlyTypes)
+		updates["dns_allowed_types"] = string(allowedTypes)
+		updates["dns_min_ttl"] = policy.MinTTL
+		updates["dns_max_ttl"] = policy.MaxTTL
+		updates["dns_max_records"] = policy.MaxRecords
+		updates["dns_allow_wildcard"] = policy.AllowWildcard
+		updates["dns_apex_only_types"] = string(apexOnlyTypes)
+		selectFields = append(selectFields, "dns_allowed_types", "dns_min_ttl", "dns_max_ttl", "dns_max_records", "dns_allow_wildcard", "dns_apex_only_types")
+	}
+	if req.LuaRecordsEnabled != nil {
+		updates["lua_records_enabled"] = *req.LuaRecordsEnabled
+		selectFields = append(selectFields, "lua_records_enabled")
+	}
+	if len(req.Lifecycle) > 0 && string(req.Lifecycle) != "null" {
+		lifecycle := rootDomain.Lifecycle
+		if err := json.Unmarshal(req.Lifecycle, &lifecycle); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid lifecycle: %v", err)})
+			return
+		}
+		if err := validateDomainLifecycle(&lifecycle); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["grace_period_days"] = lifecycle.GraceDays
+		updates["redemption_period_days"] = lifecycle.RedemptionDays
+		updates["pending_delete_days"] = lifecycle.PendingDeleteDays
+		updates["redemption_fee"] = roundMoney(lifecycle.RedemptionFee)
+		selectFields = append(selectFields, "grace_period_days", "redemption_period_days", "pending_delete_days", "redemption_fee")
+	}
+
 	if err := h.db.Model(&rootDomain).Select(selectFields).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update root domain"})
 		return
@@ -1389,6 +1554,11 @@ func (h *DomainHandler) AdminUpdateDomainStatus(c *gin.Context) {
 
 // deleteAllDNSRecordsForDomain 删除域名的所有 DNS 记录（从数据库和 PowerDNS）
 func (h *DomainHandler) deleteAllDNSRecordsForDomain(domain *models.Domain) error {
+	// 删除在根域名 zone 中发布的 DS 记录，否则重新注册该名称的用户会继承旧的 DS
+	if err := h.removeDSRecords(domain); err != nil {
+		fmt.Printf("Warning: Failed to delete DS records for domain %s: %v\n", domain.FullDomain, err)
+	}
+
 	// 查询所有 DNS 记录
 	var records []models.DNSRecord
 	if err := h.db.Where("domain_id = ?", domain.ID).Find(&records).Error; err != nil {
@@ -1460,65 +1630,6 @@ func (h *DomainHandler) AdminDeleteDomain(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Domain deleted successfully"})
 }
 
-// CleanupExpiredDomains 自动清理过期域名
-// 删除过期超过指定天数的域名及其 DNS 记录
-func (h *DomainHandler) CleanupExpiredDomains(daysAfterExpiry int) {
-	if daysAfterExpiry <= 0 {
-		daysAfterExpiry = 30 // 默认过期 30 天后删除
-	}
-
-	cutoffTime := timeutil.Now().AddDate(0, 0, -daysAfterExpiry)
-
-	fmt.Printf("Starting cleanup of domains expired before %s...\n", cutoffTime.Format("2006-01-02 15:04:05"))
-
-	// 查找过期超过指定天数的域名
-	var expiredDomains []models.Domain
-	if err := h.db.Preload("RootDomain").
-		Where("expires_at < ? AND status != ?", cutoffTime, "suspended").
-		Find(&expiredDomains).Error; err != nil {
-		fmt.Printf("Error querying expired domains: %v\n", err)
-		return
-	}
-
-	if len(expiredDomains) == 0 {
-		fmt.Println("No expired domains to clean up.")
-		return
-	}
-
-	fmt.Printf("Found %d expired domains to clean up.\n", len(expiredDomains))
-
-	successCount := 0
-	failCount := 0
-
-	for _, domain := range expiredDomains {
-		fmt.Printf("Cleaning up domain: %s (expired at %s)\n",
-			domain.FullDomain, domain.ExpiresAt.Format("2006-01-02"))
-
-		// 删除所有 DNS 记录
-		if err := h.deleteAllDNSRecordsForDomain(&domain); err != nil {
-			fmt.Printf("Warning: Failed to delete DNS records for domain %s: %v\n", domain.FullDomain, err)
-			// 继续删除域名，即使 DNS 清理失败
-		}
-
-		// 软删除域名
-		if err := h.db.Delete(&domain).Error; err != nil {
-			fmt.Printf("Error: Failed to delete domain %s: %v\n", domain.FullDomain, err)
-			failCount++
-			continue
-		}
-
-		// 减少根域名注册计数
-		if domain.RootDomainID > 0 {
-			h.db.Model(&models.RootDomain{}).Where("id = ?", domain.RootDomainID).
-				UpdateColumn("registration_count", gorm.Expr("GREATEST(registration_count - 1, 0)"))
-		}
-
-		successCount++
-	}
-
-	fmt.Printf("Cleanup completed: %d domains deleted, %d failed.\n", successCount, failCount)
-}
-
 // updateDomainNSRecordsInPowerDNS 更新域名在 PowerDNS root zone 中的 NS 记录
 // 当用户使用自定义 nameservers 时，需要在 root domain 的 zone 中添加该子域名的 NS 记录
 // 当用户切换回默认 NS 时，删除这些自定义 NS 记录，并为该子域名创建独立的 zone
@@ -1539,6 +1650,9 @@ func (h *DomainHandler) updateDomainNSRecordsInPowerDNS(domain *models.Domain, n
 		} else {
 			fmt.Printf("Deleted custom NS records for %s (using default NS)\n", subdomainFQDN)
 		}
+		if err := h.pdns.DeleteRRset(rootDomain, subdomainFQDN, "DS"); err != nil {
+			fmt.Printf("Warning: Failed to delete DS records for %s in PowerDNS: %v\n", subdomainFQDN, err)
+		}
 
 		// 2. 为子域名创建独立的 zone
 		defaultNS := []string{h.cfg.DNS.DefaultNS1, h.cfg.DNS.DefaultNS2}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+	"opendomain/internal/services"
+	"opendomain/pkg/timeutil"
+)
+
+// errAutoRenewSkipped 续费期间域名被修改（已续费、关闭自动续费或删除），本次不处理
+var errAutoRenewSkipped = errors.New("domain changed during auto-renewal")
+
+// UpdateDomainSettings 修改域名设置（目前只有自动续费开关）
+// 开启时重置失败计数，付费域名会返回续费价格和当前余额
+func (h *DomainHandler) UpdateDomainSettings(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").Preload("User").First(&domain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return
+	}
+
+	// 验证所有权
+	if domain.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if domain.Status == "suspended" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
+		return
+	}
+
+	var req models.DomainSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.Model(&domain).Updates(map[string]interface{}{
+		"auto_renew":          *req.AutoRenew,
+		"auto_renew_failures": 0,
+		"auto_renew_error":    nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain settings"})
+		return
+	}
+	domain.AutoRenewError = nil
+
+	resp := gin.H{
+		"message": "Domain settings updated successfully",
+		"domain":  domain.ToResponse(),
+	}
+	if *req.AutoRenew && domain.RootDomain != nil && !domain.RootDomain.IsFree {
+		price, err := autoRenewPrice(domain.RootDomain)
+		if err != nil {
+			resp["warning"] = err.Error()
+		} else {
+			resp["renewal_price"] = price
+			resp["balance"] = domain.User.Balance
+			if domain.User.Balance < price {
+				resp["warning"] = "Your balance is lower than the renewal price. Top up before the domain expires."
+			}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RunAutoRenewals 续费开启了自动续费且即将到期（或处于宽限期）的域名，由后台任务定期调用
+// 免费域名直接延长一年；付费域名从账户余额扣款并生成已支付的续费订单。
+// 赎回期需要额外支付赎回费，不自动续费。
+// 失败后按重试间隔重试，每个续费周期最多尝试 AutoRenewMaxAttempts 次
+func (h *DomainHandler) RunAutoRenewals() {
+	days, maxAttempts, retryInterval := h.autoRenewSettings()
+	now := timeutil.Now()
+
+	var domains []models.Domain
+	if err := h.db.Preload("RootDomain").Preload("User").
+		Where("auto_renew = ? AND status IN ? AND expires_at <= ?", true, []string{"active", "expired", models.DomainStatusGrace}, now.AddDate(0, 0, days)).
+		Find(&domains).Error; err != nil {
+		fmt.Printf("Warning: Failed to load domains for auto-renewal: %v\n", err)
+		return
+	}
+
+	renewed, failed := 0, 0
+	for i := range domains {
+		domain := &domains[i]
+		if domain.RootDomain == nil || domain.User == nil {
+			continue
+		}
+
+		failures := currentAutoRenewFailures(domain, days)
+		if failures >= maxAttempts {
+			continue
+		}
+		if failures > 0 && domain.AutoRenewAttemptedAt != nil && now.Sub(*domain.AutoRenewAttemptedAt) < retryInterval {
+			continue
+		}
+
+		switch err := h.autoRenewDomain(domain, now); {
+		case err == nil:
+			renewed++
+		case errors.Is(err, errAutoRenewSkipped):
+		default:
+			failed++
+			h.recordAutoRenewFailure(domain, failures+1, maxAttempts, err, now)
+		}
+	}
+
+	if renewed > 0 || failed > 0 {
+		fmt.Printf("Auto-renewal finished: %d renewed, %d failed\n", renewed, failed)
+	}
+}
+
+// autoRenewDomain 将域名续费一年，付费域名的扣款、订单和到期时间在同一个事务中完成
+func (h *DomainHandler) autoRenewDomain(domain *models.Domain, now time.Time) error {
+	newExpiry := domain.ExpiresAt.AddDate(1, 0, 0)
+	var charged float64
+	var balanceAfter *float64
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if !domain.RootDomain.IsFree {
+			price, err := autoRenewPrice(domain.RootDomain)
+			if err != nil {
+				return err
+			}
+
+			notes := "Auto-renewal paid from account balance"
+			order := &models.Order{
+				OrderNumber:  h.generateOrderNumber(),
+				UserID:       domain.UserID,
+				RootDomainID: domain.RootDomainID,
+				Subdomain:    domain.Subdomain,
+				FullDomain:   domain.FullDomain,
+				DomainID:     &domain.ID,
+				Years:        1,
+				BasePrice:    price,
+				FinalPrice:   price,
+				Status:       "paid",
+				PaidAt:       &now,
+				ExpiresAt:    now,
+				Notes:        &notes,
+			}
+			if err := tx.Create(order).Error; err != nil {
+				return err
+			}
+
+			transaction, err := adjustBalance(tx, domain.UserID, -price, models.BalanceTypeAutoRenew, &order.ID,
+				fmt.Sprintf("Auto-renewal of %s for 1 year", domain.FullDomain))
+			if errors.Is(err, errInsufficientBalance) {
+				return fmt.Errorf("insufficient balance: %.2f required, %.2f available", price, domain.User.Balance)
+			}
+			if err != nil {
+				return err
+			}
+			charged = price
+			balanceAfter = &transaction.BalanceAfter
+		}
+
+		// 只在到期时间未变且仍开启自动续费时更新，避免与手动续费并发时重复续费
+		updates := renewalUpdates(domain, newExpiry)
+		updates["auto_renew_failures"] = 0
+		updates["auto_renew_attempted_at"] = now
+		updates["auto_renew_error"] = nil
+		result := tx.Model(&models.Domain{}).
+			Where("id = ? AND expires_at = ? AND auto_renew = ?", domain.ID, domain.ExpiresAt, true).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAutoRenewSkipped
+		}
+
+		details := fmt.Sprintf("Renewed automatically until %s", newExpiry.UTC().Format("2006-01-02"))
+		if charged > 0 {
+			details += fmt.Sprintf(", charged %.2f from balance", charged)
+		}
+		return tx.Create(&models.DomainHistory{
+			UserID:     domain.UserID,
+			DomainID:   domain.ID,
+			DomainName: domain.FullDomain,
+			Action:     "auto_renewed",
+			Details:    details,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[%s] %s was renewed automatically", h.cfg.SiteName, domain.FullDomain)
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"Your domain %s was renewed automatically.\n\n"+
+		"New expiry date: %s\n",
+		domain.User.Username, domain.FullDomain, newExpiry.UTC().Format("2006-01-02"))
+	if balanceAfter != nil {
+		body += fmt.Sprintf("Amount charged:  %.2f\nBalance:         %.2f\n", charged, *balanceAfter)
+	}
+	h.sendAutoRenewEmail(domain, subject, body)
+	return nil
+}
+
+// recordAutoRenewFailure 记录续费失败并通知用户，最后一次失败时提示需要手动续费
+func (h *DomainHandler) recordAutoRenewFailure(domain *models.Domain, attempt, maxAttempts int, renewErr error, now time.Time) {
+	message := renewErr.Error()
+	if err := h.db.Model(&models.Domain{}).Where("id = ?", domain.ID).Updates(map[string]interface{}{
+		"auto_renew_failures":     attempt,
+		"auto_renew_attempted_at": now,
+		"auto_renew_error":        message,
+	}).Error; err != nil {
+		fmt.Printf("Warning: Failed to record auto-renewal failure for %s: %v\n", domain.FullDomain, err)
+	}
+
+	h.db.Create(&models.DomainHistory{
+		UserID:     domain.UserID,
+		DomainID:   domain.ID,
+		DomainName: domain.FullDomain,
+		Action:     "auto_renew_failed",
+		Details:    fmt.Sprintf("Attempt %d of %d: %s", attempt, maxAttempts, message),
+	})
+
+	next := "We will retry automatically."
+	if attempt >= maxAttempts {
+		next = "No further automatic attempts will be made. Please renew the domain manually before it expires."
+	}
+	subject := fmt.Sprintf("[%s] Automatic renewal of %s failed", h.cfg.SiteName, domain.FullDomain)
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"The automatic renewal of your domain %s failed (attempt %d of %d).\n\n"+
+		"Reason:      %s\n"+
+		"Expires at:  %s\n\n"+
+		"%s\n",
+		domain.User.Username, domain.FullDomain, attempt, maxAttempts, message,
+		domain.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"), next)
+	h.sendAutoRenewEmail(domain, subject, body)
+}
+
+// sendAutoRenewEmail 异步发送自动续费通知
+func (h *DomainHandler) sendAutoRenewEmail(domain *models.Domain, subject, body string) {
+	go func() {
+		if err := services.NewEmailService(h.cfg).Send(domain.User.Email, subject, body); err != nil {
+			fmt.Printf("Warning: Failed to send auto-renewal notification for %s: %v\n", domain.FullDomain, err)
+		}
+	}()
+}
+
+// autoRenewSettings 返回自动续费配置，未配置时使用默认值
+func (h *DomainHandler) autoRenewSettings() (days, maxAttempts int, retryInterval time.Duration) {
+	days, maxAttempts, retryHours := h.cfg.Domain.AutoRenewDays, h.cfg.Domain.AutoRenewMaxAttempts, h.cfg.Domain.AutoRenewRetryHours
+	if days <= 0 {
+		days = 7
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if retryHours <= 0 {
+		retryHours = 12
+	}
+	return days, maxAttempts, time.Duration(retryHours) * time.Hour
+}
+
+// currentAutoRenewFailures 返回本续费周期内的失败次数
+// 上一个周期（手动续费之前）留下的失败记录不计入
+func currentAutoRenewFailures(domain *models.Domain, days int) int {
+	if domain.AutoRenewAttemptedAt == nil || domain.AutoRenewAttemptedAt.Before(domain.ExpiresAt.AddDate(0, 0, -days)) {
+		return 0
+	}
+	return domain.AutoRenewFailures
+}
+
+// autoRenewPrice 返回付费根域名的一年续费价格
+func autoRenewPrice(rootDomain *models.RootDomain) (float64, error) {
+	if rootDomain.PricePerYear == nil {
+		return 0, fmt.Errorf("renewal pricing is not configured for %s", rootDomain.Domain)
+	}
+	return roundMoney(*rootDomain.PricePerYear), nil
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+)
+
+// dnssecAlgorithm 启用 DNSSEC 时创建的密钥算法
+const dnssecAlgorithm = "ECDSAP256SHA256"
+
+// GetRootDomainDNSSEC 管理员：获取根域名的 DNSSEC 状态及 DNSKEY/DS
+func (h *DomainHandler) GetRootDomainDNSSEC(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	keys, err := h.pdns.ListCryptokeys(rootDomain.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch cryptokeys from PowerDNS: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"root_domain_id": rootDomain.ID,
+		"domain":         rootDomain.Domain,
+		"enabled":        rootDomain.DNSSECEnabled,
+		"keys":           keys,
+	})
+}
+
+// EnableRootDomainDNSSEC 管理员：为根域名启用 DNSSEC 签名
+// 没有活跃密钥时创建一个 CSK，返回的 DS 需要提交到上级注册商
+func (h *DomainHandler) EnableRootDomainDNSSEC(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	keys, err := h.pdns.ListCryptokeys(rootDomain.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch cryptokeys from PowerDNS: %v", err)})
+		return
+	}
+
+	hasActiveKey := false
+	for _, key := range keys {
+		if key.Active {
+			hasActiveKey = true
+			break
+		}
+	}
+
+	if !hasActiveKey {
+		if _, err := h.pdns.CreateCryptokey(rootDomain.Domain, "csk", dnssecAlgorithm, 0); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to create cryptokey: %v", err)})
+			return
+		}
+	}
+
+	if err := h.pdns.RectifyZone(rootDomain.Domain); err != nil {
+		fmt.Printf("Warning: Failed to rectify zone %s: %v\n", rootDomain.Domain, err)
+	}
+
+	if err := h.db.Model(&rootDomain).Update("dnssec_enabled", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update root domain"})
+		return
+	}
+
+	keys, err = h.pdns.ListCryptokeys(rootDomain.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch cryptokeys from PowerDNS: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "DNSSEC enabled. Submit the DS records to the parent zone registrar.",
+		"enabled": true,
+		"keys":    keys,
+	})
+}
+
+// DisableRootDomainDNSSEC 管理员：关闭根域名的 DNSSEC 签名并删除所有密钥
+// 调用前应先从上级注册商移除 DS 记录，否则解析会失败
+func (h *DomainHandler) DisableRootDomainDNSSEC(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	keys, err := h.pdns.ListCryptokeys(rootDomain.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch cryptokeys from PowerDNS: %v", err)})
+		return
+	}
+
+	for _, key := range keys {
+		if err := h.pdns.DeleteCryptokey(rootDomain.Domain, key.ID); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to delete cryptokey %d: %v", key.ID, err)})
+			return
+		}
+	}
+
+	if err := h.pdns.RectifyZone(rootDomain.Domain); err != nil {
+		fmt.Printf("Warning: Failed to rectify zone %s: %v\n", rootDomain.Domain, err)
+	}
+
+	if err := h.db.Model(&rootDomain).Update("dnssec_enabled", false).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update root domain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "DNSSEC disabled",
+		"enabled": false,
+	})
+}
+
+// ListDSRecords 获取域名在根域名 zone 中发布的 DS 记录
+func (h *DomainHandler) ListDSRecords(c *gin.Context) {
+	domain, ok := h.loadDelegatedDomain(c)
+	if !ok {
+		return
+	}
+
+	var records []models.DomainDSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&records).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch DS records"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ds_records":    records,
+		"parent_signed": domain.RootDomain.DNSSECEnabled,
+	})
+}
+
+// CreateDSRecord 为使用自定义 NS 的域名提交 DS 记录
+func (h *DomainHandler) CreateDSRecord(c *gin.Context) {
+	domain, ok := h.loadDelegatedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req models.DomainDSRecordCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateDSRecord(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.DomainDSRecord{}).Where("domain_id = ?", domain.ID).Count(&count)
+	if count >= 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A domain can have at most 8 DS records"})
+		return
+	}
+
+	record := &models.DomainDSRecord{
+		DomainID:   domain.ID,
+		KeyTag:     req.KeyTag,
+		Algorithm:  req.Algorithm,
+		DigestType: req.DigestType,
+		Digest:     strings.ToUpper(req.Digest),
+	}
+	if err := h.db.Create(record).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "DS record already exists"})
+		return
+	}
+
+	if err := h.publishDSRecords(domain); err != nil {
+		h.db.Delete(record)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to publish DS record: %v", err)})
+		return
+	}
+
+	response := gin.H{
+		"message":   "DS record published successfully",
+		"ds_record": record,
+	}
+	if !domain.RootDomain.DNSSECEnabled {
+		response["warning"] = "DNSSEC is not enabled for the parent zone, the DS record has no effect until it is"
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDSRecord 删除域名的 DS 记录
+func (h *DomainHandler) DeleteDSRecord(c *gin.Context) {
+	domain, ok := h.loadDelegatedDomain(c)
+	if !ok {
+		return
+	}
+
+	var record models.DomainDSRecord
+	if err := h.db.Where("id = ? AND domain_id = ?", c.Param("dsId"), domain.ID).First(&record).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "DS record not found"})
+		return
+	}
+
+	if err := h.db.Delete(&record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete DS record"})
+		return
+	}
+
+	if err := h.publishDSRecords(domain); err != nil {
+		h.db.Create(&record)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to remove DS record: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "DS record deleted successfully"})
+}
+
+// loadDelegatedDomain 加载当前用户使用自定义 NS 的域名，失败时直接写入响应并返回 false
+func (h *DomainHandler) loadDelegatedDomain(c *gin.Context) (*models.Domain, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").First(&domain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return nil, false
+	}
+
+	if domain.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return nil, false
+	}
+
+	if domain.Status == "suspended" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
+		return nil, false
+	}
+
+	if models.DNSDisabledStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has expired and its DNS is disabled. Renew it to manage DNS records."})
+		return nil, false
+	}
+
+	if domain.UseDefaultNameservers || domain.RootDomain == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "DS records are only available for domains using custom nameservers"})
+		return nil, false
+	}
+
+	return &domain, true
+}
+
+// publishDSRecords 将域名的所有 DS 记录写入根域名 zone，没有记录时删除 DS 记录集
+func (h *DomainHandler) publishDSRecords(domain *models.Domain) error {
+	var records []models.DomainDSRecord
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("id ASC").Find(&records).Error; err != nil {
+		return err
+	}
+
+	entries := make([]powerdns.RecordEntry, 0, len(records))
+	for i := range records {
+		entries = append(entries, powerdns.RecordEntry{Content: records[i].Content()})
+	}
+
+	if err := h.pdns.SetRecords(domain.RootDomain.Domain, domain.FullDomain, "DS", entries, 3600); err != nil {
+		return err
+	}
+
+	if domain.RootDomain.DNSSECEnabled {
+		if err := h.pdns.RectifyZone(domain.RootDomain.Domain); err != nil {
+			fmt.Printf("Warning: Failed to rectify zone %s: %v\n", domain.RootDomain.Domain, err)
+		}
+	}
+	return nil
+}
+
+// removeDSRecords 删除域名的 DS 记录，并删除其在根域名 zone 中发布的 DS 记录集
+func (h *DomainHandler) removeDSRecords(domain *models.Domain) error {
+	result := h.db.Where("domain_id = ?", domain.ID).Delete(&models.DomainDSRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || domain.RootDomain == nil {
+		return nil
+	}
+
+	if err := h.pdns.DeleteRRset(domain.RootDomain.Domain, domain.FullDomain, "DS"); err != nil {
+		return err
+	}
+	if domain.RootDomain.DNSSECEnabled {
+		if err := h.pdns.RectifyZone(domain.RootDomain.Domain); err != nil {
+			fmt.Printf("Warning: Failed to rectify zone %s: %v\n", domain.RootDomain.Domain, err)
+		}
+	}
+	return nil
+}
+
+// validateDSRecord 校验 DS 记录的算法、摘要类型和摘要长度
+func validateDSRecord(req *models.DomainDSRecordCreateRequest) error {
+	switch req.Algorithm {
+	case 5, 7, 8, 10, 13, 14, 15, 16:
+	default:
+		return fmt.Errorf("unsupported DNSSEC algorithm %d", req.Algorithm)
+	}
+
+	digestLengths := map[int]int{1: 40, 2: 64, 4: 96}
+	length, ok := digestLengths[req.DigestType]
+	if !ok {
+		return fmt.Errorf("unsupported digest type %d", req.DigestType)
+	}
+
+	digest := strings.TrimSpace(req.Digest)
+	if len(digest) != length {
+		return fmt.Errorf("digest for type %d must be %d hex characters", req.DigestType, length)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return fmt.Errorf("digest must be hexadecimal")
+	}
+	req.Digest = digest
+	return nil
+}
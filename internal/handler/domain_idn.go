@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+	"opendomain/pkg/idn"
+)
+
+// normalizeRequestSubdomain 将请求中的子域名（可以是 Unicode）替换为 punycode 形式，并按根域名限制检查长度
+// 返回用于显示的 Unicode 形式；名称无效时写入错误响应并返回 false
+func normalizeRequestSubdomain(c *gin.Context, subdomain *string, rootDomain *models.RootDomain) (string, bool) {
+	ascii, display, err := idn.NormalizeLabel(*subdomain)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": idnErrorMessage(c, err), "available": false})
+		return "", false
+	}
+
+	// 长度按用户看到的字符数计算
+	if length := utf8.RuneCountInString(display); length < rootDomain.MinLength || length > rootDomain.MaxLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Subdomain length must be between %d and %d characters",
+				rootDomain.MinLength, rootDomain.MaxLength),
+			"available": false,
+		})
+		return "", false
+	}
+
+	*subdomain = ascii
+	return display, true
+}
+
+// idnErrorMessage 名称转换失败时返回给用户的错误信息
+func idnErrorMessage(c *gin.Context, err error) string {
+	if errors.Is(err, idn.ErrMixedScript) || errors.Is(err, idn.ErrConfusable) {
+		return fmt.Sprintf("Subdomain is not allowed: %v", err)
+	}
+	return middleware.T(c, "error.domain_invalid")
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
+	"opendomain/pkg/timeutil"
+)
+
+// ProcessDomainLifecycle 推进过期域名的生命周期，由后台任务定期调用
+// 宽限期内解析正常；进入赎回期后停用解析；待删除期满后删除 DNS 记录并释放域名。
+// 被暂停的域名不参与，解除暂停后再按到期时间处理
+func (h *DomainHandler) ProcessDomainLifecycle() {
+	now := timeutil.Now()
+
+	var domains []models.Domain
+	if err := h.db.Preload("RootDomain").
+		Where("status IN ? OR (status IN ? AND expires_at <= ?)",
+			[]string{models.DomainStatusGrace, models.DomainStatusRedemption, models.DomainStatusPendingDelete},
+			[]string{models.DomainStatusActive, "expired"}, now).
+		Find(&domains).Error; err != nil {
+		fmt.Printf("Error querying expired domains: %v\n", err)
+		return
+	}
+
+	changed, released := 0, 0
+	for i := range domains {
+		domain := &domains[i]
+		if domain.RootDomain == nil {
+			continue
+		}
+
+		stage := domain.RootDomain.Lifecycle.StageAt(domain.ExpiresAt, now)
+		if stage == domain.Status {
+			continue
+		}
+
+		if stage == models.DomainStatusReleased {
+			if err := h.releaseDomain(domain); err != nil {
+				fmt.Printf("Error: Failed to release domain %s: %v\n", domain.FullDomain, err)
+				continue
+			}
+			released++
+			continue
+		}
+
+		if err := h.transitionDomain(domain, stage); err != nil {
+			fmt.Printf("Warning: Failed to move domain %s to %s: %v\n", domain.FullDomain, stage, err)
+			continue
+		}
+		changed++
+	}
+
+	if changed > 0 || released > 0 {
+		fmt.Printf("Domain lifecycle processed: %d status changes, %d domains released\n", changed, released)
+	}
+}
+
+// transitionDomain 将域名切换到新的生命周期状态，并按需停用或恢复解析
+func (h *DomainHandler) transitionDomain(domain *models.Domain, stage string) error {
+	result := h.db.Model(&models.Domain{}).
+		Where("id = ? AND status = ? AND expires_at = ?", domain.ID, domain.Status, domain.ExpiresAt).
+		Update("status", stage)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil // 期间已续费或被修改
+	}
+
+	previous := domain.Status
+	domain.Status = stage
+	if disabled := models.DNSDisabledStatus(stage); disabled != models.DNSDisabledStatus(previous) {
+		if err := h.pdns.SetSubdomainDisabled(domain.RootDomain.Domain, domain.FullDomain, disabled); err != nil {
+			fmt.Printf("Warning: Failed to %s DNS records in PowerDNS for %s: %v\n",
+				map[bool]string{true: "disable", false: "enable"}[disabled], domain.FullDomain, err)
+		}
+	}
+
+	h.db.Create(&models.DomainHistory{
+		UserID:     domain.UserID,
+		DomainID:   domain.ID,
+		DomainName: domain.FullDomain,
+		Action:     stage,
+		Details:    fmt.Sprintf("Status changed from %s to %s", previous, stage),
+	})
+	return nil
+}
+
+// releaseDomain 待删除期满：软删除域名、取消未完成的转移并删除 DNS 记录，名称可重新注册
+func (h *DomainHandler) releaseDomain(domain *models.Domain) error {
+	fmt.Printf("Releasing domain: %s (expired at %s)\n", domain.FullDomain, domain.ExpiresAt.Format("2006-01-02"))
+
+	released := false
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// 只在到期时间未变时删除，避免与续费并发
+		result := tx.Where("id = ? AND expires_at = ?", domain.ID, domain.ExpiresAt).Delete(&models.Domain{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		released = true
+
+		if err := tx.Model(&models.DomainTransfer{}).
+			Where("domain_id = ? AND status = ?", domain.ID, models.TransferStatusPending).
+			Update("status", models.TransferStatusCancelled).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.RootDomain{}).Where("id = ?", domain.RootDomainID).
+			UpdateColumn("registration_count", gorm.Expr("GREATEST(registration_count - 1, 0)")).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.DomainHistory{
+			UserID:     domain.UserID,
+			DomainID:   domain.ID,
+			DomainName: domain.FullDomain,
+			Action:     models.DomainStatusReleased,
+			Details:    "Released after the pending-delete period ended",
+		}).Error
+	})
+	if err != nil || !released {
+		return err
+	}
+
+	if err := h.deleteAllDNSRecordsForDomain(domain); err != nil {
+		fmt.Printf("Warning: Failed to delete DNS records for domain %s: %v\n", domain.FullDomain, err)
+	}
+	return nil
+}
+
+// renewalUpdates 续费时写入的字段：新的到期时间、重置到期提醒标记，
+// 处于宽限期或赎回期的域名恢复为 active
+func renewalUpdates(domain *models.Domain, newExpiry time.Time) map[string]interface{} {
+	updates := map[string]interface{}{
+		"expires_at":        newExpiry,
+		"reminder_sent_30d": false,
+		"reminder_sent_7d":  false,
+	}
+	if models.IsLifecycleStatus(domain.Status) || domain.Status == "expired" {
+		updates["status"] = models.DomainStatusActive
+	}
+	return updates
+}
+
+// restoreRenewedDNS 赎回期续费后恢复解析
+func restoreRenewedDNS(provider dnsprovider.Provider, domain *models.Domain, previousStatus string) {
+	if !models.DNSDisabledStatus(previousStatus) || domain.RootDomain == nil {
+		return
+	}
+	go func() {
+		if err := provider.SetSubdomainDisabled(domain.RootDomain.Domain, domain.FullDomain, false); err != nil {
+			fmt.Printf("Warning: Failed to enable DNS records in PowerDNS for %s: %v\n", domain.FullDomain, err)
+		}
+	}()
+}
+
+// renewalBlockedReason 返回域名当前不能续费的原因，可以续费时返回空字符串
+func renewalBlockedReason(domain *models.Domain) string {
+	switch domain.Status {
+	case "suspended":
+		return "This domain has been suspended. All operations are disabled."
+	case models.DomainStatusPendingDelete:
+		return "This domain is pending deletion and can no longer be renewed"
+	}
+	return ""
+}
+
+// redemptionFee 返回当前续费需额外支付的赎回费
+func redemptionFee(domain *models.Domain) float64 {
+	if domain.Status != models.DomainStatusRedemption || domain.RootDomain == nil {
+		return 0
+	}
+	return roundMoney(domain.RootDomain.Lifecycle.RedemptionFee)
+}
+
+// validateDomainLifecycle 校验管理员提交的生命周期设置
+func validateDomainLifecycle(lifecycle *models.DomainLifecycle) error {
+	if lifecycle.GraceDays < 0 || lifecycle.GraceDays > 180 {
+		return fmt.Errorf("grace_days must be between 0 and 180")
+	}
+	if lifecycle.RedemptionDays < 0 || lifecycle.RedemptionDays > 180 {
+		return fmt.Errorf("redemption_days must be between 0 and 180")
+	}
+	if lifecycle.PendingDeleteDays < 0 || lifecycle.PendingDeleteDays > 30 {
+		return fmt.Errorf("pending_delete_days must be between 0 and 30")
+	}
+	if lifecycle.RedemptionFee < 0 {
+		return fmt.Errorf("redemption_fee must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/i18n"
+	"opendomain/internal/models"
+	"opendomain/internal/services"
+	"opendomain/pkg/timeutil"
+)
+
+// SendExpiryReminders 向即将到期域名的所有者发送提醒邮件，由后台任务定期调用
+// 第一次提醒和第二次提醒分别使用 reminder_sent_30d / reminder_sent_7d 标记，续费后重置。
+// 任务启动较晚时只发送更近的一次提醒，避免同时收到两封
+func (h *DomainHandler) SendExpiryReminders() {
+	emailService := services.NewEmailService(h.cfg)
+	if !emailService.Enabled() {
+		return
+	}
+
+	first, second := h.reminderSettings()
+	now := timeutil.Now()
+	sent := 0
+
+	// 第二次提醒，同时标记第一次提醒，避免之后再补发
+	var domains []models.Domain
+	if err := h.db.Preload("RootDomain").Preload("User").
+		Where("status = ? AND reminder_sent_7d = ? AND expires_at > ? AND expires_at <= ?", "active", false, now, now.AddDate(0, 0, second)).
+		Find(&domains).Error; err != nil {
+		fmt.Printf("Warning: Failed to load domains for expiry reminders: %v\n", err)
+		return
+	}
+	for i := range domains {
+		if h.sendExpiryReminder(emailService, &domains[i], now, "reminder_sent_7d", "reminder_sent_30d") {
+			sent++
+		}
+	}
+
+	// 第一次提醒
+	domains = nil
+	if err := h.db.Preload("RootDomain").Preload("User").
+		Where("status = ? AND reminder_sent_30d = ? AND expires_at > ? AND expires_at <= ?", "active", false, now.AddDate(0, 0, second), now.AddDate(0, 0, first)).
+		Find(&domains).Error; err != nil {
+		fmt.Printf("Warning: Failed to load domains for expiry reminders: %v\n", err)
+		return
+	}
+	for i := range domains {
+		if h.sendExpiryReminder(emailService, &domains[i], now, "reminder_sent_30d") {
+			sent++
+		}
+	}
+
+	if sent > 0 {
+		fmt.Printf("Expiry reminders sent: %d\n", sent)
+	}
+}
+
+// sendExpiryReminder 发送单个域名的提醒邮件，发送成功后设置对应标记
+// 标记只在 expires_at 未变化时写入，避免覆盖发送期间发生的续费重置
+func (h *DomainHandler) sendExpiryReminder(emailService *services.EmailService, domain *models.Domain, now time.Time, flags ...string) bool {
+	if domain.User == nil || domain.RootDomain == nil {
+		return false
+	}
+
+	locale := domain.User.Locale
+	if !isSupportedLocale(locale) {
+		locale = "zh-CN"
+	}
+	daysLeft := int(math.Ceil(domain.ExpiresAt.Sub(now).Hours() / 24))
+
+	subject := i18n.T(locale, "email.expiry_reminder.subject", h.cfg.SiteName, domain.FullDomain, daysLeft)
+	body := i18n.T(locale, "email.expiry_reminder.body",
+		domain.User.Username, domain.FullDomain, domain.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"), daysLeft, h.renewalLink(h.renewalToken(domain)))
+	if !domain.RootDomain.IsFree {
+		body += i18n.T(locale, "email.expiry_reminder.paid_note")
+	}
+	if domain.AutoRenew {
+		body += i18n.T(locale, "email.expiry_reminder.auto_renew_note")
+	}
+
+	if err := emailService.Send(domain.User.Email, subject, body); err != nil {
+		fmt.Printf("Warning: Failed to send expiry reminder for %s: %v\n", domain.FullDomain, err)
+		return false
+	}
+
+	updates := make(map[string]interface{}, len(flags))
+	for _, flag := range flags {
+		updates[flag] = true
+	}
+	if err := h.db.Model(&models.Domain{}).
+		Where("id = ? AND expires_at = ?", domain.ID, domain.ExpiresAt).
+		Updates(updates).Error; err != nil {
+		fmt.Printf("Warning: Failed to mark expiry reminder for %s: %v\n", domain.FullDomain, err)
+	}
+	return true
+}
+
+// RenewFromLink 旧版邮件中的续期链接（GET /api/public/domains/renew）
+// 只跳转到前端确认页面，不做任何修改，避免邮件安全扫描和链接预取触发续期
+func (h *DomainHandler) RenewFromLink(c *gin.Context) {
+	c.Redirect(http.StatusFound, h.renewalLink(c.Query("token")))
+}
+
+// ConfirmRenewFromLink 前端确认页面提交续期令牌（无需登录）
+// 免费域名直接续期一年；付费域名返回 payment_required，由前端跳转到续费页面完成支付
+func (h *DomainHandler) ConfirmRenewFromLink(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.renewalResult(c, http.StatusBadRequest, "invalid_link", nil)
+		return
+	}
+
+	domainID, expiresAt, ok := h.parseRenewalToken(req.Token)
+	if !ok {
+		h.renewalResult(c, http.StatusBadRequest, "invalid_link", nil)
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").First(&domain, domainID).Error; err != nil {
+		h.renewalResult(c, http.StatusNotFound, "not_found", nil)
+		return
+	}
+
+	// 到期时间变化说明链接生成后已经续费过
+	if domain.ExpiresAt.Unix() != expiresAt {
+		h.renewalResult(c, http.StatusConflict, "already_renewed", &domain)
+		return
+	}
+	if renewalBlockedReason(&domain) != "" || domain.RootDomain == nil {
+		h.renewalResult(c, http.StatusForbidden, "unavailable", &domain)
+		return
+	}
+	if !domain.RootDomain.IsFree || redemptionFee(&domain) > 0 {
+		h.renewalResult(c, http.StatusPaymentRequired, "payment_required", &domain)
+		return
+	}
+
+	newExpiry := domain.ExpiresAt.AddDate(1, 0, 0)
+	result := h.db.Model(&models.Domain{}).
+		Where("id = ? AND expires_at = ?", domain.ID, domain.ExpiresAt).
+		Updates(renewalUpdates(&domain, newExpiry))
+	if result.Error != nil {
+		fmt.Printf("Warning: Failed to renew %s from reminder link: %v\n", domain.FullDomain, result.Error)
+		h.renewalResult(c, http.StatusInternalServerError, "failed", &domain)
+		return
+	}
+	if result.RowsAffected == 0 {
+		h.renewalResult(c, http.StatusConflict, "already_renewed", &domain)
+		return
+	}
+	restoreRenewedDNS(h.pdns, &domain, domain.Status)
+
+	h.db.Create(&models.DomainHistory{
+		UserID:     domain.UserID,
+		DomainID:   domain.ID,
+		DomainName: domain.FullDomain,
+		Action:     "renewed",
+		Details:    fmt.Sprintf("Renewed from reminder email until %s", newExpiry.UTC().Format("2006-01-02")),
+	})
+
+	domain.ExpiresAt = newExpiry
+	h.renewalResult(c, http.StatusOK, "renewed", &domain)
+}
+
+// renewalResult 返回续期结果，status 供前端显示对应的提示
+func (h *DomainHandler) renewalResult(c *gin.Context, code int, status string, domain *models.Domain) {
+	resp := gin.H{"status": status}
+	if domain != nil {
+		resp["domain"] = domain.FullDomain
+		resp["domain_id"] = domain.ID
+		resp["expires_at"] = domain.ExpiresAt
+	}
+	c.JSON(code, resp)
+}
+
+// renewalLink 前端续期确认页面的地址，页面由用户点击确认后再提交令牌
+func (h *DomainHandler) renewalLink(token string) string {
+	return fmt.Sprintf("%s/renew?token=%s", h.cfg.FrontendURL, url.QueryEscape(token))
+}
+
+// renewalToken 签名绑定域名 ID 和当前到期时间，续费后旧链接自动失效
+func (h *DomainHandler) renewalToken(domain *models.Domain) string {
+	payload := fmt.Sprintf("%d.%d", domain.ID, domain.ExpiresAt.Unix())
+	return payload + "." + h.renewalSignature(payload)
+}
+
+// parseRenewalToken 校验签名并返回域名 ID 和签发时的到期时间
+func (h *DomainHandler) parseRenewalToken(token string) (uint, int64, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(h.renewalSignature(payload))) {
+		return 0, 0, false
+	}
+
+	domainID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint(domainID), expiresAt, true
+}
+
+// renewalSignature 使用 DOMAIN_RENEWAL_LINK_SECRET 签名；未配置时退回到 JWT 密钥，
+// 此时轮换 JWT 密钥会使所有已发出的续期链接失效
+func (h *DomainHandler) renewalSignature(payload string) string {
+	secret := h.cfg.Domain.RenewalLinkSecret
+	if secret == "" {
+		secret = h.cfg.JWT.Secret
+	}
+	mac := hmac.New(sha256.New, []byte("renewal-link:"+secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// reminderSettings 返回两次提醒的提前天数，未配置时使用默认值
+func (h *DomainHandler) reminderSettings() (first, second int) {
+	first, second = h.cfg.Domain.ReminderFirstDays, h.cfg.Domain.ReminderSecondDays
+	if first <= 0 {
+		first = 30
+	}
+	if second <= 0 {
+		second = 7
+	}
+	if second > first {
+		first, second = second, first
+	}
+	return first, second
+}
@@ -0,0 +1,383 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+)
+
+// secondaryMetadataKinds 由二级 DNS 配置生成的 zone 元数据，保存时整体覆盖
+var secondaryMetadataKinds = []string{
+	powerdns.MetadataAllowAXFRFrom,
+	powerdns.MetadataAlsoNotify,
+	powerdns.MetadataTSIGAllowAXFR,
+}
+
+// defaultTSIGAlgorithm 未指定算法时创建的 TSIG 密钥算法
+const defaultTSIGAlgorithm = "hmac-sha256"
+
+// GetRootDomainSecondaries 管理员：获取根域名的二级 DNS 服务器、当前生效的 zone 元数据和 NOTIFY 状态
+func (h *DomainHandler) GetRootDomainSecondaries(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	var secondaries []models.RootDomainSecondary
+	if err := h.db.Where("root_domain_id = ?", rootDomain.ID).Order("id ASC").Find(&secondaries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch secondary servers"})
+		return
+	}
+
+	zone, err := h.pdns.GetZone(rootDomain.Domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch zone from PowerDNS: %v", err)})
+		return
+	}
+
+	metadata := make(map[string][]string, len(secondaryMetadataKinds))
+	for _, kind := range secondaryMetadataKinds {
+		values, err := h.pdns.GetMetadata(rootDomain.Domain, kind)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch %s metadata from PowerDNS: %v", kind, err)})
+			return
+		}
+		metadata[kind] = values
+	}
+
+	resp := gin.H{
+		"root_domain_id":  rootDomain.ID,
+		"domain":          rootDomain.Domain,
+		"kind":            zone.Kind,
+		"serial":          zone.Serial,
+		"notified_serial": zone.NotifiedSerial,
+		"notify_pending":  zone.NotifiedSerial < zone.Serial,
+		"secondaries":     secondaries,
+		"metadata":        metadata,
+	}
+	if zone.Kind != "Master" {
+		resp["warning"] = fmt.Sprintf("Zone kind is %s; PowerDNS only sends NOTIFY for Master zones", zone.Kind)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddRootDomainSecondary 管理员：为根域名添加二级 DNS 服务器
+func (h *DomainHandler) AddRootDomainSecondary(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	var req models.RootDomainSecondaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secondary := &models.RootDomainSecondary{RootDomainID: rootDomain.ID}
+	if !h.applySecondaryRequest(c, secondary, &req) {
+		return
+	}
+
+	h.saveSecondaries(c, &rootDomain, http.StatusCreated, "Secondary server added", secondary, func(tx *gorm.DB) error {
+		return tx.Create(secondary).Error
+	})
+}
+
+// UpdateRootDomainSecondary 管理员：修改二级 DNS 服务器
+func (h *DomainHandler) UpdateRootDomainSecondary(c *gin.Context) {
+	rootDomain, secondary, ok := h.loadRootDomainSecondary(c)
+	if !ok {
+		return
+	}
+
+	var req models.RootDomainSecondaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.applySecondaryRequest(c, secondary, &req) {
+		return
+	}
+
+	h.saveSecondaries(c, rootDomain, http.StatusOK, "Secondary server updated", secondary, func(tx *gorm.DB) error {
+		return tx.Select("*").Omit("created_at").Save(secondary).Error
+	})
+}
+
+// DeleteRootDomainSecondary 管理员：删除二级 DNS 服务器，同时撤销其 AXFR 权限
+func (h *DomainHandler) DeleteRootDomainSecondary(c *gin.Context) {
+	rootDomain, secondary, ok := h.loadRootDomainSecondary(c)
+	if !ok {
+		return
+	}
+
+	h.saveSecondaries(c, rootDomain, http.StatusOK, "Secondary server deleted", nil, func(tx *gorm.DB) error {
+		return tx.Delete(secondary).Error
+	})
+}
+
+// NotifyRootDomainSecondaries 管理员：立即向二级服务器发送 NOTIFY
+func (h *DomainHandler) NotifyRootDomainSecondaries(c *gin.Context) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return
+	}
+
+	if err := h.pdns.NotifyZone(rootDomain.Domain); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to send NOTIFY: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "NOTIFY queued for secondary servers"})
+}
+
+// ListTSIGKeys 管理员：获取 PowerDNS 上的 TSIG 密钥（不含密钥内容）
+func (h *DomainHandler) ListTSIGKeys(c *gin.Context) {
+	keys, err := h.pdns.ListTSIGKeys()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch TSIG keys from PowerDNS: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// CreateTSIGKey 管理员：创建 TSIG 密钥，密钥内容只在创建时返回一次，需要配置到二级服务器上
+func (h *DomainHandler) CreateTSIGKey(c *gin.Context) {
+	var req models.TSIGKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(req.Name), "."))
+	if name == "" || strings.HasPrefix(name, "*") || !hostnamePattern.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TSIG key name must be a valid DNS name"})
+		return
+	}
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = defaultTSIGAlgorithm
+	}
+
+	key, err := h.pdns.CreateTSIGKey(name, algorithm, req.Key)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to create TSIG key: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "TSIG key created. Store the secret now, it will not be shown again.",
+		"key":     key,
+	})
+}
+
+// DeleteTSIGKey 管理员：删除 TSIG 密钥，仍被二级服务器使用时拒绝删除
+func (h *DomainHandler) DeleteTSIGKey(c *gin.Context) {
+	key, ok := h.findTSIGKey(c, c.Param("name"))
+	if !ok {
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.RootDomainSecondary{}).Where("tsig_key_name = ?", key.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot delete: %d secondary servers are using this TSIG key", count)})
+		return
+	}
+
+	if err := h.pdns.DeleteTSIGKey(key.ID); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to delete TSIG key: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "TSIG key deleted successfully"})
+}
+
+// loadRootDomainSecondary 根据路由参数加载根域名及其二级服务器，失败时已写入响应
+func (h *DomainHandler) loadRootDomainSecondary(c *gin.Context) (*models.RootDomain, *models.RootDomainSecondary, bool) {
+	var rootDomain models.RootDomain
+	if err := h.db.First(&rootDomain, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Root domain not found"})
+		return nil, nil, false
+	}
+
+	secondaryID, err := strconv.ParseUint(c.Param("secondaryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid secondary server ID"})
+		return nil, nil, false
+	}
+
+	var secondary models.RootDomainSecondary
+	if err := h.db.Where("id = ? AND root_domain_id = ?", secondaryID, rootDomain.ID).First(&secondary).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Secondary server not found"})
+		return nil, nil, false
+	}
+	return &rootDomain, &secondary, true
+}
+
+// applySecondaryRequest 校验请求并写入 secondary，失败时已写入响应
+func (h *DomainHandler) applySecondaryRequest(c *gin.Context, secondary *models.RootDomainSecondary, req *models.RootDomainSecondaryRequest) bool {
+	ip := net.ParseIP(req.Address)
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Secondary server address must be a routable IP address"})
+		return false
+	}
+
+	secondary.Name = strings.TrimSpace(req.Name)
+	secondary.Address = ip.String()
+	secondary.Port = req.Port
+	if secondary.Port == 0 {
+		secondary.Port = 53
+	}
+	secondary.Notify = req.Notify == nil || *req.Notify
+
+	secondary.TSIGKeyName = ""
+	if keyName := strings.TrimSpace(req.TSIGKeyName); keyName != "" {
+		key, ok := h.findTSIGKey(c, keyName)
+		if !ok {
+			return false
+		}
+		secondary.TSIGKeyName = key.Name
+	}
+
+	var count int64
+	h.db.Model(&models.RootDomainSecondary{}).
+		Where("root_domain_id = ? AND address = ? AND port = ? AND id <> ?", secondary.RootDomainID, secondary.Address, secondary.Port, secondary.ID).
+		Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Secondary server with this address and port already exists"})
+		return false
+	}
+	return true
+}
+
+// findTSIGKey 在 PowerDNS 上按名称查找 TSIG 密钥（忽略大小写和结尾的点），失败时已写入响应
+func (h *DomainHandler) findTSIGKey(c *gin.Context, name string) (*powerdns.TSIGKey, bool) {
+	keys, err := h.pdns.ListTSIGKeys()
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to fetch TSIG keys from PowerDNS: %v", err)})
+		return nil, false
+	}
+
+	want := strings.ToLower(strings.TrimSuffix(name, "."))
+	for i := range keys {
+		if strings.ToLower(strings.TrimSuffix(keys[i].Name, ".")) == want {
+			return &keys[i], true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("TSIG key %s not found", name)})
+	return nil, false
+}
+
+// saveSecondaries 在事务中修改二级服务器，并根据修改后的完整列表重写 zone 元数据
+// PowerDNS 更新失败时回滚数据库，并将已写入的元数据恢复为原值；成功后立即发送 NOTIFY
+func (h *DomainHandler) saveSecondaries(c *gin.Context, rootDomain *models.RootDomain, status int, message string, secondary *models.RootDomainSecondary, mutate func(tx *gorm.DB) error) {
+	var pushErr error
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := mutate(tx); err != nil {
+			return err
+		}
+		var secondaries []models.RootDomainSecondary
+		if err := tx.Where("root_domain_id = ?", rootDomain.ID).Order("id ASC").Find(&secondaries).Error; err != nil {
+			return err
+		}
+		pushErr = h.pushSecondaryMetadata(rootDomain.Domain, secondaries)
+		return pushErr
+	})
+	if pushErr != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to update zone metadata in PowerDNS: %v", pushErr)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secondary server"})
+		return
+	}
+
+	if err := h.pdns.NotifyZone(rootDomain.Domain); err != nil {
+		fmt.Printf("Warning: Failed to send NOTIFY for %s: %v\n", rootDomain.Domain, err)
+	}
+
+	resp := gin.H{"message": message}
+	if secondary != nil {
+		resp["secondary"] = secondary
+	}
+	c.JSON(status, resp)
+}
+
+// pushSecondaryMetadata 根据二级服务器列表重写 zone 的 AXFR/NOTIFY 元数据：
+//   - 配置了 TSIG 密钥的服务器只通过 TSIG-ALLOW-AXFR 授权，不加入 IP 白名单，未签名的 AXFR 会被拒绝
+//   - 未配置密钥的服务器按 IP 加入 ALLOW-AXFR-FROM
+//   - 开启 notify 的服务器加入 ALSO-NOTIFY，非 53 端口时带上端口
+//
+// 各类元数据逐个写入，某一类写入失败时把已写入的类别恢复为原值；
+// 恢复也失败时返回的错误会说明 PowerDNS 中的元数据处于部分更新状态
+func (h *DomainHandler) pushSecondaryMetadata(zone string, secondaries []models.RootDomainSecondary) error {
+	values := make(map[string][]string, len(secondaryMetadataKinds))
+	seen := make(map[string]bool)
+	add := func(kind, value string) {
+		if !seen[kind+"|"+value] {
+			seen[kind+"|"+value] = true
+			values[kind] = append(values[kind], value)
+		}
+	}
+
+	for _, s := range secondaries {
+		if s.TSIGKeyName != "" {
+			add(powerdns.MetadataTSIGAllowAXFR, s.TSIGKeyName)
+		} else {
+			add(powerdns.MetadataAllowAXFRFrom, s.Address)
+		}
+		if s.Notify {
+			target := s.Address
+			if s.Port != 53 {
+				target = net.JoinHostPort(s.Address, strconv.Itoa(s.Port))
+			}
+			add(powerdns.MetadataAlsoNotify, target)
+		}
+	}
+
+	previous := make(map[string][]string, len(secondaryMetadataKinds))
+	for _, kind := range secondaryMetadataKinds {
+		current, err := h.pdns.GetMetadata(zone, kind)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		previous[kind] = current
+	}
+
+	for i, kind := range secondaryMetadataKinds {
+		if err := h.writeZoneMetadata(zone, kind, values[kind]); err != nil {
+			var restoreErrs []string
+			for _, written := range secondaryMetadataKinds[:i] {
+				if restoreErr := h.writeZoneMetadata(zone, written, previous[written]); restoreErr != nil {
+					restoreErrs = append(restoreErrs, fmt.Sprintf("%s: %v", written, restoreErr))
+				}
+			}
+			if len(restoreErrs) > 0 {
+				return fmt.Errorf("%s: %w (metadata is partially updated, failed to restore %s)", kind, err, strings.Join(restoreErrs, "; "))
+			}
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// writeZoneMetadata 写入一类元数据，值为空时删除
+func (h *DomainHandler) writeZoneMetadata(zone, kind string, values []string) error {
+	if len(values) == 0 {
+		return h.pdns.DeleteMetadata(zone, kind)
+	}
+	return h.pdns.SetMetadata(zone, kind, values)
+}
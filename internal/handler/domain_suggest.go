@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+	"opendomain/pkg/idn"
+)
+
+// 生成推荐变体使用的前缀、后缀和数字
+var (
+	suggestionPrefixes = []string{"my", "get", "go", "the", "hi"}
+	suggestionSuffixes = []string{"app", "hub", "hq", "lab", "site", "web", "online"}
+	suggestionNumbers  = []string{"1", "2", "24", "365"}
+)
+
+// suggestionLabel 候选子域名的 punycode 和显示形式
+type suggestionLabel struct {
+	ascii   string
+	display string
+}
+
+// SuggestDomains 按关键字检查所有启用的根域名，并推荐可注册的变体
+// results 为关键字本身在每个根域名下的状态；suggestions 为可注册的变体，同一变体按根域名优先级排列
+// @Summary 域名推荐
+// @Tags Domain
+// @Produce json
+// @Param keyword query string true "关键字"
+// @Param limit query int false "推荐数量（默认 20，最多 50）"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/domains/suggestions [get]
+// @Security Bearer
+func (h *DomainHandler) SuggestDomains(c *gin.Context) {
+	var req models.DomainSuggestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": middleware.T(c, "error.validation")})
+		return
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	keyword, keywordDisplay, err := idn.NormalizeLabel(req.Keyword)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": idnErrorMessage(c, err)})
+		return
+	}
+
+	var rootDomains []models.RootDomain
+	if err := h.db.Where("is_active = ?", true).Order("priority DESC, id ASC").Find(&rootDomains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.T(c, "error.internal_server")})
+		return
+	}
+
+	labels := append([]suggestionLabel{{ascii: keyword, display: keywordDisplay}}, suggestionVariants(keywordDisplay)...)
+
+	// 按根域名的长度限制和格式过滤候选名称，同一名称在各根域名下按优先级排列
+	rootDisplays := make([]string, len(rootDomains))
+	rootDomainIDs := make([]uint, len(rootDomains))
+	for i := range rootDomains {
+		rootDisplays[i] = idn.ToUnicode(rootDomains[i].Domain)
+		rootDomainIDs[i] = rootDomains[i].ID
+	}
+
+	var candidates []models.DomainSuggestion
+	var fullDomains, subdomains []string
+	for _, label := range labels {
+		subdomains = append(subdomains, label.ascii)
+		if !isValidSubdomain(label.ascii) {
+			continue
+		}
+		length := utf8.RuneCountInString(label.display)
+		for i := range rootDomains {
+			root := &rootDomains[i]
+			if length < root.MinLength || length > root.MaxLength {
+				continue
+			}
+			fullDomain := label.ascii + "." + root.Domain
+			candidates = append(candidates, models.DomainSuggestion{
+				Subdomain:         label.ascii,
+				SubdomainUnicode:  label.display,
+				FullDomain:        fullDomain,
+				FullDomainUnicode: label.display + "." + rootDisplays[i],
+				RootDomainID:      root.ID,
+				RootDomain:        root.Domain,
+				IsFree:            root.IsFree,
+				PricePerYear:      root.PricePerYear,
+				LifetimePrice:     root.LifetimePrice,
+			})
+			fullDomains = append(fullDomains, fullDomain)
+		}
+	}
+
+	if len(candidates) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"keyword":         keyword,
+			"keyword_unicode": keywordDisplay,
+			"results":         []models.DomainSuggestion{},
+			"suggestions":     []models.DomainSuggestion{},
+		})
+		return
+	}
+
+	// 已注册和待激活的名称各用一次查询
+	taken := make(map[string]bool)
+	var registered, pending []string
+	if err := h.db.Model(&models.Domain{}).Where("full_domain IN ?", fullDomains).Pluck("full_domain", &registered).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.T(c, "error.internal_server")})
+		return
+	}
+	if err := h.db.Model(&models.PendingDomain{}).Where("full_domain IN ?", fullDomains).Pluck("full_domain", &pending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.T(c, "error.internal_server")})
+		return
+	}
+	for _, name := range append(registered, pending...) {
+		taken[name] = true
+	}
+
+	rules, err := loadReservedNames(h.db, subdomains, rootDomainIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check reserved names"})
+		return
+	}
+
+	results := []models.DomainSuggestion{}
+	suggestions := []models.DomainSuggestion{}
+	for _, candidate := range candidates {
+		candidate.Available = !taken[candidate.FullDomain]
+		if rule := matchReservedName(rules, candidate.Subdomain, candidate.RootDomainID); rule != nil {
+			if rule.IsPremium() {
+				candidate.Premium = true
+				candidate.IsFree = false
+				candidate.PricePerYear = rule.PricePerYear
+				candidate.LifetimePrice = rule.LifetimePrice
+			} else {
+				candidate.Available = false
+				candidate.Reserved = true
+			}
+		}
+
+		if candidate.Subdomain == keyword {
+			results = append(results, candidate)
+		} else if candidate.Available && len(suggestions) < limit {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"keyword":         keyword,
+		"keyword_unicode": keywordDisplay,
+		"results":         results,
+		"suggestions":     suggestions,
+	})
+}
+
+// suggestionVariants 根据关键字生成变体：去掉连字符、加前缀、加后缀（连写和连字符两种）以及加数字
+// 变体使用关键字的显示形式生成，无效或与关键字相同的名称会被丢弃
+func suggestionVariants(keyword string) []suggestionLabel {
+	var raw []string
+	if stripped := strings.ReplaceAll(keyword, "-", ""); stripped != keyword {
+		raw = append(raw, stripped)
+	}
+	for _, suffix := range suggestionSuffixes {
+		raw = append(raw, keyword+suffix)
+	}
+	for _, prefix := range suggestionPrefixes {
+		raw = append(raw, prefix+keyword)
+	}
+	for _, suffix := range suggestionSuffixes {
+		raw = append(raw, keyword+"-"+suffix)
+	}
+	for _, prefix := range suggestionPrefixes {
+		raw = append(raw, prefix+"-"+keyword)
+	}
+	for _, number := range suggestionNumbers {
+		raw = append(raw, keyword+number)
+	}
+
+	seen := map[string]bool{}
+	if ascii, _, err := idn.NormalizeLabel(keyword); err == nil {
+		seen[ascii] = true
+	}
+	var variants []suggestionLabel
+	for _, name := range raw {
+		ascii, display, err := idn.NormalizeLabel(name)
+		if err != nil || seen[ascii] {
+			continue
+		}
+		seen[ascii] = true
+		variants = append(variants, suggestionLabel{ascii: ascii, display: display})
+	}
+	return variants
+}
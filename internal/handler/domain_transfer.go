@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"opendomain/internal/middleware"
+	"opendomain/internal/models"
+	"opendomain/internal/services"
+	"opendomain/pkg/timeutil"
+)
+
+var (
+	// errTransferNotPending 转移已被其他请求处理
+	errTransferNotPending = errors.New("transfer is no longer pending")
+	// errTransferRecipientMissing 接收方账户不存在
+	errTransferRecipientMissing = errors.New("recipient not found")
+	// errTransferQuotaExceeded 接收方的域名数已达到配额
+	errTransferQuotaExceeded = errors.New("domain quota exceeded")
+)
+
+// ListDomainTransfers 获取当前用户发起和收到的域名转移
+// direction=incoming/outgoing 只返回一个方向，status 按状态筛选
+func (h *DomainHandler) ListDomainTransfers(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query := h.db.Preload("Domain").Preload("FromUser").Preload("ToUser")
+	switch c.Query("direction") {
+	case "incoming":
+		query = query.Where("to_user_id = ?", userID)
+	case "outgoing":
+		query = query.Where("from_user_id = ?", userID)
+	default:
+		query = query.Where("from_user_id = ? OR to_user_id = ?", userID, userID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var transfers []models.DomainTransfer
+	if err := query.Order("created_at DESC").Limit(200).Find(&transfers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	responses := make([]*models.DomainTransferResponse, len(transfers))
+	for i := range transfers {
+		responses[i] = transfers[i].ToResponse(userID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfers": responses})
+}
+
+// AcceptDomainTransfer 接收方接受域名转移
+// 接受时检查转移是否过期、发起方是否仍持有域名以及接收方配额
+func (h *DomainHandler) AcceptDomainTransfer(c *gin.Context) {
+	userID, transfer, ok := h.loadPendingTransfer(c, false)
+	if !ok {
+		return
+	}
+
+	if timeutil.Now().After(transfer.ExpiresAt) {
+		h.closeTransfer(transfer, models.TransferStatusExpired, "Transfer expired before it was accepted")
+		c.JSON(http.StatusGone, gin.H{"error": "Transfer has expired"})
+		return
+	}
+
+	domain := transfer.Domain
+	if domain == nil || domain.DeletedAt.Valid || domain.UserID != transfer.FromUserID {
+		h.closeTransfer(transfer, models.TransferStatusCancelled, "Domain is no longer owned by the sender")
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is no longer owned by the sender"})
+		return
+	}
+	if domain.Status == "suspended" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This domain has been suspended. All operations are disabled."})
+		return
+	}
+	if models.IsLifecycleStatus(domain.Status) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Expired domains must be renewed before they can be transferred"})
+		return
+	}
+
+	var recipient models.User
+	now := timeutil.Now()
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定接收方后再统计配额，防止并发接受多个转移超出配额
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&recipient, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTransferRecipientMissing
+			}
+			return err
+		}
+		if activeDomainCount(tx, userID) >= int64(recipient.DomainQuota) {
+			return errTransferQuotaExceeded
+		}
+
+		result := tx.Model(&models.DomainTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, models.TransferStatusPending).
+			Updates(map[string]interface{}{"status": models.TransferStatusAccepted, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTransferNotPending
+		}
+
+		// 只在发起方仍是所有者时变更，防止与删除、管理员操作并发
+		// 自动续费由原所有者开启并从其余额扣款，转移后需要接收方重新开启
+		result = tx.Model(&models.Domain{}).
+			Where("id = ? AND user_id = ?", domain.ID, transfer.FromUserID).
+			Updates(map[string]interface{}{
+				"user_id":             userID,
+				"auto_renew":          false,
+				"auto_renew_failures": 0,
+				"auto_renew_error":    nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTransferNotPending
+		}
+
+		// 原所有者创建的令牌不再有效
+		if err := tx.Where("domain_id = ?", domain.ID).Delete(&models.DomainToken{}).Error; err != nil {
+			return err
+		}
+
+		transfer.Status = models.TransferStatusAccepted
+		transfer.RespondedAt = &now
+		return recordTransferHistory(tx, transfer, "transfer_out", "transfer_in",
+			fmt.Sprintf("Domain transferred to %s", recipient.Username),
+			fmt.Sprintf("Domain received from %s", transferUsername(transfer.FromUser)))
+	})
+	switch {
+	case errors.Is(err, errTransferNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+		return
+	case errors.Is(err, errTransferRecipientMissing):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	case errors.Is(err, errTransferQuotaExceeded):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          "Domain quota exceeded. Free up quota before accepting this transfer.",
+			"quota_exceeded": true,
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept transfer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer accepted. The domain is now in your account.",
+		"transfer": transfer.ToResponse(userID),
+	})
+}
+
+// DeclineDomainTransfer 接收方拒绝域名转移
+func (h *DomainHandler) DeclineDomainTransfer(c *gin.Context) {
+	userID, transfer, ok := h.loadPendingTransfer(c, false)
+	if !ok {
+		return
+	}
+
+	if !h.closeTransfer(transfer, models.TransferStatusDeclined, "Declined by the recipient") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer declined",
+		"transfer": transfer.ToResponse(userID),
+	})
+}
+
+// CancelDomainTransfer 发起方撤销尚未处理的域名转移
+func (h *DomainHandler) CancelDomainTransfer(c *gin.Context) {
+	userID, transfer, ok := h.loadPendingTransfer(c, true)
+	if !ok {
+		return
+	}
+
+	if !h.closeTransfer(transfer, models.TransferStatusCancelled, "Cancelled by the sender") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Transfer cancelled",
+		"transfer": transfer.ToResponse(userID),
+	})
+}
+
+// ListDomainHistory 获取当前用户的域名历史事件
+func (h *DomainHandler) ListDomainHistory(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	query := h.db.Where("user_id = ?", userID)
+	if domainID := c.Query("domain_id"); domainID != "" {
+		query = query.Where("domain_id = ?", domainID)
+	}
+
+	var history []models.DomainHistory
+	if err := query.Order("created_at DESC, id DESC").Limit(200).Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch domain history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// ExpireDomainTransfers 将超过确认期限的转移标记为过期，由后台任务定期调用
+func (h *DomainHandler) ExpireDomainTransfers() {
+	var transfers []models.DomainTransfer
+	if err := h.db.Preload("Domain", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("status = ? AND expires_at < ?", models.TransferStatusPending, timeutil.Now()).
+		Find(&transfers).Error; err != nil {
+		fmt.Printf("Warning: Failed to load expired transfers: %v\n", err)
+		return
+	}
+
+	for i := range transfers {
+		h.closeTransfer(&transfers[i], models.TransferStatusExpired, "Transfer expired before it was accepted")
+	}
+	if len(transfers) > 0 {
+		fmt.Printf("Expired %d pending domain transfers\n", len(transfers))
+	}
+}
+
+// loadPendingTransfer 加载当前用户可处理的待处理转移，失败时已写入响应
+// asSender 为 true 时要求当前用户是发起方，否则要求是接收方
+func (h *DomainHandler) loadPendingTransfer(c *gin.Context, asSender bool) (uint, *models.DomainTransfer, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, nil, false
+	}
+
+	transferID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return 0, nil, false
+	}
+
+	var transfer models.DomainTransfer
+	if err := h.db.Preload("Domain", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("FromUser").Preload("ToUser").First(&transfer, transferID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return 0, nil, false
+	}
+
+	if (asSender && transfer.FromUserID != userID) || (!asSender && transfer.ToUserID != userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return 0, nil, false
+	}
+
+	if transfer.Status != models.TransferStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Transfer is already %s", transfer.Status)})
+		return 0, nil, false
+	}
+	return userID, &transfer, true
+}
+
+// closeTransfer 将待处理的转移结束为 declined/cancelled/expired，并为双方记录历史
+// 转移已被其他请求处理时返回 false
+func (h *DomainHandler) closeTransfer(transfer *models.DomainTransfer, status, details string) bool {
+	now := timeutil.Now()
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.DomainTransfer{}).
+			Where("id = ? AND status = ?", transfer.ID, models.TransferStatusPending).
+			Updates(map[string]interface{}{"status": status, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTransferNotPending
+		}
+
+		transfer.Status = status
+		transfer.RespondedAt = &now
+		action := "transfer_" + status
+		return recordTransferHistory(tx, transfer, action, action, details, details)
+	})
+	if err != nil {
+		if !errors.Is(err, errTransferNotPending) {
+			fmt.Printf("Warning: Failed to mark transfer %d as %s: %v\n", transfer.ID, status, err)
+		}
+		return false
+	}
+	return true
+}
+
+// activeDomainCount 统计用户的活跃域名数，与注册时的配额计算方式一致
+func activeDomainCount(db *gorm.DB, userID uint) int64 {
+	var count int64
+	db.Model(&models.Domain{}).Where("user_id = ? AND status = ?", userID, "active").Count(&count)
+	return count
+}
+
+// transferUsername 返回用户名，用户已删除时返回占位文本
+func transferUsername(user *models.User) string {
+	if user == nil {
+		return "a deleted user"
+	}
+	return user.Username
+}
+
+// recordTransferHistory 为转移的发起方和接收方各写入一条历史
+func recordTransferHistory(tx *gorm.DB, transfer *models.DomainTransfer, senderAction, recipientAction, senderDetails, recipientDetails string) error {
+	domainName := ""
+	if transfer.Domain != nil {
+		domainName = transfer.Domain.FullDomain
+	}
+	history := []models.DomainHistory{
+		{UserID: transfer.FromUserID, DomainID: transfer.DomainID, DomainName: domainName, Action: senderAction, TransferID: &transfer.ID, Details: senderDetails},
+		{UserID: transfer.ToUserID, DomainID: transfer.DomainID, DomainName: domainName, Action: recipientAction, TransferID: &transfer.ID, Details: recipientDetails},
+	}
+	return tx.Create(&history).Error
+}
+
+// notifyTransferRecipient 邮件通知接收方有待确认的转移
+func (h *DomainHandler) notifyTransferRecipient(transfer *models.DomainTransfer) {
+	if transfer.ToUser == nil || transfer.FromUser == nil || transfer.Domain == nil {
+		return
+	}
+
+	subject := fmt.Sprintf("[%s] %s wants to transfer %s to you", h.cfg.SiteName, transfer.FromUser.Username, transfer.Domain.FullDomain)
+	body := fmt.Sprintf("Hello %s,\n\n"+
+		"%s wants to transfer the domain %s to your account.\n\n"+
+		"Message: %s\n"+
+		"Expires: %s\n\n"+
+		"Accept or decline the transfer from your account: %s\n",
+		transfer.ToUser.Username, transfer.FromUser.Username, transfer.Domain.FullDomain,
+		transfer.Message, transfer.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"), h.cfg.FrontendURL)
+
+	go func() {
+		if err := services.NewEmailService(h.cfg).Send(transfer.ToUser.Email, subject, body); err != nil {
+			fmt.Printf("Warning: Failed to send transfer notification for %s: %v\n", transfer.Domain.FullDomain, err)
+		}
+	}()
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"opendomain/internal/models"
+)
+
+func TestAcceptDomainTransfer(t *testing.T) {
+	tests := []struct {
+		name        string
+		quota       int
+		owned       int // 接收方已有的域名数
+		status      int
+		transferred bool
+	}{
+		{name: "accepted", quota: 2, status: http.StatusOK, transferred: true},
+		{name: "quota exceeded", quota: 1, owned: 1, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			alice := env.createUser(t, "alice")
+			bob := env.createUser(t, "bob")
+			env.db.Model(bob).Update("domain_quota", tt.quota)
+			for i := 0; i < tt.owned; i++ {
+				env.createDomain(t, bob, "bob"+string(rune('a'+i)), "active")
+			}
+
+			// 发起方开启了自动续费并创建了令牌
+			domain := env.createDomain(t, alice, "foo", "active")
+			env.db.Model(domain).Updates(map[string]interface{}{
+				"auto_renew":          true,
+				"auto_renew_failures": 2,
+				"auto_renew_error":    "insufficient balance",
+			})
+			env.db.Create(&models.DomainToken{DomainID: domain.ID, UserID: alice.ID, Scope: "dyndns", TokenHash: "hash", TokenPrefix: "od_"})
+			transfer := &models.DomainTransfer{
+				DomainID:   domain.ID,
+				FromUserID: alice.ID,
+				ToUserID:   bob.ID,
+				Status:     models.TransferStatusPending,
+				ExpiresAt:  time.Now().Add(24 * time.Hour),
+			}
+			if err := env.db.Create(transfer).Error; err != nil {
+				t.Fatalf("create transfer: %v", err)
+			}
+
+			h := NewDomainHandlerWithProvider(env.db, env.cfg, env.provider)
+			c, w := newTestContext(t, http.MethodPost, "/", nil, bob.ID, gin.Params{idParam("id", transfer.ID)})
+			h.AcceptDomainTransfer(c)
+			expectStatus(t, w, tt.status)
+
+			var updated models.Domain
+			env.db.First(&updated, domain.ID)
+			var after models.DomainTransfer
+			env.db.First(&after, transfer.ID)
+			var tokens, history int64
+			env.db.Model(&models.DomainToken{}).Where("domain_id = ?", domain.ID).Count(&tokens)
+			env.db.Model(&models.DomainHistory{}).Where("domain_id = ?", domain.ID).Count(&history)
+
+			if !tt.transferred {
+				if updated.UserID != alice.ID || !updated.AutoRenew || tokens != 1 || history != 0 {
+					t.Errorf("domain should be unchanged, got owner=%d auto_renew=%v tokens=%d history=%d",
+						updated.UserID, updated.AutoRenew, tokens, history)
+				}
+				if after.Status != models.TransferStatusPending {
+					t.Errorf("transfer should stay pending, got %s", after.Status)
+				}
+				return
+			}
+
+			if updated.UserID != bob.ID {
+				t.Errorf("expected owner %d, got %d", bob.ID, updated.UserID)
+			}
+			if after.Status != models.TransferStatusAccepted {
+				t.Errorf("expected transfer accepted, got %s", after.Status)
+			}
+			if tokens != 0 {
+				t.Errorf("sender tokens should be revoked, got %d", tokens)
+			}
+			if history != 2 {
+				t.Errorf("expected history for both users, got %d", history)
+			}
+			// 自动续费从原所有者余额扣款，转移后需要接收方重新开启
+			if updated.AutoRenew || updated.AutoRenewFailures != 0 || updated.AutoRenewError != nil {
+				t.Errorf("auto renew should be reset, got auto_renew=%v failures=%d error=%v",
+					updated.AutoRenew, updated.AutoRenewFailures, updated.AutoRenewError)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/timeutil"
+)
+
+// domainTokenPrefix 域名令牌明文的固定前缀
+const domainTokenPrefix = "odt_"
+
+// ListDomainTokens 获取域名的 API 令牌列表
+func (h *DNSHandler) ListDomainTokens(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var tokens []models.DomainToken
+	if err := h.db.Where("domain_id = ?", domain.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateDomainToken 为域名创建 API 令牌，明文只在响应中返回一次
+func (h *DNSHandler) CreateDomainToken(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	var req models.DomainTokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.DomainToken{}).Where("domain_id = ?", domain.ID).Count(&count)
+	if count >= 10 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A domain can have at most 10 tokens"})
+		return
+	}
+
+	plain, err := generateDomainToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	token := &models.DomainToken{
+		DomainID:    domain.ID,
+		UserID:      domain.UserID,
+		Scope:       req.Scope,
+		TokenHash:   hashDomainToken(plain),
+		TokenPrefix: plain[:len(domainTokenPrefix)+6],
+		Description: req.Description,
+	}
+	if err := h.db.Create(token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Token created successfully. It will not be shown again.",
+		"token":   plain,
+		"info":    token,
+	})
+}
+
+// DeleteDomainToken 吊销域名的 API 令牌
+func (h *DNSHandler) DeleteDomainToken(c *gin.Context) {
+	domain, ok := h.loadManagedDomain(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("id = ? AND domain_id = ?", c.Param("tokenId"), domain.ID).Delete(&models.DomainToken{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+// DynDNSUpdate 兼容 DynDNS2 协议的动态 DNS 更新接口
+// GET /nic/update?hostname=home.example.com&myip=1.2.3.4
+// 只接受 HTTP Basic 认证，密码为 dyndns 类型的域名令牌，用户名任意；
+// 不支持通过查询参数传递令牌，避免令牌出现在访问日志中。
+// 未提供 myip 时使用请求来源 IP。每个 hostname 返回一行结果：
+// good <ip> / nochg <ip> / nohost / notfqdn / abuse / dnserr / badauth / 911
+func (h *DNSHandler) DynDNSUpdate(c *gin.Context) {
+	_, password, hasAuth := c.Request.BasicAuth()
+	token, err := h.authenticateDomainToken(password, "dyndns")
+	if !hasAuth || err != nil {
+		c.Header("WWW-Authenticate", `Basic realm="DynDNS"`)
+		c.String(http.StatusUnauthorized, "badauth")
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").First(&domain, token.DomainID).Error; err != nil {
+		c.String(http.StatusOK, "nohost")
+		return
+	}
+
+	ip := strings.TrimSpace(c.Query("myip"))
+	if ip == "" {
+		ip = c.ClientIP()
+	}
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		c.String(http.StatusBadRequest, "911")
+		return
+	}
+
+	hostnames := strings.Split(c.Query("hostname"), ",")
+	results := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		results = append(results, h.dynDNSUpdateHost(&domain, strings.TrimSpace(hostname), parsedIP))
+	}
+
+	now := timeutil.Now()
+	h.db.Model(token).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": c.ClientIP(),
+	})
+
+	c.String(http.StatusOK, strings.Join(results, "\n"))
+}
+
+// dynDNSUpdateHost 更新单个 hostname 的地址记录，返回 DynDNS2 结果码
+func (h *DNSHandler) dynDNSUpdateHost(domain *models.Domain, hostname string, ip net.IP) string {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if hostname == "" || !strings.Contains(hostname, ".") {
+		return "notfqdn"
+	}
+	if hostname != domain.FullDomain && !strings.HasSuffix(hostname, "."+domain.FullDomain) {
+		return "nohost"
+	}
+	if domain.Status == "suspended" {
+		return "abuse"
+	}
+	if (domain.Status != "active" && domain.Status != models.DomainStatusGrace) || !domain.UseDefaultNameservers {
+		return "nohost"
+	}
+
+	recordType := "AAAA"
+	content := ip.String()
+	if v4 := ip.To4(); v4 != nil {
+		recordType = "A"
+		content = v4.String()
+	}
+	name := extractRecordName(hostname, domain.FullDomain)
+
+	var records []models.DNSRecord
+	if err := h.db.Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?",
+		domain.ID, name, recordType, true).Order("id ASC").Find(&records).Error; err != nil {
+		return "911"
+	}
+
+	if len(records) == 1 && records[0].Content == content {
+		return "nochg " + content
+	}
+
+	// 故障转移托管的地址由健康检查决定，不接受动态更新
+	for _, r := range records {
+		if r.FailoverGroupID != nil {
+			return "nohost"
+		}
+	}
+
+	// 动态 DNS 只保留一个地址：优先保留内容已经相同的记录，其余记录删除
+	keep := 0
+	for i, r := range records {
+		if r.Content == content {
+			keep = i
+			break
+		}
+	}
+	var record models.DNSRecord
+	if len(records) == 0 {
+		record = models.DNSRecord{
+			DomainID: domain.ID,
+			Name:     name,
+			Type:     recordType,
+			Content:  content,
+			TTL:      defaultRecordTTL(domain),
+			IsActive: true,
+		}
+	} else {
+		record = records[keep]
+		record.Content = content
+		record.SyncedToPowerDNS = false
+	}
+
+	// 与其他写入路径一样校验 zone 冲突和根域名策略（TTL、允许的类型、记录数等）
+	conflicts, err := h.zoneConflictsForRecord(domain, &record)
+	if err != nil {
+		return "911"
+	}
+	if len(conflicts) > 0 {
+		fmt.Printf("Warning: DynDNS update rejected for %s: %s\n", hostname, conflicts[0].Message)
+		return "nohost"
+	}
+
+	h.snapshotBeforeChange(domain.ID)
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if len(records) == 0 {
+			return tx.Create(&record).Error
+		}
+
+		if err := tx.Save(&record).Error; err != nil {
+			return err
+		}
+		for i := range records {
+			if i == keep {
+				continue
+			}
+			if err := tx.Delete(&records[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Warning: DynDNS update failed for %s: %v\n", hostname, err)
+		return "911"
+	}
+	h.snapshotAfterChange(domain.ID, "dyndns_update")
+
+	h.syncRecordSetToPowerDNS(&record, domain)
+
+	if err := h.db.First(&record, record.ID).Error; err != nil || !record.SyncedToPowerDNS {
+		return "dnserr"
+	}
+	return "good " + content
+}
+
+// authenticateDomainToken 校验域名令牌明文及其权限范围
+func (h *DNSHandler) authenticateDomainToken(plain, scope string) (*models.DomainToken, error) {
+	if !strings.HasPrefix(plain, domainTokenPrefix) {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	var token models.DomainToken
+	if err := h.db.Where("token_hash = ? AND scope = ?", hashDomainToken(plain), scope).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return &token, nil
+}
+
+// generateDomainToken 生成域名令牌明文
+func generateDomainToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return domainTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+// hashDomainToken 计算令牌的 SHA-256 哈希
+func hashDomainToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
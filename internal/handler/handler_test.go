@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"opendomain/internal/config"
+	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
+	"opendomain/pkg/powerdns"
+)
+
+// testEnv 处理器测试使用的数据库、配置和内存 DNS 后端
+type testEnv struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	provider *dnsprovider.MemoryProvider
+}
+
+// newTestEnv 创建临时 SQLite 数据库和内存 DNS 后端
+// 处理器在事务进行中还会通过 h.db 读取，因此使用 WAL 模式的文件数据库而不是单连接内存数据库
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=off"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.RootDomain{},
+		&models.Domain{},
+		&models.DNSRecord{},
+		&models.DNSSnapshot{},
+		&models.ACMEChallenge{},
+		&models.FailoverGroup{},
+		&models.FailoverEvent{},
+		&models.DomainToken{},
+		&models.DomainTransfer{},
+		&models.DomainHistory{},
+		&models.Order{},
+		&models.Payment{},
+		&models.Coupon{},
+		&models.CouponUsage{},
+	); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	cfg := &config.Config{
+		DNS: config.DNSConfig{Provider: "memory", DefaultNS1: "ns1.example.net", DefaultNS2: "ns2.example.net"},
+	}
+	return &testEnv{db: db, cfg: cfg, provider: dnsprovider.NewMemoryProvider()}
+}
+
+// createUser 创建测试用户
+func (e *testEnv) createUser(t *testing.T, username string) *models.User {
+	t.Helper()
+	user := &models.User{
+		Username:    username,
+		Email:       username + "@example.org",
+		InviteCode:  "INV-" + username,
+		DomainQuota: 5,
+	}
+	if err := e.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// createDomain 在 example.com 下为用户创建域名，并在内存后端中创建对应的 zone
+func (e *testEnv) createDomain(t *testing.T, user *models.User, subdomain, status string) *models.Domain {
+	t.Helper()
+	var root models.RootDomain
+	if err := e.db.Where(models.RootDomain{Domain: "example.com"}).
+		Attrs(models.RootDomain{Nameservers: `["ns1.example.net","ns2.example.net"]`}).
+		FirstOrCreate(&root).Error; err != nil {
+		t.Fatalf("create root domain: %v", err)
+	}
+
+	now := time.Now()
+	domain := &models.Domain{
+		UserID:                user.ID,
+		RootDomainID:          root.ID,
+		Subdomain:             subdomain,
+		FullDomain:            subdomain + ".example.com",
+		SubdomainUnicode:      subdomain,
+		FullDomainUnicode:     subdomain + ".example.com",
+		Status:                status,
+		RegisteredAt:          now,
+		ExpiresAt:             now.AddDate(1, 0, 0),
+		UseDefaultNameservers: true,
+	}
+	if err := e.db.Create(domain).Error; err != nil {
+		t.Fatalf("create domain: %v", err)
+	}
+	if err := e.provider.CreateZone(domain.FullDomain, []string{"ns1.example.net.", "ns2.example.net."}); err != nil {
+		t.Fatalf("create zone: %v", err)
+	}
+	domain.RootDomain = &root
+	return domain
+}
+
+// createRecord 直接在数据库中创建已同步的记录，并写入内存后端
+func (e *testEnv) createRecord(t *testing.T, domain *models.Domain, name, recordType, content string) *models.DNSRecord {
+	t.Helper()
+	record := &models.DNSRecord{
+		DomainID:         domain.ID,
+		Name:             name,
+		Type:             recordType,
+		Content:          content,
+		TTL:              600,
+		IsActive:         true,
+		SyncedToPowerDNS: true,
+	}
+	if err := e.db.Create(record).Error; err != nil {
+		t.Fatalf("create record: %v", err)
+	}
+	entries := []powerdns.RecordEntry{{Content: content}}
+	if err := e.provider.SetRecords(domain.FullDomain, buildRecordFQDN(name, domain.FullDomain), recordType, entries, record.TTL); err != nil {
+		t.Fatalf("push record: %v", err)
+	}
+	return record
+}
+
+// rrsetContents 返回内存后端中某个记录集的内容，记录集不存在时返回 nil
+func (e *testEnv) rrsetContents(t *testing.T, zone, name, recordType string) []string {
+	t.Helper()
+	z, err := e.provider.GetZone(zone)
+	if err != nil {
+		t.Fatalf("get zone %s: %v", zone, err)
+	}
+	for _, rrset := range z.RRsets {
+		if rrset.Name == name+"." && rrset.Type == recordType {
+			contents := make([]string, 0, len(rrset.Records))
+			for _, r := range rrset.Records {
+				contents = append(contents, r.Content)
+			}
+			return contents
+		}
+	}
+	return nil
+}
+
+// newTestContext 构造已登录用户的请求上下文
+func newTestContext(t *testing.T, method, target string, body interface{}, userID uint, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", userID)
+	return c, w
+}
+
+// expectStatus 校验响应状态码
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, w.Code, w.Body.String())
+	}
+}
+
+// waitFor 等待后台同步完成
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func idParam(key string, id uint) gin.Param {
+	return gin.Param{Key: key, Value: fmt.Sprint(id)}
+}
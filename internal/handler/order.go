@@ -47,8 +47,20 @@ func (h *OrderHandler) CalculatePrice(c *gin.Context) {
 		return
 	}
 
+	// 溢价名称按规则价格计算，免费根域名下同样需要购买
+	var premium *models.ReservedName
+	if req.Subdomain != "" {
+		if _, ok := normalizeRequestSubdomain(c, &req.Subdomain, &rootDomain); !ok {
+			return
+		}
+		var ok bool
+		if premium, ok = checkReservedName(c, h.db, req.Subdomain, rootDomain.ID); !ok {
+			return
+		}
+	}
+
 	// 检查是否为免费域名
-	if rootDomain.IsFree {
+	if rootDomain.IsFree && premium == nil {
 		c.JSON(http.StatusOK, models.PriceCalculationResponse{
 			BasePrice:      0,
 			DiscountAmount: 0,
@@ -60,7 +72,14 @@ func (h *OrderHandler) CalculatePrice(c *gin.Context) {
 
 	// 计算基础价格
 	var basePrice float64
-	if req.IsLifetime {
+	if premium != nil {
+		price, err := premiumBasePrice(premium, req.Years, req.IsLifetime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		basePrice = price
+	} else if req.IsLifetime {
 		if rootDomain.LifetimePrice == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Lifetime pricing not available for this domain"})
 			return
@@ -122,6 +141,7 @@ func (h *OrderHandler) CalculatePrice(c *gin.Context) {
 		CouponCode:     req.CouponCode,
 		CouponType:     couponType,
 		CouponError:    couponError,
+		Premium:        premium != nil,
 	})
 }
 
@@ -146,8 +166,19 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// 国际化域名转换为 punycode 并检查长度
+	if _, ok := normalizeRequestSubdomain(c, &req.Subdomain, &rootDomain); !ok {
+		return
+	}
+
+	// 检查保留名称，溢价名称按规则价格购买
+	premium, ok := checkReservedName(c, h.db, req.Subdomain, rootDomain.ID)
+	if !ok {
+		return
+	}
+
 	// 检查是否为免费域名
-	if rootDomain.IsFree {
+	if rootDomain.IsFree && premium == nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "This domain is free. Please use the regular registration endpoint.",
 		})
@@ -166,7 +197,14 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	// 计算价格
 	var basePrice float64
-	if req.IsLifetime {
+	if premium != nil {
+		price, err := premiumBasePrice(premium, req.Years, req.IsLifetime)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		basePrice = price
+	} else if req.IsLifetime {
 		if rootDomain.LifetimePrice == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Lifetime pricing not available"})
 			return
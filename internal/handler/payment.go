@@ -19,6 +19,7 @@ import (
 	"opendomain/internal/config"
 	"opendomain/internal/middleware"
 	"opendomain/internal/models"
+	"opendomain/pkg/dnsprovider"
 	"opendomain/pkg/powerdns"
 	"opendomain/pkg/timeutil"
 )
@@ -27,15 +28,20 @@ import (
 type PaymentHandler struct {
 	db   *gorm.DB
 	cfg  *config.Config
-	pdns *powerdns.Client
+	pdns dnsprovider.Provider
 }
 
 // NewPaymentHandler 创建支付处理器
 func NewPaymentHandler(db *gorm.DB, cfg *config.Config) *PaymentHandler {
+	return NewPaymentHandlerWithProvider(db, cfg, defaultDNSProvider(cfg))
+}
+
+// NewPaymentHandlerWithProvider 使用指定的 DNS 后端创建支付处理器
+func NewPaymentHandlerWithProvider(db *gorm.DB, cfg *config.Config, provider dnsprovider.Provider) *PaymentHandler {
 	return &PaymentHandler{
 		db:   db,
 		cfg:  cfg,
-		pdns: powerdns.NewClient(cfg.PowerDNS.APIURL, cfg.PowerDNS.APIKey),
+		pdns: provider,
 	}
 }
 
@@ -387,7 +393,9 @@ func (h *PaymentHandler) processSuccessfulPayment(
 	now time.Time,
 	clientIP string,
 ) error {
-	return h.db.Transaction(func(tx *gorm.DB) error {
+	var renewed *models.Domain
+	var previousStatus string
+	err := h.db.Transaction(func(tx *gorm.DB) error {
 		// 锁定订单行
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where("id = ?", order.ID).First(order).Error; err != nil {
@@ -415,6 +423,13 @@ func (h *PaymentHandler) processSuccessfulPayment(
 			return err
 		}
 
+		// 续费订单延长已有域名，不创建新域名
+		if order.DomainID != nil {
+			var err error
+			renewed, previousStatus, err = h.completeRenewalOrder(tx, order, now)
+			return err
+		}
+
 		// 计算域名过期时间
 		var expiresAt time.Time
 		if order.IsLifetime {
@@ -486,6 +501,62 @@ func (h *PaymentHandler) processSuccessfulPayment(
 
 		return nil
 	})
+	if err == nil && renewed != nil {
+		restoreRenewedDNS(h.pdns, renewed, previousStatus)
+	}
+	return err
+}
+
+// completeRenewalOrder 续费订单支付完成：延长域名到期时间并记录优惠券使用
+// 宽限期或赎回期的域名恢复为 active，返回续费前的状态用于恢复解析
+func (h *PaymentHandler) completeRenewalOrder(tx *gorm.DB, order *models.Order, now time.Time) (*models.Domain, string, error) {
+	var domain models.Domain
+	if err := tx.Preload("RootDomain").Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&domain, *order.DomainID).Error; err != nil {
+		return nil, "", fmt.Errorf("renewal domain not found: %w", err)
+	}
+	if domain.Status == models.DomainStatusPendingDelete {
+		return nil, "", fmt.Errorf("domain %s is pending deletion and can no longer be renewed", domain.FullDomain)
+	}
+
+	newExpiry := domain.ExpiresAt.AddDate(order.Years, 0, 0)
+	previousStatus := domain.Status
+	if err := tx.Model(&domain).Updates(renewalUpdates(&domain, newExpiry)).Error; err != nil {
+		return nil, "", err
+	}
+
+	order.Status = "paid"
+	order.PaidAt = &now
+	if err := tx.Save(order).Error; err != nil {
+		return nil, "", err
+	}
+
+	if order.CouponID != nil {
+		usage := &models.CouponUsage{
+			CouponID: *order.CouponID,
+			UserID:   order.UserID,
+			DomainID: &domain.ID,
+		}
+		if err := tx.Create(usage).Error; err != nil {
+			return nil, "", err
+		}
+		if err := tx.Model(&models.Coupon{}).
+			Where("id = ?", order.CouponID).
+			UpdateColumn("used_count", gorm.Expr("used_count + ?", 1)).Error; err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := tx.Create(&models.DomainHistory{
+		UserID:     domain.UserID,
+		DomainID:   domain.ID,
+		DomainName: domain.FullDomain,
+		Action:     "renewed",
+		Details:    fmt.Sprintf("Renewed via order %s until %s", order.OrderNumber, newExpiry.UTC().Format("2006-01-02")),
+	}).Error; err != nil {
+		return nil, "", err
+	}
+	return &domain, previousStatus, nil
 }
 
 // initiateNodelocPayment 调用 NodeLoc API 发起支付
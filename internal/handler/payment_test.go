@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"opendomain/internal/models"
+	"opendomain/pkg/powerdns"
+)
+
+func TestRenewalPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		renewed  bool
+		disabled bool // 根域名 zone 中的记录在续费前处于停用状态
+	}{
+		{name: "grace", status: models.DomainStatusGrace, renewed: true},
+		{name: "redemption restores DNS", status: models.DomainStatusRedemption, renewed: true, disabled: true},
+		{name: "pending delete is rejected", status: models.DomainStatusPendingDelete, disabled: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			user := env.createUser(t, "alice")
+			domain := env.createDomain(t, user, "foo", tt.status)
+			if err := env.provider.CreateZone("example.com", []string{"ns1.example.net."}); err != nil {
+				t.Fatalf("create root zone: %v", err)
+			}
+			if err := env.provider.PatchRRsets("example.com", []powerdns.RRset{{
+				Name: "www.foo.example.com.", Type: "A", TTL: 600, ChangeType: "REPLACE",
+				Records: []powerdns.Record{{Content: "192.0.2.1", Disabled: tt.disabled}},
+			}}); err != nil {
+				t.Fatalf("seed root zone: %v", err)
+			}
+
+			order := &models.Order{
+				OrderNumber:  fmt.Sprintf("R2026010100000%d", i),
+				UserID:       user.ID,
+				Subdomain:    domain.Subdomain,
+				RootDomainID: domain.RootDomainID,
+				FullDomain:   domain.FullDomain,
+				Years:        1,
+				BasePrice:    10,
+				FinalPrice:   10,
+				Status:       "pending",
+				DomainID:     &domain.ID,
+				ExpiresAt:    time.Now().Add(time.Hour),
+			}
+			if err := env.db.Create(order).Error; err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+			payment := &models.Payment{OrderID: order.ID, NodelocPaymentID: "np_1", Amount: 10, Status: "pending"}
+			if err := env.db.Create(payment).Error; err != nil {
+				t.Fatalf("create payment: %v", err)
+			}
+
+			h := NewPaymentHandlerWithProvider(env.db, env.cfg, env.provider)
+			req := &models.NodelocCallbackRequest{TransactionID: "tx_1", Amount: 10, Status: "completed", Signature: "sig"}
+			err := h.processSuccessfulPayment(payment, order, req, time.Now(), "203.0.113.1")
+
+			var domains []models.Domain
+			env.db.Find(&domains)
+			if len(domains) != 1 {
+				t.Fatalf("renewal should not create a new domain, got %d domains", len(domains))
+			}
+			var paid models.Payment
+			env.db.First(&paid, payment.ID)
+
+			if !tt.renewed {
+				if err == nil {
+					t.Fatal("expected renewal to fail")
+				}
+				if paid.Status != "pending" || domains[0].Status != tt.status {
+					t.Errorf("payment and domain should be rolled back, got payment=%s domain=%s", paid.Status, domains[0].Status)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("process payment: %v", err)
+			}
+			if paid.Status != "completed" {
+				t.Errorf("expected payment completed, got %s", paid.Status)
+			}
+			if domains[0].Status != models.DomainStatusActive {
+				t.Errorf("expected status active, got %s", domains[0].Status)
+			}
+			if want := domain.ExpiresAt.AddDate(1, 0, 0); !domains[0].ExpiresAt.Equal(want) {
+				t.Errorf("expected expiry %s, got %s", want, domains[0].ExpiresAt)
+			}
+
+			waitFor(t, "DNS to be enabled", func() bool {
+				zone, err := env.provider.GetZone("example.com")
+				if err != nil {
+					return false
+				}
+				for _, rrset := range zone.RRsets {
+					if rrset.Name == "www.foo.example.com." {
+						return len(rrset.Records) == 1 && !rrset.Records[0].Disabled
+					}
+				}
+				return false
+			})
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/config"
+	"opendomain/internal/models"
+	"opendomain/pkg/idn"
+	"opendomain/pkg/timeutil"
+)
+
+const rdapContentType = "application/rdap+json"
+
+// 注册服务商实体的固定 handle
+const rdapRegistrarHandle = "REGISTRAR"
+
+var rdapDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// RDAPHandler RDAP (RFC 9083) 查询服务
+type RDAPHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewRDAPHandler 创建 RDAP 处理器
+func NewRDAPHandler(db *gorm.DB, cfg *config.Config) *RDAPHandler {
+	return &RDAPHandler{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// GetDomain 查询已注册的子域名
+func (h *RDAPHandler) GetDomain(c *gin.Context) {
+	name := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(c.Param("name"))), ".")
+	if ascii, err := idn.ToASCII(name); err == nil {
+		name = ascii // 支持以 Unicode 形式查询国际化域名
+	}
+	if len(name) > 253 || !rdapDomainPattern.MatchString(name) {
+		h.respondError(c, http.StatusBadRequest, "Bad Request", "Invalid domain name")
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.Preload("RootDomain").Preload("User").Where("full_domain = ?", name).First(&domain).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.respondError(c, http.StatusNotFound, "Not Found", fmt.Sprintf("%s is not registered", name))
+			return
+		}
+		h.respondError(c, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	h.respond(c, http.StatusOK, h.buildDomain(c, &domain))
+}
+
+// GetEntity 查询联系人实体：注册人或注册服务商
+func (h *RDAPHandler) GetEntity(c *gin.Context) {
+	handle := strings.ToUpper(strings.TrimSpace(c.Param("handle")))
+
+	if handle == rdapRegistrarHandle {
+		entity := h.registrarEntity(c)
+		entity.RDAPConformance = []string{"rdap_level_0"}
+		entity.Notices = h.notices()
+		h.respond(c, http.StatusOK, entity)
+		return
+	}
+
+	// 只公开当前持有域名的注册人，handle 不可由用户 ID 推算，无法按顺序遍历账户
+	userID, ok := h.parseEntityHandle(handle)
+	if !ok {
+		h.respondError(c, http.StatusNotFound, "Not Found", fmt.Sprintf("Entity %s not found", handle))
+		return
+	}
+
+	var user models.User
+	err := h.db.Where("id = ? AND EXISTS (?)", userID,
+		h.db.Model(&models.Domain{}).Select("1").Where("domains.user_id = users.id AND domains.status <> ?", "deleted")).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.respondError(c, http.StatusNotFound, "Not Found", fmt.Sprintf("Entity %s not found", handle))
+			return
+		}
+		h.respondError(c, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	entity := h.registrantEntity(c, &user)
+	entity.RDAPConformance = []string{"rdap_level_0"}
+	if h.cfg.RDAP.RedactPersonalData {
+		entity.Redacted = rdapRedactions("$")
+		entity.RDAPConformance = append(entity.RDAPConformance, "redacted")
+	}
+	entity.Notices = h.notices()
+	h.respond(c, http.StatusOK, entity)
+}
+
+// Help RDAP 帮助信息
+func (h *RDAPHandler) Help(c *gin.Context) {
+	base := h.baseURL(c)
+	help := models.RDAPHelp{
+		RDAPConformance: []string{"rdap_level_0"},
+		Notices: append([]models.RDAPNotice{{
+			Title: "Help",
+			Description: []string{
+				fmt.Sprintf("RDAP service for domains registered at %s.", h.cfg.SiteName),
+				"Supported queries: /domain/<name>, /entity/<handle>, /help.",
+				"Registrant handles are opaque and are returned in domain responses; the registrar handle is " + rdapRegistrarHandle + ".",
+			},
+			Links: []models.RDAPLink{{Value: base + "/help", Rel: "self", Href: base + "/help", Type: rdapContentType}},
+		}}, h.notices()...),
+	}
+	h.respond(c, http.StatusOK, help)
+}
+
+// buildDomain 将域名转换为 RDAP 域名对象
+func (h *RDAPHandler) buildDomain(c *gin.Context, domain *models.Domain) *models.RDAPDomain {
+	base := h.baseURL(c)
+	self := base + "/domain/" + domain.FullDomain
+
+	var pendingTransfers int64
+	h.db.Model(&models.DomainTransfer{}).
+		Where("domain_id = ? AND status = ?", domain.ID, models.TransferStatusPending).
+		Count(&pendingTransfers)
+
+	events := []models.RDAPEvent{
+		{EventAction: "registration", EventDate: domain.RegisteredAt},
+		{EventAction: "expiration", EventDate: domain.ExpiresAt},
+	}
+	var lastTransfer models.DomainTransfer
+	if err := h.db.Where("domain_id = ? AND status = ? AND responded_at IS NOT NULL", domain.ID, models.TransferStatusAccepted).
+		Order("responded_at DESC").First(&lastTransfer).Error; err == nil {
+		events = append(events, models.RDAPEvent{EventAction: "transfer", EventDate: *lastTransfer.RespondedAt})
+	}
+	events = append(events,
+		models.RDAPEvent{EventAction: "last changed", EventDate: domain.UpdatedAt},
+		models.RDAPEvent{EventAction: "last update of RDAP database", EventDate: timeutil.Now()},
+	)
+
+	var nameservers []models.RDAPNameserver
+	var nsNames []string
+	if err := json.Unmarshal([]byte(domain.Nameservers), &nsNames); err == nil {
+		for _, ns := range nsNames {
+			nameservers = append(nameservers, models.RDAPNameserver{
+				ObjectClassName: "nameserver",
+				LDHName:         strings.TrimSuffix(strings.ToLower(ns), "."),
+			})
+		}
+	}
+
+	result := &models.RDAPDomain{
+		ObjectClassName: "domain",
+		Handle:          fmt.Sprintf("D%d", domain.ID),
+		LDHName:         domain.FullDomain,
+		Status:          rdapStatus(domain.Status, pendingTransfers > 0),
+		Events:          events,
+		Nameservers:     nameservers,
+		Links:           []models.RDAPLink{{Value: self, Rel: "self", Href: self, Type: rdapContentType}},
+		RDAPConformance: []string{"rdap_level_0"},
+		Notices:         h.notices(),
+	}
+
+	if domain.FullDomainUnicode != "" && domain.FullDomainUnicode != domain.FullDomain {
+		result.UnicodeName = domain.FullDomainUnicode
+	}
+
+	if domain.User != nil {
+		result.Entities = append(result.Entities, h.registrantEntity(c, domain.User))
+		if h.cfg.RDAP.RedactPersonalData {
+			result.Redacted = rdapRedactions("$.entities[?(@.roles[0]=='registrant')]")
+			result.RDAPConformance = append(result.RDAPConformance, "redacted")
+		}
+	}
+	result.Entities = append(result.Entities, h.registrarEntity(c))
+	return result
+}
+
+// registrantEntity 注册人实体，开启隐私保护时隐藏姓名和邮箱
+func (h *RDAPHandler) registrantEntity(c *gin.Context, user *models.User) models.RDAPEntity {
+	handle := h.entityHandle(user.ID)
+	self := h.baseURL(c) + "/entity/" + handle
+
+	entity := models.RDAPEntity{
+		ObjectClassName: "entity",
+		Handle:          handle,
+		Roles:           []string{"registrant"},
+		Links:           []models.RDAPLink{{Value: self, Rel: "self", Href: self, Type: rdapContentType}},
+	}
+
+	if h.cfg.RDAP.RedactPersonalData {
+		entity.VCardArray = rdapVCard(
+			[]interface{}{"fn", map[string]interface{}{}, "text", ""},
+			[]interface{}{"kind", map[string]interface{}{}, "text", "individual"},
+		)
+		entity.Remarks = []models.RDAPNotice{{
+			Title:       "REDACTED FOR PRIVACY",
+			Type:        "object redacted due to authorization",
+			Description: []string{"Personal data of the registrant is not published. Use the registrar abuse contact to reach the registrant."},
+		}}
+		return entity
+	}
+
+	name := user.Username
+	if user.RealName != nil && *user.RealName != "" {
+		name = *user.RealName
+	}
+	entity.VCardArray = rdapVCard(
+		[]interface{}{"fn", map[string]interface{}{}, "text", name},
+		[]interface{}{"kind", map[string]interface{}{}, "text", "individual"},
+		[]interface{}{"email", map[string]interface{}{}, "text", user.Email},
+	)
+	return entity
+}
+
+// registrarEntity 注册服务商实体（本站），包含滥用联系人
+func (h *RDAPHandler) registrarEntity(c *gin.Context) models.RDAPEntity {
+	self := h.baseURL(c) + "/entity/" + rdapRegistrarHandle
+	entity := models.RDAPEntity{
+		ObjectClassName: "entity",
+		Handle:          rdapRegistrarHandle,
+		Roles:           []string{"registrar"},
+		VCardArray: rdapVCard(
+			[]interface{}{"fn", map[string]interface{}{}, "text", h.cfg.SiteName},
+			[]interface{}{"kind", map[string]interface{}{}, "text", "org"},
+			[]interface{}{"url", map[string]interface{}{}, "uri", h.cfg.FrontendURL},
+		),
+		Links: []models.RDAPLink{{Value: self, Rel: "self", Href: self, Type: rdapContentType}},
+	}
+
+	if h.cfg.RDAP.AbuseEmail != "" {
+		entity.Entities = []models.RDAPEntity{{
+			ObjectClassName: "entity",
+			Roles:           []string{"abuse"},
+			VCardArray: rdapVCard(
+				[]interface{}{"fn", map[string]interface{}{}, "text", "Abuse Contact"},
+				[]interface{}{"kind", map[string]interface{}{}, "text", "group"},
+				[]interface{}{"email", map[string]interface{}{}, "text", h.cfg.RDAP.AbuseEmail},
+			),
+		}}
+	}
+	return entity
+}
+
+// notices 每个响应附带的使用条款
+func (h *RDAPHandler) notices() []models.RDAPNotice {
+	return []models.RDAPNotice{{
+		Title: "Terms of Use",
+		Description: []string{
+			fmt.Sprintf("This data is provided by %s for information purposes only.", h.cfg.SiteName),
+			"It may not be used for unsolicited advertising or automated bulk collection.",
+		},
+		Links: []models.RDAPLink{{Value: h.cfg.FrontendURL, Rel: "alternate", Href: h.cfg.FrontendURL, Type: "text/html"}},
+	}}
+}
+
+// baseURL 返回 RDAP 服务的对外地址，未配置时按请求生成
+func (h *RDAPHandler) baseURL(c *gin.Context) string {
+	if h.cfg.RDAP.BaseURL != "" {
+		return strings.TrimSuffix(h.cfg.RDAP.BaseURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/rdap", scheme, c.Request.Host)
+}
+
+// entityHandle 注册人的 handle：加密后的用户 ID，形如 U<32 位十六进制>
+func (h *RDAPHandler) entityHandle(userID uint) string {
+	var block [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(block[8:], uint64(userID))
+	h.entityCipher().Encrypt(block[:], block[:])
+	return "U" + strings.ToUpper(hex.EncodeToString(block[:]))
+}
+
+// parseEntityHandle 解密注册人 handle，格式或校验不符时返回 false
+func (h *RDAPHandler) parseEntityHandle(handle string) (uint, bool) {
+	data, err := hex.DecodeString(strings.TrimPrefix(handle, "U"))
+	if !strings.HasPrefix(handle, "U") || err != nil || len(data) != aes.BlockSize {
+		return 0, false
+	}
+	h.entityCipher().Decrypt(data, data)
+	for _, b := range data[:8] {
+		if b != 0 {
+			return 0, false
+		}
+	}
+	return uint(binary.BigEndian.Uint64(data[8:])), true
+}
+
+// entityCipher 由 RDAP_HANDLE_SECRET 派生的加密器，未配置时使用 JWT 密钥
+func (h *RDAPHandler) entityCipher() cipher.Block {
+	secret := h.cfg.RDAP.HandleSecret
+	if secret == "" {
+		secret = h.cfg.JWT.Secret
+	}
+	key := sha256.Sum256([]byte("rdap-entity:" + secret))
+	block, _ := aes.NewCipher(key[:16]) // 密钥长度固定为 16 字节，不会出错
+	return block
+}
+
+func (h *RDAPHandler) respond(c *gin.Context, status int, body interface{}) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(body); err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, rdapContentType, buf.Bytes())
+}
+
+func (h *RDAPHandler) respondError(c *gin.Context, status int, title, description string) {
+	body := models.RDAPError{
+		RDAPConformance: []string{"rdap_level_0"},
+		ErrorCode:       status,
+		Title:           title,
+	}
+	if description != "" {
+		body.Description = []string{description}
+	}
+	h.respond(c, status, body)
+}
+
+// rdapStatus 将域名状态映射为 RDAP 状态值（RFC 8056）
+func rdapStatus(status string, pendingTransfer bool) []string {
+	var result []string
+	switch status {
+	case models.DomainStatusActive:
+		result = []string{"active"}
+	case models.DomainStatusGrace:
+		result = []string{"active", "auto renew period"}
+	case models.DomainStatusRedemption:
+		result = []string{"inactive", "redemption period"}
+	case models.DomainStatusPendingDelete:
+		result = []string{"inactive", "pending delete"}
+	case "suspended":
+		result = []string{"inactive", "server hold"}
+	default:
+		result = []string{"inactive"}
+	}
+	if pendingTransfer {
+		result = append(result, "pending transfer")
+	}
+	return result
+}
+
+// rdapRedactions 注册人姓名和邮箱的隐藏说明（RFC 9537），entityPath 为注册人实体的 JSONPath
+func rdapRedactions(entityPath string) []models.RDAPRedacted {
+	reason := models.RDAPRedactedName{Description: "Server policy"}
+	return []models.RDAPRedacted{
+		{
+			Name:     models.RDAPRedactedName{Type: "Registrant Name"},
+			PostPath: entityPath + ".vcardArray[1][?(@[0]=='fn')][3]",
+			Method:   "emptyValue",
+			Reason:   reason,
+		},
+		{
+			Name:    models.RDAPRedactedName{Type: "Registrant Email"},
+			PrePath: entityPath + ".vcardArray[1][?(@[0]=='email')]",
+			Method:  "removal",
+			Reason:  reason,
+		},
+	}
+}
+
+// rdapVCard 生成 jCard（RFC 7095）
+func rdapVCard(properties ...[]interface{}) []interface{} {
+	card := []interface{}{[]interface{}{"version", map[string]interface{}{}, "text", "4.0"}}
+	for _, p := range properties {
+		card = append(card, p)
+	}
+	return []interface{}{"vcard", card}
+}
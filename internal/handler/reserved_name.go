@@ -0,0 +1,666 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"opendomain/internal/config"
+	"opendomain/internal/models"
+)
+
+// maxReservedImportSize CSV 导入的最大大小
+const maxReservedImportSize = 1 << 20
+
+// maxReservedImportRows 单次导入的最大规则数
+const maxReservedImportRows = 10000
+
+// reservedLabelPattern exact 和 prefix 规则允许的字符
+var reservedLabelPattern = regexp.MustCompile(`^[a-z0-9-]{1,63}$`)
+
+// reservedRegexCache 已编译的正则规则，按原始表达式缓存
+var reservedRegexCache sync.Map
+
+// ReservedNameHandler 保留名称和溢价名称管理
+type ReservedNameHandler struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewReservedNameHandler 创建保留名称处理器
+func NewReservedNameHandler(db *gorm.DB, cfg *config.Config) *ReservedNameHandler {
+	return &ReservedNameHandler{db: db, cfg: cfg}
+}
+
+// FindReservedName 返回对子域名生效的保留或溢价规则，没有命中时返回 nil
+// 多条规则同时命中时：根域名专属规则优先于全局规则，保留优先于溢价，exact 优先于 prefix 和 regex
+func FindReservedName(db *gorm.DB, subdomain string, rootDomainID uint) (*models.ReservedName, error) {
+	subdomain = strings.ToLower(subdomain)
+
+	var rules []models.ReservedName
+	if err := db.Where("(root_domain_id IS NULL OR root_domain_id = ?) AND "+
+		"((match_type = ? AND pattern = ?) OR (match_type = ? AND LEFT(?, LENGTH(pattern)) = pattern) OR match_type = ?)",
+		rootDomainID,
+		models.ReservedMatchExact, subdomain,
+		models.ReservedMatchPrefix, subdomain,
+		models.ReservedMatchRegex).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return matchReservedName(rules, subdomain, rootDomainID), nil
+}
+
+// loadReservedNames 一次加载批量检查多个名称所需的规则，配合 matchReservedName 使用
+func loadReservedNames(db *gorm.DB, subdomains []string, rootDomainIDs []uint) ([]models.ReservedName, error) {
+	var rules []models.ReservedName
+	err := db.Where("(root_domain_id IS NULL OR root_domain_id IN ?) AND ((match_type = ? AND pattern IN ?) OR match_type IN ?)",
+		rootDomainIDs,
+		models.ReservedMatchExact, subdomains,
+		[]string{models.ReservedMatchPrefix, models.ReservedMatchRegex}).
+		Find(&rules).Error
+	return rules, err
+}
+
+// matchReservedName 在已加载的规则中查找对子域名生效的规则
+func matchReservedName(rules []models.ReservedName, subdomain string, rootDomainID uint) *models.ReservedName {
+	var best *models.ReservedName
+	for i := range rules {
+		rule := &rules[i]
+		if rule.RootDomainID != nil && *rule.RootDomainID != rootDomainID {
+			continue
+		}
+		switch rule.MatchType {
+		case models.ReservedMatchExact:
+			if rule.Pattern != subdomain {
+				continue
+			}
+		case models.ReservedMatchPrefix:
+			if !strings.HasPrefix(subdomain, rule.Pattern) {
+				continue
+			}
+		case models.ReservedMatchRegex:
+			re, err := compileReservedRegex(rule.Pattern)
+			if err != nil || !re.MatchString(subdomain) {
+				continue
+			}
+		default:
+			continue
+		}
+		if best == nil || reservedRuleRank(rule) < reservedRuleRank(best) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// checkReservedName 查找子域名命中的规则，名称被保留或查询失败时写入错误响应并返回 false
+// 返回的规则为 nil 或溢价规则
+func checkReservedName(c *gin.Context, db *gorm.DB, subdomain string, rootDomainID uint) (*models.ReservedName, bool) {
+	rule, err := FindReservedName(db, subdomain, rootDomainID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check reserved names"})
+		return nil, false
+	}
+	if rule != nil && !rule.IsPremium() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "This subdomain is reserved",
+			"available": false,
+			"reason":    rule.Reason,
+		})
+		return nil, false
+	}
+	return rule, true
+}
+
+// reservedRuleRank 规则优先级，数值越小越优先
+func reservedRuleRank(rule *models.ReservedName) int {
+	rank := 0
+	if rule.RootDomainID == nil {
+		rank += 100
+	}
+	if rule.Tier != models.ReservedTierReserved {
+		rank += 10
+	}
+	switch rule.MatchType {
+	case models.ReservedMatchPrefix:
+		rank++
+	case models.ReservedMatchRegex:
+		rank += 2
+	}
+	return rank
+}
+
+// compileReservedRegex 编译正则规则，表达式需要匹配整个子域名
+func compileReservedRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := reservedRegexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	reservedRegexCache.Store(pattern, re)
+	return re, nil
+}
+
+// premiumBasePrice 按溢价规则计算注册价格，永久购买需要规则设置了永久价格
+func premiumBasePrice(rule *models.ReservedName, years int, isLifetime bool) (float64, error) {
+	if isLifetime {
+		if rule.LifetimePrice == nil {
+			return 0, fmt.Errorf("Lifetime pricing not available for this premium name")
+		}
+		return *rule.LifetimePrice, nil
+	}
+	if rule.PricePerYear == nil {
+		return 0, fmt.Errorf("Pricing not configured for this premium name")
+	}
+	return *rule.PricePerYear * float64(years), nil
+}
+
+// ListReservedNames 管理员：获取保留名称规则，支持按级别、根域名和关键字筛选
+func (h *ReservedNameHandler) ListReservedNames(c *gin.Context) {
+	page := 1
+	pageSize := 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps > 0 && ps <= 500 {
+		pageSize = ps
+	}
+
+	query := h.db.Model(&models.ReservedName{})
+	if tier := c.Query("tier"); tier != "" {
+		query = query.Where("tier = ?", tier)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	switch rootDomainID := c.Query("root_domain_id"); rootDomainID {
+	case "":
+	case "0", "global":
+		query = query.Where("root_domain_id IS NULL")
+	default:
+		query = query.Where("root_domain_id = ?", rootDomainID)
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("pattern LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count reserved names"})
+		return
+	}
+
+	var rules []models.ReservedName
+	if err := query.Preload("RootDomain").Order("pattern ASC, id ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reserved names"})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reserved_names": rules,
+		"pagination": gin.H{
+			"page":        page,
+			"page_size":   pageSize,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+// CheckReservedName 管理员：检查子域名在某个根域名下命中的规则
+func (h *ReservedNameHandler) CheckReservedName(c *gin.Context) {
+	subdomain := strings.ToLower(strings.TrimSpace(c.Query("subdomain")))
+	if subdomain == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subdomain is required"})
+		return
+	}
+	rootDomainID, _ := strconv.ParseUint(c.Query("root_domain_id"), 10, 64)
+
+	rule, err := FindReservedName(h.db, subdomain, uint(rootDomainID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check reserved names"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subdomain": subdomain,
+		"matched":   rule != nil,
+		"rule":      rule,
+	})
+}
+
+// CreateReservedName 管理员：创建保留或溢价名称规则
+func (h *ReservedNameHandler) CreateReservedName(c *gin.Context) {
+	var req models.ReservedNameCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.ReservedName{
+		Pattern:       req.Pattern,
+		MatchType:     req.MatchType,
+		RootDomainID:  req.RootDomainID,
+		Tier:          req.Tier,
+		Reason:        req.Reason,
+		PricePerYear:  req.PricePerYear,
+		LifetimePrice: req.LifetimePrice,
+		Note:          req.Note,
+	}
+	if err := h.validateReservedName(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if h.reservedNameExists(rule) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A rule with the same pattern already exists for this scope"})
+		return
+	}
+
+	if err := h.db.Create(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reserved name"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Reserved name created successfully",
+		"reserved_name": rule,
+	})
+}
+
+// UpdateReservedName 管理员：更新规则
+func (h *ReservedNameHandler) UpdateReservedName(c *gin.Context) {
+	var rule models.ReservedName
+	if err := h.db.First(&rule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reserved name not found"})
+		return
+	}
+
+	var req models.ReservedNameUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Pattern != nil {
+		rule.Pattern = *req.Pattern
+	}
+	if req.MatchType != nil {
+		rule.MatchType = *req.MatchType
+	}
+	if req.RootDomainID != nil {
+		rule.RootDomainID = req.RootDomainID
+		if *req.RootDomainID == 0 {
+			rule.RootDomainID = nil
+		}
+	}
+	if req.Tier != nil {
+		rule.Tier = *req.Tier
+	}
+	if req.Reason != nil {
+		rule.Reason = *req.Reason
+	}
+	if req.PricePerYear != nil {
+		rule.PricePerYear = req.PricePerYear
+	}
+	if req.LifetimePrice != nil {
+		rule.LifetimePrice = req.LifetimePrice
+	}
+	if req.Note != nil {
+		rule.Note = *req.Note
+	}
+
+	if err := h.validateReservedName(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if h.reservedNameExists(&rule) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A rule with the same pattern already exists for this scope"})
+		return
+	}
+
+	if err := h.db.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reserved name"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Reserved name updated successfully",
+		"reserved_name": rule,
+	})
+}
+
+// DeleteReservedName 管理员：删除规则
+func (h *ReservedNameHandler) DeleteReservedName(c *gin.Context) {
+	result := h.db.Delete(&models.ReservedName{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reserved name"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reserved name not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reserved name deleted successfully"})
+}
+
+// ImportReservedNames 管理员：从 CSV 批量导入规则
+// 列依次为 pattern,match_type,tier,reason,root_domain,price_per_year,lifetime_price,note，
+// 除 pattern 外均可留空；root_domain 填根域名或其 ID，留空表示全局规则；首行可以是表头。
+// 支持 JSON 请求体 {"csv": "...", "dry_run": true}，也支持直接提交 CSV 文本并通过 ?dry_run=1 指定参数。
+// 已存在的同名规则会被更新；任何一行有误时整个导入都不生效。
+func (h *ReservedNameHandler) ImportReservedNames(c *gin.Context) {
+	var req struct {
+		CSV    string `json:"csv" binding:"required"`
+		DryRun bool   `json:"dry_run"`
+	}
+
+	if strings.Contains(c.ContentType(), "json") {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxReservedImportSize+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV"})
+			return
+		}
+		req.CSV = string(body)
+		req.DryRun = isTruthy(c.Query("dry_run"))
+	}
+
+	if len(req.CSV) > maxReservedImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV is too large"})
+		return
+	}
+
+	rules, rowErrors, err := h.parseReservedNamesCSV(req.CSV)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rowErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  fmt.Sprintf("CSV contains %d invalid rows", len(rowErrors)),
+			"errors": rowErrors,
+		})
+		return
+	}
+
+	created, updated := 0, 0
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		for i := range rules {
+			rule := &rules[i]
+
+			var existing models.ReservedName
+			query := tx.Where("pattern = ? AND match_type = ?", rule.Pattern, rule.MatchType)
+			if rule.RootDomainID == nil {
+				query = query.Where("root_domain_id IS NULL")
+			} else {
+				query = query.Where("root_domain_id = ?", *rule.RootDomainID)
+			}
+			err := query.First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				created++
+				if req.DryRun {
+					continue
+				}
+				if err := tx.Create(rule).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			default:
+				updated++
+				if req.DryRun {
+					continue
+				}
+				rule.ID = existing.ID
+				rule.CreatedAt = existing.CreatedAt
+				if err := tx.Save(rule).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import reserved names"})
+		return
+	}
+
+	response := gin.H{
+		"dry_run": req.DryRun,
+		"created": created,
+		"updated": updated,
+	}
+	if !req.DryRun {
+		response["message"] = "Reserved names imported successfully"
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// parseReservedNamesCSV 解析并校验 CSV，返回规则以及带行号的错误说明
+func (h *ReservedNameHandler) parseReservedNamesCSV(text string) ([]models.ReservedName, []string, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	firstRow := 1
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "pattern") {
+		records = records[1:]
+		firstRow = 2
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("CSV contains no rules")
+	}
+	if len(records) > maxReservedImportRows {
+		return nil, nil, fmt.Errorf("CSV contains more than %d rules", maxReservedImportRows)
+	}
+
+	var roots []models.RootDomain
+	if err := h.db.Select("id", "domain").Find(&roots).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load root domains")
+	}
+	rootIDs := make(map[string]uint, len(roots)*2)
+	for _, root := range roots {
+		rootIDs[strings.ToLower(root.Domain)] = root.ID
+		rootIDs[strconv.FormatUint(uint64(root.ID), 10)] = root.ID
+	}
+
+	var rules []models.ReservedName
+	var rowErrors []string
+	seen := make(map[string]int)
+	for i, record := range records {
+		field := func(index int) string {
+			if index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		rule := models.ReservedName{
+			Pattern:   field(0),
+			MatchType: strings.ToLower(field(1)),
+			Tier:      strings.ToLower(field(2)),
+			Reason:    strings.ToLower(field(3)),
+			Note:      field(7),
+		}
+		rowNumber := firstRow + i
+		row := fmt.Sprintf("row %d (%s)", rowNumber, rule.Pattern)
+
+		if root := strings.ToLower(strings.TrimSuffix(field(4), ".")); root != "" {
+			id, ok := rootIDs[root]
+			if !ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("%s: unknown root domain %s", row, root))
+				continue
+			}
+			rule.RootDomainID = &id
+		}
+
+		var priceErr error
+		rule.PricePerYear, priceErr = parseOptionalPrice(field(5))
+		if priceErr == nil {
+			rule.LifetimePrice, priceErr = parseOptionalPrice(field(6))
+		}
+		if priceErr != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s: %v", row, priceErr))
+			continue
+		}
+
+		if err := normalizeReservedName(&rule); err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s: %v", row, err))
+			continue
+		}
+
+		key := fmt.Sprintf("%s|%s|%v", rule.Pattern, rule.MatchType, reservedScopeKey(&rule))
+		if previous, ok := seen[key]; ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("%s: duplicates row %d", row, previous))
+			continue
+		}
+		seen[key] = rowNumber
+		rules = append(rules, rule)
+	}
+	return rules, rowErrors, nil
+}
+
+// parseOptionalPrice 解析 CSV 中的价格，空值表示未设置
+func parseOptionalPrice(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price %q", value)
+	}
+	return &price, nil
+}
+
+// validateReservedName 规范化并校验规则，并确认根域名存在
+func (h *ReservedNameHandler) validateReservedName(rule *models.ReservedName) error {
+	if err := normalizeReservedName(rule); err != nil {
+		return err
+	}
+	if rule.RootDomainID != nil {
+		var count int64
+		h.db.Model(&models.RootDomain{}).Where("id = ?", *rule.RootDomainID).Count(&count)
+		if count == 0 {
+			return fmt.Errorf("Root domain not found")
+		}
+	}
+	return nil
+}
+
+// reservedNameExists 检查同一作用域下是否已有相同的规则
+func (h *ReservedNameHandler) reservedNameExists(rule *models.ReservedName) bool {
+	query := h.db.Model(&models.ReservedName{}).
+		Where("pattern = ? AND match_type = ? AND id <> ?", rule.Pattern, rule.MatchType, rule.ID)
+	if rule.RootDomainID == nil {
+		query = query.Where("root_domain_id IS NULL")
+	} else {
+		query = query.Where("root_domain_id = ?", *rule.RootDomainID)
+	}
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+// normalizeReservedName 填充默认值并校验模式和价格，保留名称不带价格
+func normalizeReservedName(rule *models.ReservedName) error {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if rule.MatchType == "" {
+		rule.MatchType = models.ReservedMatchExact
+	}
+	if rule.Tier == "" {
+		rule.Tier = models.ReservedTierReserved
+	}
+	if rule.Reason == "" {
+		rule.Reason = "system"
+		if rule.Tier == models.ReservedTierPremium {
+			rule.Reason = "premium"
+		}
+	}
+
+	switch rule.MatchType {
+	case models.ReservedMatchExact, models.ReservedMatchPrefix:
+		rule.Pattern = strings.ToLower(rule.Pattern)
+		if !reservedLabelPattern.MatchString(rule.Pattern) {
+			return fmt.Errorf("pattern must contain only letters, digits and hyphens (max 63 characters)")
+		}
+	case models.ReservedMatchRegex:
+		if rule.Pattern == "" || len(rule.Pattern) > 255 {
+			return fmt.Errorf("regex pattern must be between 1 and 255 characters")
+		}
+		if _, err := compileReservedRegex(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid regex pattern: %v", err)
+		}
+	default:
+		return fmt.Errorf("match_type must be exact, prefix or regex")
+	}
+
+	switch rule.Tier {
+	case models.ReservedTierReserved:
+		if rule.Reason == "premium" {
+			return fmt.Errorf("reason premium is only valid for premium names")
+		}
+		rule.PricePerYear = nil
+		rule.LifetimePrice = nil
+	case models.ReservedTierPremium:
+		if rule.PricePerYear == nil || *rule.PricePerYear <= 0 {
+			return fmt.Errorf("premium names require a positive price_per_year")
+		}
+		price := roundMoney(*rule.PricePerYear)
+		rule.PricePerYear = &price
+		if rule.LifetimePrice != nil {
+			if *rule.LifetimePrice < 0 {
+				return fmt.Errorf("lifetime_price must not be negative")
+			}
+			if *rule.LifetimePrice == 0 {
+				rule.LifetimePrice = nil // 0 表示不提供永久购买
+			} else {
+				lifetime := roundMoney(*rule.LifetimePrice)
+				rule.LifetimePrice = &lifetime
+			}
+		}
+	default:
+		return fmt.Errorf("tier must be reserved or premium")
+	}
+
+	switch rule.Reason {
+	case "trademark", "brand", "system", "premium":
+	default:
+		return fmt.Errorf("reason must be trademark, brand, system or premium")
+	}
+	return nil
+}
+
+// reservedScopeKey 规则作用域，全局规则为 0
+func reservedScopeKey(rule *models.ReservedName) uint {
+	if rule.RootDomainID == nil {
+		return 0
+	}
+	return *rule.RootDomainID
+}
@@ -13,6 +13,7 @@ import (
 	"gorm.io/gorm"
 
 	"opendomain/internal/config"
+	"opendomain/internal/i18n"
 	"opendomain/internal/middleware"
 	"opendomain/internal/models"
 	"opendomain/pkg/timeutil"
@@ -79,6 +80,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		UserLevel:    "normal",
 		DomainQuota:  defaultQuota,
 		Status:       "active",
+		Locale:       middleware.GetLocale(c),
 	}
 
 	// 开始事务处理邀请码和奖励
@@ -242,6 +244,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	var req struct {
 		Username *string `json:"username"`
 		Avatar   *string `json:"avatar"`
+		Locale   *string `json:"locale"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -255,6 +258,13 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	if req.Avatar != nil {
 		user.Avatar = req.Avatar
 	}
+	if req.Locale != nil {
+		if !isSupportedLocale(*req.Locale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported locale"})
+			return
+		}
+		user.Locale = *req.Locale
+	}
 
 	if err := h.db.Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
@@ -533,3 +543,13 @@ func generateInviteCode() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// isSupportedLocale 检查语言是否在 i18n 支持的列表中
+func isSupportedLocale(locale string) bool {
+	for _, l := range i18n.GetSupportedLocales() {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ACMEChallenge ACME DNS-01 验证使用的临时 TXT 记录
+// 不写入 dns_records，只直接发布到 PowerDNS，并在过期后自动清理
+type ACMEChallenge struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DomainID  uint      `json:"domain_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:255;not null"` // 相对域名，如 _acme-challenge 或 _acme-challenge.www
+	Value     string    `json:"value" gorm:"size:255;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ACMEChallenge) TableName() string {
+	return "acme_challenges"
+}
+
+// ACMEChallengeRequest lego httpreq 提供者的请求
+// 默认模式提交 fqdn/value，RAW 模式提交 domain/token/keyAuth
+type ACMEChallengeRequest struct {
+	FQDN    string `json:"fqdn"`
+	Value   string `json:"value"`
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// 余额变动类型
+const (
+	BalanceTypeAdjust    = "admin_adjust"
+	BalanceTypeAutoRenew = "auto_renew"
+)
+
+// BalanceTransaction 账户余额变动流水，Amount 为正表示入账，为负表示扣款
+type BalanceTransaction struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	Amount       float64   `json:"amount" gorm:"type:decimal(10,2);not null"`
+	BalanceAfter float64   `json:"balance_after" gorm:"type:decimal(10,2);not null"`
+	Type         string    `json:"type" gorm:"size:20;not null"` // admin_adjust/auto_renew
+	OrderID      *uint     `json:"order_id,omitempty"`
+	Description  string    `json:"description" gorm:"size:255"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (BalanceTransaction) TableName() string {
+	return "balance_transactions"
+}
+
+// BalanceAdjustRequest 管理员调整用户余额请求
+type BalanceAdjustRequest struct {
+	Amount      float64 `json:"amount" binding:"required"`
+	Description string  `json:"description" binding:"required,max=255"`
+}
@@ -11,7 +11,7 @@ type DNSRecord struct {
 	ID              uint           `gorm:"primarykey" json:"id"`
 	DomainID        uint           `gorm:"not null;index" json:"domain_id"`
 	Name            string         `gorm:"size:255;not null" json:"name"`
-	Type            string         `gorm:"size:20;not null" json:"type"` // A, AAAA, CNAME, MX, TXT, NS, SRV, CAA
+	Type            string         `gorm:"size:20;not null" json:"type"` // A, AAAA, CNAME, MX, TXT, NS, SRV, CAA, HTTPS, SVCB, TLSA, SSHFP, DS, NAPTR, URL, LUA
 	Content         string         `gorm:"type:text;not null" json:"content"`
 	TTL             int            `gorm:"default:3600" json:"ttl"`
 	Priority        *int           `json:"priority,omitempty"` // For MX and SRV records
@@ -19,6 +19,7 @@ type DNSRecord struct {
 	SyncedToPowerDNS bool          `gorm:"column:synced_to_powerdns;default:false" json:"synced_to_powerdns"`
 	SyncError       *string        `gorm:"type:text" json:"sync_error,omitempty"`
 	LastSyncedAt    *time.Time     `json:"last_synced_at,omitempty"`
+	FailoverGroupID *uint          `gorm:"index" json:"failover_group_id,omitempty"` // 由故障转移组托管时不能直接修改
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -33,38 +34,61 @@ func (DNSRecord) TableName() string {
 
 // DNSRecordCreateRequest 创建 DNS 记录请求
 type DNSRecordCreateRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Type     string `json:"type" binding:"required,oneof=A AAAA CNAME MX TXT NS SRV CAA"`
-	Content  string `json:"content" binding:"required"`
-	TTL      int    `json:"ttl" binding:"min=60,max=86400"`
-	Priority *int   `json:"priority,omitempty"`
+	Name     string         `json:"name" binding:"required"`
+	Type     string         `json:"type" binding:"required,oneof=A AAAA CNAME MX TXT NS SRV CAA HTTPS SVCB TLSA SSHFP DS NAPTR URL LUA"`
+	Content  string         `json:"content" binding:"required_without=Data"`
+	Data     *DNSRecordData `json:"data,omitempty"` // HTTPS/SVCB/TLSA/SSHFP/DS/NAPTR/URL 可用结构化字段代替 content
+	TTL      int            `json:"ttl" binding:"min=60,max=86400"`
+	Priority *int           `json:"priority,omitempty"`
 }
 
 // DNSRecordUpdateRequest 更新 DNS 记录请求
 type DNSRecordUpdateRequest struct {
-	Name     *string `json:"name,omitempty"`
-	Type     *string `json:"type,omitempty"`
-	Content  *string `json:"content,omitempty"`
-	TTL      *int    `json:"ttl,omitempty"`
-	Priority *int    `json:"priority,omitempty"`
-	IsActive *bool   `json:"is_active,omitempty"`
+	Name     *string        `json:"name,omitempty"`
+	Type     *string        `json:"type,omitempty"`
+	Content  *string        `json:"content,omitempty"`
+	Data     *DNSRecordData `json:"data,omitempty"`
+	TTL      *int           `json:"ttl,omitempty"`
+	Priority *int           `json:"priority,omitempty"`
+	IsActive *bool          `json:"is_active,omitempty"`
+}
+
+// DNSChangeOperation 批量变更中的单个操作
+// create 需要 name/type/content；update 和 delete 需要 record_id，update 只修改提供的字段
+type DNSChangeOperation struct {
+	Action   string         `json:"action" binding:"required,oneof=create update delete"`
+	RecordID uint           `json:"record_id,omitempty"`
+	Name     *string        `json:"name,omitempty"`
+	Type     *string        `json:"type,omitempty"`
+	Content  *string        `json:"content,omitempty"`
+	Data     *DNSRecordData `json:"data,omitempty"`
+	TTL      *int           `json:"ttl,omitempty"`
+	Priority *int           `json:"priority,omitempty"`
+	IsActive *bool          `json:"is_active,omitempty"`
+}
+
+// DNSChangeSetRequest 批量 DNS 变更请求
+type DNSChangeSetRequest struct {
+	Changes []DNSChangeOperation `json:"changes" binding:"required,min=1,max=200,dive"`
 }
 
 // DNSRecordResponse DNS 记录响应
 type DNSRecordResponse struct {
-	ID               uint       `json:"id"`
-	DomainID         uint       `json:"domain_id"`
-	Name             string     `json:"name"`
-	Type             string     `json:"type"`
-	Content          string     `json:"content"`
-	TTL              int        `json:"ttl"`
-	Priority         *int       `json:"priority,omitempty"`
-	IsActive         bool       `json:"is_active"`
-	SyncedToPowerDNS bool       `json:"synced_to_powerdns"`
-	SyncError        *string    `json:"sync_error,omitempty"`
-	LastSyncedAt     *time.Time `json:"last_synced_at,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+	ID               uint           `json:"id"`
+	DomainID         uint           `json:"domain_id"`
+	Name             string         `json:"name"`
+	Type             string         `json:"type"`
+	Content          string         `json:"content"`
+	Data             *DNSRecordData `json:"data,omitempty"`
+	TTL              int            `json:"ttl"`
+	Priority         *int           `json:"priority,omitempty"`
+	IsActive         bool           `json:"is_active"`
+	SyncedToPowerDNS bool           `json:"synced_to_powerdns"`
+	SyncError        *string        `json:"sync_error,omitempty"`
+	LastSyncedAt     *time.Time     `json:"last_synced_at,omitempty"`
+	FailoverGroupID  *uint          `json:"failover_group_id,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
 // ToResponse 转换为响应格式
@@ -75,12 +99,14 @@ func (d *DNSRecord) ToResponse() *DNSRecordResponse {
 		Name:             d.Name,
 		Type:             d.Type,
 		Content:          d.Content,
+		Data:             ParseDNSRecordData(d.Type, d.Content),
 		TTL:              d.TTL,
 		Priority:         d.Priority,
 		IsActive:         d.IsActive,
 		SyncedToPowerDNS: d.SyncedToPowerDNS,
 		SyncError:        d.SyncError,
 		LastSyncedAt:     d.LastSyncedAt,
+		FailoverGroupID:  d.FailoverGroupID,
 		CreatedAt:        d.CreatedAt,
 		UpdatedAt:        d.UpdatedAt,
 	}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// 故障转移探测方式
+const (
+	FailoverProbeHTTP  = "http"
+	FailoverProbeHTTPS = "https"
+	FailoverProbeTCP   = "tcp"
+)
+
+// FailoverGroup 一组按健康检查结果切换的 A/AAAA 目标
+// 组内只有一个目标对外生效，对应一条由故障转移托管的 DNS 记录
+type FailoverGroup struct {
+	ID               uint                            `gorm:"primarykey" json:"id"`
+	DomainID         uint                            `gorm:"not null;index" json:"domain_id"`
+	RecordID         *uint                           `json:"record_id,omitempty"` // 托管的 DNS 记录
+	Name             string                          `gorm:"size:255;not null" json:"name"`
+	Type             string                          `gorm:"size:10;not null" json:"type"` // A, AAAA
+	TTL              int                             `gorm:"not null" json:"ttl"`
+	Primary          string                          `gorm:"column:primary_target;size:64;not null" json:"primary"`
+	Backups          []string                        `gorm:"type:jsonb;serializer:json;not null" json:"backups"`
+	ActiveTarget     string                          `gorm:"size:64;not null" json:"active_target"`
+	ProbeType        string                          `gorm:"size:10;not null" json:"probe_type"` // http, https, tcp
+	ProbePort        int                             `json:"probe_port"`
+	ProbePath        string                          `gorm:"size:255" json:"probe_path"`
+	ProbeHost        string                          `gorm:"size:255" json:"probe_host"`                     // HTTP Host 头，默认为记录的完整域名
+	Interval         int                             `gorm:"column:check_interval;not null" json:"interval"` // 秒
+	FailThreshold    int                             `gorm:"not null" json:"fail_threshold"`
+	RecoverThreshold int                             `gorm:"not null" json:"recover_threshold"`
+	AutoFailback     bool                            `json:"auto_failback"`
+	IsEnabled        bool                            `json:"is_enabled"`
+	TargetHealth     map[string]*FailoverTargetState `gorm:"type:jsonb;serializer:json;not null" json:"target_health"`
+	LastCheckedAt    *time.Time                      `json:"last_checked_at,omitempty"`
+	CreatedAt        time.Time                       `json:"created_at"`
+	UpdatedAt        time.Time                       `json:"updated_at"`
+
+	Domain *Domain `gorm:"foreignKey:DomainID" json:"-"`
+}
+
+// TableName 指定表名
+func (FailoverGroup) TableName() string {
+	return "dns_failover_groups"
+}
+
+// Targets 按优先级返回所有目标（主目标在前）
+func (g *FailoverGroup) Targets() []string {
+	return append([]string{g.Primary}, g.Backups...)
+}
+
+// FailoverTargetState 单个目标的健康状态
+type FailoverTargetState struct {
+	Healthy              bool       `json:"healthy"`
+	ConsecutiveFailures  int        `json:"consecutive_failures"`
+	ConsecutiveSuccesses int        `json:"consecutive_successes"`
+	LastError            string     `json:"last_error,omitempty"`
+	LastCheckedAt        *time.Time `json:"last_checked_at,omitempty"`
+}
+
+// FailoverEvent 故障转移切换记录
+type FailoverEvent struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	GroupID    uint      `gorm:"not null;index" json:"group_id"`
+	DomainID   uint      `gorm:"not null;index" json:"domain_id"`
+	FromTarget string    `gorm:"size:64" json:"from_target"`
+	ToTarget   string    `gorm:"size:64" json:"to_target"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (FailoverEvent) TableName() string {
+	return "dns_failover_events"
+}
+
+// FailoverGroupCreateRequest 创建故障转移组请求
+type FailoverGroupCreateRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Type             string   `json:"type" binding:"required,oneof=A AAAA"`
+	TTL              int      `json:"ttl" binding:"omitempty,min=60,max=3600"`
+	Primary          string   `json:"primary" binding:"required,ip"`
+	Backups          []string `json:"backups" binding:"required,min=1,max=5,dive,ip"`
+	ProbeType        string   `json:"probe_type" binding:"required,oneof=http https tcp"`
+	ProbePort        int      `json:"probe_port" binding:"omitempty,min=1,max=65535"`
+	ProbePath        string   `json:"probe_path" binding:"omitempty,startswith=/,max=255"`
+	ProbeHost        string   `json:"probe_host" binding:"omitempty,hostname_rfc1123,max=255"`
+	Interval         int      `json:"interval" binding:"omitempty,min=30,max=3600"`
+	FailThreshold    int      `json:"fail_threshold" binding:"omitempty,min=1,max=10"`
+	RecoverThreshold int      `json:"recover_threshold" binding:"omitempty,min=1,max=10"`
+	AutoFailback     *bool    `json:"auto_failback"`
+}
+
+// FailoverGroupUpdateRequest 更新故障转移组请求，名称和类型不可修改
+type FailoverGroupUpdateRequest struct {
+	TTL              *int     `json:"ttl" binding:"omitempty,min=60,max=3600"`
+	Primary          *string  `json:"primary" binding:"omitempty,ip"`
+	Backups          []string `json:"backups" binding:"omitempty,min=1,max=5,dive,ip"`
+	ProbeType        *string  `json:"probe_type" binding:"omitempty,oneof=http https tcp"`
+	ProbePort        *int     `json:"probe_port" binding:"omitempty,min=1,max=65535"`
+	ProbePath        *string  `json:"probe_path" binding:"omitempty,startswith=/,max=255"`
+	ProbeHost        *string  `json:"probe_host" binding:"omitempty,max=255"`
+	Interval         *int     `json:"interval" binding:"omitempty,min=30,max=3600"`
+	FailThreshold    *int     `json:"fail_threshold" binding:"omitempty,min=1,max=10"`
+	RecoverThreshold *int     `json:"recover_threshold" binding:"omitempty,min=1,max=10"`
+	AutoFailback     *bool    `json:"auto_failback"`
+	IsEnabled        *bool    `json:"is_enabled"`
+}
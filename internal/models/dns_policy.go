@@ -0,0 +1,44 @@
+package models
+
+// DNSPolicy 根域名的 DNS 记录策略，只在创建和修改记录时检查，已有记录不受影响
+type DNSPolicy struct {
+	AllowedTypes  []string `gorm:"column:dns_allowed_types;type:text;serializer:json" json:"allowed_types"` // 为空表示允许所有支持的类型
+	MinTTL        int      `gorm:"column:dns_min_ttl" json:"min_ttl"`
+	MaxTTL        int      `gorm:"column:dns_max_ttl" json:"max_ttl"`
+	MaxRecords    int      `gorm:"column:dns_max_records" json:"max_records"` // 每个域名的记录数上限，0 表示不限制
+	AllowWildcard bool     `gorm:"column:dns_allow_wildcard" json:"allow_wildcard"`
+	ApexOnlyTypes []string `gorm:"column:dns_apex_only_types;type:text;serializer:json" json:"apex_only_types"` // 只能在 @ 创建的类型
+}
+
+// DefaultDNSPolicy 新建根域名时使用的默认策略（与不设策略时的行为一致）
+func DefaultDNSPolicy() DNSPolicy {
+	return DNSPolicy{
+		AllowedTypes:  []string{},
+		MinTTL:        60,
+		MaxTTL:        86400,
+		AllowWildcard: true,
+		ApexOnlyTypes: []string{},
+	}
+}
+
+// AllowsType 判断策略是否允许该记录类型
+func (p *DNSPolicy) AllowsType(recordType string) bool {
+	if len(p.AllowedTypes) == 0 {
+		return true
+	}
+	return containsType(p.AllowedTypes, recordType)
+}
+
+// IsApexOnly 判断该记录类型是否只能在 apex 创建
+func (p *DNSPolicy) IsApexOnly(recordType string) bool {
+	return containsType(p.ApexOnlyTypes, recordType)
+}
+
+func containsType(types []string, recordType string) bool {
+	for _, t := range types {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// DNSReconcileStatus 记录每个域名最近一次数据库与 PowerDNS 对账的结果
+type DNSReconcileStatus struct {
+	DomainID            uint       `json:"domain_id" gorm:"primaryKey;autoIncrement:false"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"default:0"`
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	DriftCount          int        `json:"drift_count" gorm:"default:0"`     // 无法修复的记录集数量
+	Drift               string     `json:"drift,omitempty" gorm:"type:text"` // 无法修复的记录集描述，JSON 数组
+	LastError           *string    `json:"last_error,omitempty" gorm:"type:text"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+
+	Domain *Domain `json:"domain,omitempty" gorm:"foreignKey:DomainID"`
+}
+
+// TableName 指定表名
+func (DNSReconcileStatus) TableName() string {
+	return "dns_reconcile_status"
+}
@@ -0,0 +1,444 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StructuredRecordTypes 使用结构化字段的记录类型（URL 为平台提供的重定向伪记录）
+var StructuredRecordTypes = []string{"HTTPS", "SVCB", "TLSA", "SSHFP", "DS", "NAPTR", "URL", "LUA"}
+
+// URL 重定向记录的模式
+const (
+	RedirectPermanent = "301"
+	RedirectTemporary = "302"
+	RedirectFrame     = "frame" // 以 frame 嵌入目标页面，地址栏保持原域名
+)
+
+// redirectPreservePathFlag URL 记录内容中表示保留请求路径的标记
+const redirectPreservePathFlag = "preserve-path"
+
+// LUA 记录支持的函数，Lua 代码只由这些模板生成，不接受用户提交的任意代码
+const (
+	LuaPickWeightedRandom = "pickwrandom" // 按权重随机返回一个地址
+	LuaCountry            = "country"     // 按客户端所在国家返回地址
+	LuaIfPortUp           = "ifportup"    // 返回端口可用的地址
+)
+
+// 解析生成的 LUA 记录内容
+var (
+	luaPickWeightedPattern = regexp.MustCompile(`^pickwrandom\(\{((?:\{\d+,'[^'{}]+'\},?)+)\}\)$`)
+	luaWeightedPairPattern = regexp.MustCompile(`\{(\d+),'([^'{}]+)'\}`)
+	luaIfPortUpPattern     = regexp.MustCompile(`^ifportup\((\d+),\{([^{}]+)\}\)$`)
+	luaCountryPattern      = regexp.MustCompile(`^;((?:if country\(\{[^{}]+\}\) then return \{[^{}]+\} end )+)return \{([^{}]+)\}$`)
+	luaCountryRulePattern  = regexp.MustCompile(`if country\(\{([^{}]+)\}\) then return \{([^{}]+)\} end `)
+)
+
+// IsStructuredRecordType 判断记录类型是否使用结构化字段
+func IsStructuredRecordType(recordType string) bool {
+	for _, t := range StructuredRecordTypes {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// DNSRecordData 结构化记录字段，只填写对应类型需要的字段
+type DNSRecordData struct {
+	// HTTPS/SVCB
+	SvcPriority *int              `json:"svc_priority,omitempty"`
+	Target      string            `json:"target,omitempty"`
+	Params      map[string]string `json:"params,omitempty"` // alpn=h3,h2 port=443 ...
+
+	// TLSA
+	Usage        *int   `json:"usage,omitempty"`
+	Selector     *int   `json:"selector,omitempty"`
+	MatchingType *int   `json:"matching_type,omitempty"`
+	Certificate  string `json:"certificate,omitempty"`
+
+	// SSHFP（algorithm 与 DS 共用）
+	Algorithm       *int   `json:"algorithm,omitempty"`
+	FingerprintType *int   `json:"fingerprint_type,omitempty"`
+	Fingerprint     string `json:"fingerprint,omitempty"`
+
+	// DS
+	KeyTag     *int   `json:"key_tag,omitempty"`
+	DigestType *int   `json:"digest_type,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+
+	// NAPTR
+	Order       *int   `json:"order,omitempty"`
+	Preference  *int   `json:"preference,omitempty"`
+	Flags       string `json:"flags,omitempty"`
+	Service     string `json:"service,omitempty"`
+	Regexp      string `json:"regexp,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+
+	// URL
+	Redirect     string `json:"redirect,omitempty"` // 301、302 或 frame
+	URL          string `json:"url,omitempty"`
+	PreservePath bool   `json:"preserve_path,omitempty"`
+
+	// LUA（port 用于 ifportup，addresses 为 ifportup 的候选地址或 country 的默认答案）
+	LuaType     string              `json:"lua_type,omitempty"` // A 或 AAAA
+	LuaFunction string              `json:"lua_function,omitempty"`
+	Weighted    []LuaWeightedTarget `json:"weighted,omitempty"`
+	Countries   []LuaCountryRule    `json:"countries,omitempty"`
+	Addresses   []string            `json:"addresses,omitempty"`
+	Port        *int                `json:"port,omitempty"`
+}
+
+// LuaWeightedTarget pickwrandom 的一个候选地址
+type LuaWeightedTarget struct {
+	Address string `json:"address"`
+	Weight  int    `json:"weight"`
+}
+
+// LuaCountryRule country 规则：客户端位于 countries 中的国家时返回 addresses
+type LuaCountryRule struct {
+	Countries []string `json:"countries"`
+	Addresses []string `json:"addresses"`
+}
+
+// svcParamOrder SVCB 参数的键序号，用于输出规范顺序
+var svcParamOrder = map[string]int{
+	"mandatory":       0,
+	"alpn":            1,
+	"no-default-alpn": 2,
+	"port":            3,
+	"ipv4hint":        4,
+	"ech":             5,
+	"ipv6hint":        6,
+}
+
+// Content 将结构化字段组合为记录内容（presentation 格式）
+// 只检查必填字段是否齐全，取值是否合法由调用方对生成的内容做校验
+func (d *DNSRecordData) Content(recordType string) (string, error) {
+	missing := func(fields ...string) error {
+		return fmt.Errorf("%s record requires %s", recordType, strings.Join(fields, ", "))
+	}
+
+	switch recordType {
+	case "HTTPS", "SVCB":
+		if d.SvcPriority == nil || d.Target == "" {
+			return "", missing("svc_priority", "target")
+		}
+		parts := []string{strconv.Itoa(*d.SvcPriority), d.Target}
+		keys := make([]string, 0, len(d.Params))
+		for k := range d.Params {
+			keys = append(keys, k)
+		}
+		SortSvcParamKeys(keys)
+		for _, k := range keys {
+			if d.Params[k] == "" {
+				parts = append(parts, k)
+			} else {
+				parts = append(parts, k+"="+d.Params[k])
+			}
+		}
+		return strings.Join(parts, " "), nil
+	case "TLSA":
+		if d.Usage == nil || d.Selector == nil || d.MatchingType == nil || d.Certificate == "" {
+			return "", missing("usage", "selector", "matching_type", "certificate")
+		}
+		return fmt.Sprintf("%d %d %d %s", *d.Usage, *d.Selector, *d.MatchingType, d.Certificate), nil
+	case "SSHFP":
+		if d.Algorithm == nil || d.FingerprintType == nil || d.Fingerprint == "" {
+			return "", missing("algorithm", "fingerprint_type", "fingerprint")
+		}
+		return fmt.Sprintf("%d %d %s", *d.Algorithm, *d.FingerprintType, d.Fingerprint), nil
+	case "DS":
+		if d.KeyTag == nil || d.Algorithm == nil || d.DigestType == nil || d.Digest == "" {
+			return "", missing("key_tag", "algorithm", "digest_type", "digest")
+		}
+		return fmt.Sprintf("%d %d %d %s", *d.KeyTag, *d.Algorithm, *d.DigestType, d.Digest), nil
+	case "NAPTR":
+		if d.Order == nil || d.Preference == nil || d.Replacement == "" {
+			return "", missing("order", "preference", "replacement")
+		}
+		return fmt.Sprintf("%d %d %s %s %s %s", *d.Order, *d.Preference,
+			QuoteRecordString(d.Flags), QuoteRecordString(d.Service), QuoteRecordString(d.Regexp), d.Replacement), nil
+	case "URL":
+		if d.Redirect == "" || d.URL == "" {
+			return "", missing("redirect", "url")
+		}
+		content := d.Redirect + " " + QuoteRecordString(d.URL)
+		if d.PreservePath {
+			content += " " + redirectPreservePathFlag
+		}
+		return content, nil
+	case "LUA":
+		return d.luaContent()
+	}
+	return "", fmt.Errorf("record type %s has no structured fields", recordType)
+}
+
+// luaContent 生成 LUA 记录内容，例如：
+//
+//	A "pickwrandom({{100,'192.0.2.1'},{50,'192.0.2.2'}})"
+//	A "ifportup(443,{'192.0.2.1','192.0.2.2'})"
+//	A ";if country({'US','CA'}) then return {'192.0.2.1'} end return {'192.0.2.2'}"
+func (d *DNSRecordData) luaContent() (string, error) {
+	if d.LuaType == "" || d.LuaFunction == "" {
+		return "", fmt.Errorf("LUA record requires lua_type, lua_function")
+	}
+
+	var code string
+	switch d.LuaFunction {
+	case LuaPickWeightedRandom:
+		if len(d.Weighted) == 0 {
+			return "", fmt.Errorf("pickwrandom requires weighted")
+		}
+		pairs := make([]string, len(d.Weighted))
+		for i, t := range d.Weighted {
+			pairs[i] = fmt.Sprintf("{%d,'%s'}", t.Weight, t.Address)
+		}
+		code = fmt.Sprintf("pickwrandom({%s})", strings.Join(pairs, ","))
+	case LuaIfPortUp:
+		if d.Port == nil || len(d.Addresses) == 0 {
+			return "", fmt.Errorf("ifportup requires port, addresses")
+		}
+		code = fmt.Sprintf("ifportup(%d,{%s})", *d.Port, luaStringList(d.Addresses))
+	case LuaCountry:
+		if len(d.Countries) == 0 || len(d.Addresses) == 0 {
+			return "", fmt.Errorf("country requires countries, addresses")
+		}
+		var b strings.Builder
+		b.WriteString(";")
+		for _, rule := range d.Countries {
+			fmt.Fprintf(&b, "if country({%s}) then return {%s} end ", luaStringList(rule.Countries), luaStringList(rule.Addresses))
+		}
+		fmt.Fprintf(&b, "return {%s}", luaStringList(d.Addresses))
+		code = b.String()
+	default:
+		return "", fmt.Errorf("unsupported LUA function %q", d.LuaFunction)
+	}
+	return d.LuaType + " " + QuoteRecordString(code), nil
+}
+
+// parseLuaData 解析由 luaContent 生成的内容，其他 Lua 代码返回 nil
+func parseLuaData(fields []string) *DNSRecordData {
+	if len(fields) != 2 {
+		return nil
+	}
+	data := &DNSRecordData{LuaType: fields[0]}
+	code := UnquoteRecordString(fields[1])
+
+	if m := luaPickWeightedPattern.FindStringSubmatch(code); m != nil {
+		data.LuaFunction = LuaPickWeightedRandom
+		for _, pair := range luaWeightedPairPattern.FindAllStringSubmatch(m[1], -1) {
+			weight, err := strconv.Atoi(pair[1])
+			if err != nil {
+				return nil
+			}
+			data.Weighted = append(data.Weighted, LuaWeightedTarget{Address: pair[2], Weight: weight})
+		}
+		return data
+	}
+	if m := luaIfPortUpPattern.FindStringSubmatch(code); m != nil {
+		port, err := strconv.Atoi(m[1])
+		addresses, ok := parseLuaStringList(m[2])
+		if err != nil || !ok {
+			return nil
+		}
+		data.LuaFunction = LuaIfPortUp
+		data.Port = &port
+		data.Addresses = addresses
+		return data
+	}
+	if m := luaCountryPattern.FindStringSubmatch(code); m != nil {
+		data.LuaFunction = LuaCountry
+		for _, rule := range luaCountryRulePattern.FindAllStringSubmatch(m[1], -1) {
+			countries, ok1 := parseLuaStringList(rule[1])
+			addresses, ok2 := parseLuaStringList(rule[2])
+			if !ok1 || !ok2 {
+				return nil
+			}
+			data.Countries = append(data.Countries, LuaCountryRule{Countries: countries, Addresses: addresses})
+		}
+		addresses, ok := parseLuaStringList(m[2])
+		if !ok {
+			return nil
+		}
+		data.Addresses = addresses
+		return data
+	}
+	return nil
+}
+
+// luaStringList 生成 Lua 字符串列表：'a','b'
+func luaStringList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return strings.Join(quoted, ",")
+}
+
+// parseLuaStringList 解析 'a','b' 形式的列表
+func parseLuaStringList(s string) ([]string, bool) {
+	var values []string
+	for _, item := range strings.Split(s, ",") {
+		if len(item) < 2 || item[0] != '\'' || item[len(item)-1] != '\'' || strings.Contains(item[1:len(item)-1], "'") {
+			return nil, false
+		}
+		values = append(values, item[1:len(item)-1])
+	}
+	return values, true
+}
+
+// ParseDNSRecordData 将结构化类型的记录内容解析为字段，无法解析时返回 nil
+func ParseDNSRecordData(recordType, content string) *DNSRecordData {
+	if !IsStructuredRecordType(recordType) {
+		return nil
+	}
+
+	fields := SplitRecordFields(content)
+	ints := func(n int) ([]*int, bool) {
+		if len(fields) < n {
+			return nil, false
+		}
+		values := make([]*int, n)
+		for i := 0; i < n; i++ {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, false
+			}
+			values[i] = &v
+		}
+		return values, true
+	}
+
+	switch recordType {
+	case "HTTPS", "SVCB":
+		values, ok := ints(1)
+		if !ok || len(fields) < 2 {
+			return nil
+		}
+		data := &DNSRecordData{SvcPriority: values[0], Target: fields[1]}
+		if len(fields) > 2 {
+			data.Params = make(map[string]string)
+			for _, param := range fields[2:] {
+				key, value, _ := strings.Cut(param, "=")
+				data.Params[key] = UnquoteRecordString(value)
+			}
+		}
+		return data
+	case "TLSA":
+		values, ok := ints(3)
+		if !ok || len(fields) < 4 {
+			return nil
+		}
+		return &DNSRecordData{Usage: values[0], Selector: values[1], MatchingType: values[2], Certificate: strings.Join(fields[3:], "")}
+	case "SSHFP":
+		values, ok := ints(2)
+		if !ok || len(fields) < 3 {
+			return nil
+		}
+		return &DNSRecordData{Algorithm: values[0], FingerprintType: values[1], Fingerprint: strings.Join(fields[2:], "")}
+	case "DS":
+		values, ok := ints(3)
+		if !ok || len(fields) < 4 {
+			return nil
+		}
+		return &DNSRecordData{KeyTag: values[0], Algorithm: values[1], DigestType: values[2], Digest: strings.Join(fields[3:], "")}
+	case "NAPTR":
+		values, ok := ints(2)
+		if !ok || len(fields) != 6 {
+			return nil
+		}
+		return &DNSRecordData{
+			Order:       values[0],
+			Preference:  values[1],
+			Flags:       UnquoteRecordString(fields[2]),
+			Service:     UnquoteRecordString(fields[3]),
+			Regexp:      UnquoteRecordString(fields[4]),
+			Replacement: fields[5],
+		}
+	case "LUA":
+		return parseLuaData(fields)
+	case "URL":
+		if len(fields) < 2 {
+			return nil
+		}
+		data := &DNSRecordData{Redirect: fields[0], URL: UnquoteRecordString(fields[1])}
+		for _, flag := range fields[2:] {
+			if flag == redirectPreservePathFlag {
+				data.PreservePath = true
+			}
+		}
+		return data
+	}
+	return nil
+}
+
+// SortSvcParamKeys 按 SVCB 参数键序号排序（keyNNNNN 按数字排序）
+func SortSvcParamKeys(keys []string) {
+	number := func(k string) int {
+		if n, ok := svcParamOrder[k]; ok {
+			return n
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(k, "key")); err == nil {
+			return n
+		}
+		return 1 << 20
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return number(keys[i]) < number(keys[j]) })
+}
+
+// SplitRecordFields 按空白拆分记录内容，引号内的空白不拆分，引号保留
+func SplitRecordFields(content string) []string {
+	var (
+		fields  []string
+		current strings.Builder
+		inQuote bool
+		escaped bool
+		inField bool
+	)
+	for _, ch := range content {
+		switch {
+		case escaped:
+			current.WriteRune(ch)
+			escaped = false
+		case ch == '\\':
+			current.WriteRune(ch)
+			escaped = true
+			inField = true
+		case ch == '"':
+			current.WriteRune(ch)
+			inQuote = !inQuote
+			inField = true
+		case (ch == ' ' || ch == '\t') && !inQuote:
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(ch)
+			inField = true
+		}
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// QuoteRecordString 将字符串转为带引号的 character-string
+func QuoteRecordString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// UnquoteRecordString 去掉 character-string 的引号并还原转义
+func UnquoteRecordString(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		s = s[1 : len(s)-1]
+		return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(s)
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// DNSSnapshot 域名 DNS 记录的版本快照，每次记录变更后保存一次完整的记录集
+type DNSSnapshot struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DomainID  uint      `json:"domain_id" gorm:"not null;index"`
+	Version   int       `json:"version" gorm:"not null"`
+	Action    string    `json:"action" gorm:"size:50;not null"` // baseline/create_record/update_record/delete_record/...
+	Records   string    `json:"-" gorm:"type:text;not null"`    // JSON array of DNSSnapshotRecord
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (DNSSnapshot) TableName() string {
+	return "dns_snapshots"
+}
+
+// DNSSnapshotRecord 快照中的单条记录
+type DNSSnapshotRecord struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	TTL      int    `json:"ttl"`
+	Priority *int   `json:"priority,omitempty"`
+	IsActive bool   `json:"is_active"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// DNSTemplate 管理员维护的 DNS 记录模板，用于一次性添加邮箱、站点验证等常用配置
+// 记录的名称和内容中可以使用 {{变量}}，应用模板时替换
+type DNSTemplate struct {
+	ID          uint                  `gorm:"primaryKey" json:"id"`
+	Slug        string                `gorm:"uniqueIndex;size:64;not null" json:"slug"`
+	Name        string                `gorm:"size:100;not null" json:"name"`
+	Description string                `gorm:"type:text" json:"description"`
+	Category    string                `gorm:"size:32;not null" json:"category"` // mail, verification, website, other
+	Variables   []DNSTemplateVariable `gorm:"type:jsonb;serializer:json;not null" json:"variables"`
+	Records     []DNSTemplateRecord   `gorm:"type:jsonb;serializer:json;not null" json:"records"`
+	IsBuiltin   bool                  `json:"is_builtin"`
+	IsActive    bool                  `json:"is_active"` // 不设 gorm 默认值，创建时停用的模板才能写入 false
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (DNSTemplate) TableName() string {
+	return "dns_templates"
+}
+
+// DNSTemplateVariable 模板变量，{{domain}} 和 {{subdomain}} 由系统提供，无需声明
+type DNSTemplateVariable struct {
+	Name        string `json:"name" binding:"required"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// DNSTemplateRecord 模板中的一条记录
+type DNSTemplateRecord struct {
+	Name     string `json:"name" binding:"required"`
+	Type     string `json:"type" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+	TTL      int    `json:"ttl,omitempty"`
+	Priority *int   `json:"priority,omitempty"`
+}
+
+// DNSTemplateCreateRequest 创建模板请求
+type DNSTemplateCreateRequest struct {
+	Slug        string                `json:"slug" binding:"required,min=2,max=64"`
+	Name        string                `json:"name" binding:"required,max=100"`
+	Description string                `json:"description"`
+	Category    string                `json:"category" binding:"required,oneof=mail verification website other"`
+	Variables   []DNSTemplateVariable `json:"variables" binding:"dive"`
+	Records     []DNSTemplateRecord   `json:"records" binding:"required,min=1,max=50,dive"`
+	IsActive    *bool                 `json:"is_active"`
+}
+
+// DNSTemplateUpdateRequest 更新模板请求
+type DNSTemplateUpdateRequest struct {
+	Name        *string               `json:"name" binding:"omitempty,max=100"`
+	Description *string               `json:"description"`
+	Category    *string               `json:"category" binding:"omitempty,oneof=mail verification website other"`
+	Variables   []DNSTemplateVariable `json:"variables" binding:"omitempty,dive"` // nil 表示不修改
+	Records     []DNSTemplateRecord   `json:"records" binding:"omitempty,max=50,dive"`
+	IsActive    *bool                 `json:"is_active"`
+}
+
+// DNSTemplateApplyRequest 应用模板请求
+type DNSTemplateApplyRequest struct {
+	TemplateID uint              `json:"template_id"`
+	Slug       string            `json:"slug"`
+	Subdomain  string            `json:"subdomain"` // 记录所在的名称，默认为 @
+	Variables  map[string]string `json:"variables"`
+	DryRun     bool              `json:"dry_run"`
+}
@@ -4,27 +4,33 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"opendomain/pkg/idn"
 )
 
 // RootDomain 根域名模型
 type RootDomain struct {
-	ID                    uint      `gorm:"primarykey" json:"id"`
-	Domain                string    `gorm:"size:100;not null;uniqueIndex" json:"domain"`
-	Description           *string   `json:"description,omitempty"`
-	Nameservers           string    `gorm:"type:text;not null" json:"nameservers"` // JSON array as text
-	UseDefaultNameservers bool      `gorm:"default:true" json:"use_default_nameservers"`
-	IsActive              bool      `gorm:"default:true" json:"is_active"`
-	IsHot                 bool      `gorm:"default:false" json:"is_hot"`
-	IsNew                 bool      `gorm:"default:false" json:"is_new"`
-	Priority              int       `gorm:"default:0" json:"priority"`
-	MinLength             int       `gorm:"default:3" json:"min_length"`
-	MaxLength             int       `gorm:"default:63" json:"max_length"`
-	RegistrationCount     int       `gorm:"default:0" json:"registration_count"`
-	PricePerYear          *float64  `gorm:"type:decimal(10,2)" json:"price_per_year,omitempty"`
-	LifetimePrice         *float64  `gorm:"type:decimal(10,2)" json:"lifetime_price,omitempty"`
-	IsFree                bool      `gorm:"default:true" json:"is_free"`
-	CreatedAt             time.Time `json:"created_at"`
-	UpdatedAt             time.Time `json:"updated_at"`
+	ID                    uint            `gorm:"primarykey" json:"id"`
+	Domain                string          `gorm:"size:100;not null;uniqueIndex" json:"domain"`
+	Description           *string         `json:"description,omitempty"`
+	Nameservers           string          `gorm:"type:text;not null" json:"nameservers"` // JSON array as text
+	UseDefaultNameservers bool            `gorm:"default:true" json:"use_default_nameservers"`
+	IsActive              bool            `gorm:"default:true" json:"is_active"`
+	IsHot                 bool            `gorm:"default:false" json:"is_hot"`
+	IsNew                 bool            `gorm:"default:false" json:"is_new"`
+	Priority              int             `gorm:"default:0" json:"priority"`
+	MinLength             int             `gorm:"default:3" json:"min_length"`
+	MaxLength             int             `gorm:"default:63" json:"max_length"`
+	RegistrationCount     int             `gorm:"default:0" json:"registration_count"`
+	PricePerYear          *float64        `gorm:"type:decimal(10,2)" json:"price_per_year,omitempty"`
+	LifetimePrice         *float64        `gorm:"type:decimal(10,2)" json:"lifetime_price,omitempty"`
+	IsFree                bool            `gorm:"default:true" json:"is_free"`
+	DNSSECEnabled         bool            `gorm:"column:dnssec_enabled;default:false" json:"dnssec_enabled"`
+	LuaRecordsEnabled     bool            `gorm:"column:lua_records_enabled;default:false" json:"lua_records_enabled"` // 需要 PowerDNS 开启 enable-lua-records
+	DNSPolicy             DNSPolicy       `gorm:"embedded" json:"dns_policy"`
+	Lifecycle             DomainLifecycle `gorm:"embedded" json:"lifecycle"` // 过期后的宽限期、赎回期和待删除期
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
 }
 
 // TableName 指定表名
@@ -38,11 +44,16 @@ type Domain struct {
 	UserID                uint           `gorm:"not null;index" json:"user_id"`
 	RootDomainID          uint           `gorm:"not null;index" json:"root_domain_id"`
 	Subdomain             string         `gorm:"size:63;not null" json:"subdomain"`
-	FullDomain            string         `gorm:"size:255;not null;uniqueIndex" json:"full_domain"`
-	Status                string         `gorm:"size:20;default:active" json:"status"` // active/expired/suspended/deleted
+	FullDomain            string         `gorm:"size:255;not null;uniqueIndex:idx_domains_full_domain_live,where:deleted_at IS NULL" json:"full_domain"`
+	SubdomainUnicode      string         `gorm:"size:255;not null" json:"subdomain_unicode"`   // 国际化域名的显示形式，Subdomain 为 punycode
+	FullDomainUnicode     string         `gorm:"size:255;not null" json:"full_domain_unicode"` // 国际化域名的显示形式，FullDomain 为 punycode
+	Status                string         `gorm:"size:20;default:active" json:"status"`         // active/grace/redemption/pending_delete/suspended
 	RegisteredAt          time.Time      `gorm:"not null" json:"registered_at"`
 	ExpiresAt             time.Time      `gorm:"not null" json:"expires_at"`
 	AutoRenew             bool           `gorm:"default:false" json:"auto_renew"`
+	AutoRenewFailures     int            `gorm:"default:0" json:"-"` // 当前续费周期内连续失败次数
+	AutoRenewAttemptedAt  *time.Time     `json:"auto_renew_attempted_at,omitempty"`
+	AutoRenewError        *string        `gorm:"type:text" json:"auto_renew_error,omitempty"`
 	Nameservers           string         `gorm:"type:text" json:"nameservers"`
 	UseDefaultNameservers bool           `gorm:"default:true" json:"use_default_nameservers"`
 	ReminderSent30d       bool           `gorm:"column:reminder_sent_30d;default:false" json:"-"`
@@ -63,35 +74,77 @@ func (Domain) TableName() string {
 	return "domains"
 }
 
+// BeforeCreate 未设置显示形式时由 punycode 转换得到
+func (d *Domain) BeforeCreate(tx *gorm.DB) error {
+	if d.SubdomainUnicode == "" {
+		d.SubdomainUnicode = idn.ToUnicode(d.Subdomain)
+	}
+	if d.FullDomainUnicode == "" {
+		d.FullDomainUnicode = idn.ToUnicode(d.FullDomain)
+	}
+	return nil
+}
+
 // DomainSearchRequest 域名搜索请求
 type DomainSearchRequest struct {
-	Subdomain    string `form:"subdomain" binding:"required,min=3,max=63"`
+	Subdomain    string `form:"subdomain" binding:"required,max=63"` // 可以是 Unicode，长度限制由根域名决定
 	RootDomainID uint   `form:"root_domain_id" binding:"required"`
 }
 
 // DomainRegisterRequest 域名注册请求
 type DomainRegisterRequest struct {
-	Subdomain    string  `json:"subdomain" binding:"required,min=3,max=63"`
+	Subdomain    string  `json:"subdomain" binding:"required,max=63"` // 可以是 Unicode，长度限制由根域名决定
 	RootDomainID uint    `json:"root_domain_id" binding:"required"`
 	CouponCode   *string `json:"coupon_code,omitempty"`
 }
 
+// DomainSuggestRequest 域名推荐请求
+type DomainSuggestRequest struct {
+	Keyword string `form:"keyword" binding:"required,max=63"` // 可以是 Unicode
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// DomainSuggestion 推荐结果中的候选域名，溢价名称返回溢价价格
+type DomainSuggestion struct {
+	Subdomain         string   `json:"subdomain"`
+	SubdomainUnicode  string   `json:"subdomain_unicode"`
+	FullDomain        string   `json:"full_domain"`
+	FullDomainUnicode string   `json:"full_domain_unicode"`
+	RootDomainID      uint     `json:"root_domain_id"`
+	RootDomain        string   `json:"root_domain"`
+	Available         bool     `json:"available"`
+	Reserved          bool     `json:"reserved,omitempty"`
+	Premium           bool     `json:"premium,omitempty"`
+	IsFree            bool     `json:"is_free"`
+	PricePerYear      *float64 `json:"price_per_year"`
+	LifetimePrice     *float64 `json:"lifetime_price"`
+}
+
+// DomainSettingsRequest 修改域名设置请求
+type DomainSettingsRequest struct {
+	AutoRenew *bool `json:"auto_renew" binding:"required"`
+}
+
 // DomainResponse 域名响应
 type DomainResponse struct {
-	ID                    uint          `json:"id"`
-	UserID                uint          `json:"user_id"`
-	RootDomainID          uint          `json:"root_domain_id"`
-	Subdomain             string        `json:"subdomain"`
-	FullDomain            string        `json:"full_domain"`
-	Status                string        `json:"status"`
-	RegisteredAt          time.Time     `json:"registered_at"`
-	ExpiresAt             time.Time     `json:"expires_at"`
-	AutoRenew             bool          `json:"auto_renew"`
-	Nameservers           string        `json:"nameservers"`
-	UseDefaultNameservers bool          `json:"use_default_nameservers"`
-	DNSSynced             bool          `json:"dns_synced"`
-	RootDomain            *RootDomain   `json:"root_domain,omitempty"`
-	User                  *UserResponse `json:"user,omitempty"`
+	ID                    uint                 `json:"id"`
+	UserID                uint                 `json:"user_id"`
+	RootDomainID          uint                 `json:"root_domain_id"`
+	Subdomain             string               `json:"subdomain"`
+	FullDomain            string               `json:"full_domain"`
+	SubdomainUnicode      string               `json:"subdomain_unicode"`
+	FullDomainUnicode     string               `json:"full_domain_unicode"`
+	Status                string               `json:"status"`
+	RegisteredAt          time.Time            `json:"registered_at"`
+	ExpiresAt             time.Time            `json:"expires_at"`
+	AutoRenew             bool                 `json:"auto_renew"`
+	AutoRenewError        *string              `json:"auto_renew_error,omitempty"`
+	Nameservers           string               `json:"nameservers"`
+	UseDefaultNameservers bool                 `json:"use_default_nameservers"`
+	DNSSynced             bool                 `json:"dns_synced"`
+	Lifecycle             *DomainLifecycleInfo `json:"lifecycle,omitempty"`
+	RootDomain            *RootDomain          `json:"root_domain,omitempty"`
+	User                  *UserResponse        `json:"user,omitempty"`
 }
 
 // ToResponse 转换为响应格式
@@ -102,15 +155,23 @@ func (d *Domain) ToResponse() *DomainResponse {
 		RootDomainID:          d.RootDomainID,
 		Subdomain:             d.Subdomain,
 		FullDomain:            d.FullDomain,
+		SubdomainUnicode:      d.SubdomainUnicode,
+		FullDomainUnicode:     d.FullDomainUnicode,
 		Status:                d.Status,
 		RegisteredAt:          d.RegisteredAt,
 		ExpiresAt:             d.ExpiresAt,
 		AutoRenew:             d.AutoRenew,
+		AutoRenewError:        d.AutoRenewError,
 		Nameservers:           d.Nameservers,
 		UseDefaultNameservers: d.UseDefaultNameservers,
 		DNSSynced:             d.DNSSynced,
+		Lifecycle:             d.LifecycleInfo(),
 		RootDomain:            d.RootDomain,
 	}
+	if resp.FullDomainUnicode == "" {
+		resp.SubdomainUnicode = idn.ToUnicode(d.Subdomain)
+		resp.FullDomainUnicode = idn.ToUnicode(d.FullDomain)
+	}
 	if d.User != nil {
 		resp.User = d.User.ToResponse()
 	}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DomainDSRecord 使用自定义 NS 的域名提交的 DS 记录，发布在根域名 zone 中
+type DomainDSRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	DomainID   uint      `json:"domain_id" gorm:"not null;index"`
+	KeyTag     int       `json:"key_tag" gorm:"not null"`
+	Algorithm  int       `json:"algorithm" gorm:"not null"`
+	DigestType int       `json:"digest_type" gorm:"not null"`
+	Digest     string    `json:"digest" gorm:"size:128;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (DomainDSRecord) TableName() string {
+	return "domain_ds_records"
+}
+
+// Content 返回 DS 记录的 presentation 格式
+func (r *DomainDSRecord) Content() string {
+	return fmt.Sprintf("%d %d %d %s", r.KeyTag, r.Algorithm, r.DigestType, strings.ToUpper(r.Digest))
+}
+
+// DomainDSRecordCreateRequest 提交 DS 记录请求
+type DomainDSRecordCreateRequest struct {
+	KeyTag     int    `json:"key_tag" binding:"min=0,max=65535"`
+	Algorithm  int    `json:"algorithm" binding:"required"`
+	DigestType int    `json:"digest_type" binding:"required"`
+	Digest     string `json:"digest" binding:"required"`
+}
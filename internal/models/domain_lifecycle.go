@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// 域名过期后的生命周期状态：active -> grace -> redemption -> pending_delete -> 释放（软删除）
+const (
+	DomainStatusActive        = "active"
+	DomainStatusGrace         = "grace"          // 宽限期：解析正常，可按原价续费
+	DomainStatusRedemption    = "redemption"     // 赎回期：解析停用，续费需额外支付赎回费
+	DomainStatusPendingDelete = "pending_delete" // 待删除：不可续费，期满后释放
+	DomainStatusReleased      = "released"       // 已释放：域名被删除，可重新注册（不写入数据库）
+)
+
+// DomainLifecycle 根域名的过期生命周期设置
+type DomainLifecycle struct {
+	GraceDays         int     `gorm:"column:grace_period_days" json:"grace_days"`
+	RedemptionDays    int     `gorm:"column:redemption_period_days" json:"redemption_days"`
+	PendingDeleteDays int     `gorm:"column:pending_delete_days" json:"pending_delete_days"`
+	RedemptionFee     float64 `gorm:"column:redemption_fee;type:decimal(10,2)" json:"redemption_fee"` // 赎回期续费在续费价格之外加收
+}
+
+// DefaultDomainLifecycle 新建根域名时使用的默认设置，过期 30 天后释放
+func DefaultDomainLifecycle() DomainLifecycle {
+	return DomainLifecycle{
+		GraceDays:         15,
+		RedemptionDays:    10,
+		PendingDeleteDays: 5,
+	}
+}
+
+// GraceEndsAt 宽限期结束时间
+func (l *DomainLifecycle) GraceEndsAt(expiresAt time.Time) time.Time {
+	return expiresAt.AddDate(0, 0, l.GraceDays)
+}
+
+// RedemptionEndsAt 赎回期结束时间
+func (l *DomainLifecycle) RedemptionEndsAt(expiresAt time.Time) time.Time {
+	return l.GraceEndsAt(expiresAt).AddDate(0, 0, l.RedemptionDays)
+}
+
+// ReleaseAt 域名被释放的时间
+func (l *DomainLifecycle) ReleaseAt(expiresAt time.Time) time.Time {
+	return l.RedemptionEndsAt(expiresAt).AddDate(0, 0, l.PendingDeleteDays)
+}
+
+// StageAt 返回到期时间为 expiresAt 的域名在 now 时应处于的状态
+func (l *DomainLifecycle) StageAt(expiresAt, now time.Time) string {
+	switch {
+	case now.Before(expiresAt):
+		return DomainStatusActive
+	case now.Before(l.GraceEndsAt(expiresAt)):
+		return DomainStatusGrace
+	case now.Before(l.RedemptionEndsAt(expiresAt)):
+		return DomainStatusRedemption
+	case now.Before(l.ReleaseAt(expiresAt)):
+		return DomainStatusPendingDelete
+	default:
+		return DomainStatusReleased
+	}
+}
+
+// IsLifecycleStatus 判断状态是否属于过期生命周期（宽限期、赎回期、待删除）
+func IsLifecycleStatus(status string) bool {
+	return status == DomainStatusGrace || status == DomainStatusRedemption || status == DomainStatusPendingDelete
+}
+
+// DNSDisabledStatus 判断该生命周期状态下解析是否停用
+func DNSDisabledStatus(status string) bool {
+	return status == DomainStatusRedemption || status == DomainStatusPendingDelete
+}
+
+// DomainLifecycleInfo 域名生命周期的时间节点，随域名响应返回
+type DomainLifecycleInfo struct {
+	GraceEndsAt      time.Time `json:"grace_ends_at"`
+	RedemptionEndsAt time.Time `json:"redemption_ends_at"`
+	ReleaseAt        time.Time `json:"release_at"`
+	Renewable        bool      `json:"renewable"`
+	RedemptionFee    float64   `json:"redemption_fee,omitempty"` // 当前续费需额外支付的赎回费
+}
+
+// LifecycleInfo 计算域名的生命周期时间节点，未加载根域名时返回 nil
+func (d *Domain) LifecycleInfo() *DomainLifecycleInfo {
+	if d.RootDomain == nil {
+		return nil
+	}
+	lifecycle := d.RootDomain.Lifecycle
+	info := &DomainLifecycleInfo{
+		GraceEndsAt:      lifecycle.GraceEndsAt(d.ExpiresAt),
+		RedemptionEndsAt: lifecycle.RedemptionEndsAt(d.ExpiresAt),
+		ReleaseAt:        lifecycle.ReleaseAt(d.ExpiresAt),
+		Renewable:        d.Status != "suspended" && d.Status != DomainStatusPendingDelete,
+	}
+	if d.Status == DomainStatusRedemption {
+		info.RedemptionFee = lifecycle.RedemptionFee
+	}
+	return info
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// DomainToken 域名级别的 API 令牌，用于 DynDNS 等无需登录的自动化场景
+// 只保存令牌的 SHA-256 哈希，明文仅在创建时返回一次
+type DomainToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	DomainID    uint       `json:"domain_id" gorm:"not null;index"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Scope       string     `json:"scope" gorm:"size:20;not null"` // dyndns/acme
+	TokenHash   string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	TokenPrefix string     `json:"token_prefix" gorm:"size:12;not null"` // 明文前几位，便于用户识别
+	Description string     `json:"description" gorm:"size:255"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty" gorm:"size:45"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Domain *Domain `json:"domain,omitempty" gorm:"foreignKey:DomainID"`
+}
+
+// TableName 指定表名
+func (DomainToken) TableName() string {
+	return "domain_tokens"
+}
+
+// DomainTokenCreateRequest 创建域名令牌请求
+type DomainTokenCreateRequest struct {
+	Scope       string `json:"scope" binding:"required,oneof=dyndns acme"`
+	Description string `json:"description" binding:"max=255"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// 域名转移状态
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusDeclined  = "declined"
+	TransferStatusCancelled = "cancelled"
+	TransferStatusExpired   = "expired"
+)
+
+// DomainTransfer 站内域名转移请求，接收方确认后才会变更所有者
+type DomainTransfer struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	DomainID    uint       `json:"domain_id" gorm:"not null;index"`
+	FromUserID  uint       `json:"from_user_id" gorm:"not null;index"`
+	ToUserID    uint       `json:"to_user_id" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"size:20;not null"` // pending/accepted/declined/cancelled/expired
+	Message     string     `json:"message" gorm:"size:500"`        // 发起方留言
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Domain   *Domain `json:"-" gorm:"foreignKey:DomainID"`
+	FromUser *User   `json:"-" gorm:"foreignKey:FromUserID"`
+	ToUser   *User   `json:"-" gorm:"foreignKey:ToUserID"`
+}
+
+// TableName 指定表名
+func (DomainTransfer) TableName() string {
+	return "domain_transfers"
+}
+
+// DomainTransferRequest 发起域名转移请求
+type DomainTransferRequest struct {
+	Target  string `json:"target" binding:"required"` // Email or username
+	Message string `json:"message" binding:"max=500"`
+}
+
+// DomainTransferResponse 域名转移响应，只暴露双方的用户名
+type DomainTransferResponse struct {
+	ID           uint       `json:"id"`
+	DomainID     uint       `json:"domain_id"`
+	DomainName   string     `json:"domain_name"`
+	FromUsername string     `json:"from_username"`
+	ToUsername   string     `json:"to_username"`
+	Direction    string     `json:"direction,omitempty"` // incoming/outgoing，相对当前用户
+	Status       string     `json:"status"`
+	Message      string     `json:"message"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// ToResponse 转换为响应格式，userID 为当前用户，用于确定方向
+func (t *DomainTransfer) ToResponse(userID uint) *DomainTransferResponse {
+	resp := &DomainTransferResponse{
+		ID:          t.ID,
+		DomainID:    t.DomainID,
+		Status:      t.Status,
+		Message:     t.Message,
+		ExpiresAt:   t.ExpiresAt,
+		RespondedAt: t.RespondedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+	if t.Domain != nil {
+		resp.DomainName = t.Domain.FullDomain
+	}
+	if t.FromUser != nil {
+		resp.FromUsername = t.FromUser.Username
+	}
+	if t.ToUser != nil {
+		resp.ToUsername = t.ToUser.Username
+	}
+	switch userID {
+	case t.FromUserID:
+		resp.Direction = "outgoing"
+	case t.ToUserID:
+		resp.Direction = "incoming"
+	}
+	return resp
+}
+
+// DomainHistory 用户视角的域名历史事件，转移时发起方和接收方各记录一条
+type DomainHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	DomainID   uint      `json:"domain_id" gorm:"not null;index"`
+	DomainName string    `json:"domain_name" gorm:"size:255;not null"` // 域名删除后仍可显示
+	Action     string    `json:"action" gorm:"size:50;not null"`       // transfer_requested/transfer_received/transfer_out/transfer_in/...
+	TransferID *uint     `json:"transfer_id,omitempty"`
+	Details    string    `json:"details" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (DomainHistory) TableName() string {
+	return "domain_histories"
+}
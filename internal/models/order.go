@@ -53,7 +53,7 @@ func (Order) TableName() string {
 
 // OrderCreateRequest 创建订单请求
 type OrderCreateRequest struct {
-	Subdomain    string  `json:"subdomain" binding:"required,min=3,max=63"`
+	Subdomain    string  `json:"subdomain" binding:"required,max=63"` // 可以是 Unicode，长度限制由根域名决定
 	RootDomainID uint    `json:"root_domain_id" binding:"required"`
 	Years        int     `json:"years" binding:"omitempty,min=0,max=10"`
 	IsLifetime   bool    `json:"is_lifetime"`
@@ -62,6 +62,7 @@ type OrderCreateRequest struct {
 
 // OrderCalculateRequest 计算价格请求
 type OrderCalculateRequest struct {
+	Subdomain    string  `json:"subdomain" binding:"omitempty,max=63"` // 提供时按溢价名称价格计算
 	RootDomainID uint    `json:"root_domain_id" binding:"required"`
 	Years        int     `json:"years" binding:"omitempty,min=0,max=10"`
 	IsLifetime   bool    `json:"is_lifetime"`
@@ -95,6 +96,7 @@ type PriceCalculationResponse struct {
 	CouponCode     *string `json:"coupon_code,omitempty"`
 	CouponType     *string `json:"coupon_type,omitempty"`
 	CouponError    *string `json:"coupon_error,omitempty"` // 优惠券验证失败的详细原因
+	Premium        bool    `json:"premium,omitempty"`      // 是否按溢价名称价格计算
 }
 
 // ToResponse 转换为响应格式
@@ -0,0 +1,94 @@
+package models
+
+import "time"
+
+// RDAP (RFC 9083) 响应结构
+
+// RDAPLink 链接
+type RDAPLink struct {
+	Value string `json:"value,omitempty"`
+	Rel   string `json:"rel"`
+	Href  string `json:"href"`
+	Type  string `json:"type,omitempty"`
+}
+
+// RDAPNotice 通知和备注
+type RDAPNotice struct {
+	Title       string     `json:"title,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Description []string   `json:"description"`
+	Links       []RDAPLink `json:"links,omitempty"`
+}
+
+// RDAPEvent 事件（注册、到期、最后修改等）
+type RDAPEvent struct {
+	EventAction string    `json:"eventAction"`
+	EventActor  string    `json:"eventActor,omitempty"`
+	EventDate   time.Time `json:"eventDate"`
+}
+
+// RDAPNameserver 域名服务器
+type RDAPNameserver struct {
+	ObjectClassName string `json:"objectClassName"`
+	LDHName         string `json:"ldhName"`
+}
+
+// RDAPEntity 联系人实体（注册人、注册服务商、滥用联系人）
+type RDAPEntity struct {
+	ObjectClassName string         `json:"objectClassName"`
+	Handle          string         `json:"handle,omitempty"`
+	Roles           []string       `json:"roles,omitempty"`
+	VCardArray      []interface{}  `json:"vcardArray,omitempty"`
+	Entities        []RDAPEntity   `json:"entities,omitempty"`
+	Remarks         []RDAPNotice   `json:"remarks,omitempty"`
+	Events          []RDAPEvent    `json:"events,omitempty"`
+	Links           []RDAPLink     `json:"links,omitempty"`
+	Redacted        []RDAPRedacted `json:"redacted,omitempty"`
+	RDAPConformance []string       `json:"rdapConformance,omitempty"`
+	Notices         []RDAPNotice   `json:"notices,omitempty"`
+}
+
+// RDAPDomain 域名对象
+type RDAPDomain struct {
+	ObjectClassName string           `json:"objectClassName"`
+	Handle          string           `json:"handle"`
+	LDHName         string           `json:"ldhName"`
+	UnicodeName     string           `json:"unicodeName,omitempty"` // 国际化域名的 Unicode 形式
+	Status          []string         `json:"status"`
+	Events          []RDAPEvent      `json:"events"`
+	Nameservers     []RDAPNameserver `json:"nameservers,omitempty"`
+	Entities        []RDAPEntity     `json:"entities,omitempty"`
+	Links           []RDAPLink       `json:"links,omitempty"`
+	Redacted        []RDAPRedacted   `json:"redacted,omitempty"`
+	RDAPConformance []string         `json:"rdapConformance"`
+	Notices         []RDAPNotice     `json:"notices,omitempty"`
+}
+
+// RDAPRedacted 被隐藏字段的说明（RFC 9537）
+type RDAPRedacted struct {
+	Name     RDAPRedactedName `json:"name"`
+	PrePath  string           `json:"prePath,omitempty"`  // removal 方式使用
+	PostPath string           `json:"postPath,omitempty"` // emptyValue 方式使用
+	Method   string           `json:"method"`
+	Reason   RDAPRedactedName `json:"reason"`
+}
+
+// RDAPRedactedName 被隐藏字段或原因的描述
+type RDAPRedactedName struct {
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// RDAPHelp 帮助响应
+type RDAPHelp struct {
+	RDAPConformance []string     `json:"rdapConformance"`
+	Notices         []RDAPNotice `json:"notices"`
+}
+
+// RDAPError 错误响应
+type RDAPError struct {
+	RDAPConformance []string `json:"rdapConformance"`
+	ErrorCode       int      `json:"errorCode"`
+	Title           string   `json:"title"`
+	Description     []string `json:"description,omitempty"`
+}
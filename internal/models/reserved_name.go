@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// 保留名称规则的匹配方式
+const (
+	ReservedMatchExact  = "exact"  // 与子域名完全相同
+	ReservedMatchPrefix = "prefix" // 子域名以该前缀开头
+	ReservedMatchRegex  = "regex"  // 正则表达式匹配整个子域名
+)
+
+// 保留名称规则的级别
+const (
+	ReservedTierReserved = "reserved" // 禁止注册
+	ReservedTierPremium  = "premium"  // 可按自定义价格购买
+)
+
+// ReservedName 管理员维护的保留名称和溢价名称规则，RootDomainID 为空时对所有根域名生效
+type ReservedName struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	Pattern       string      `gorm:"size:255;not null" json:"pattern"`
+	MatchType     string      `gorm:"size:16;not null" json:"match_type"`
+	RootDomainID  *uint       `gorm:"index" json:"root_domain_id"`
+	RootDomain    *RootDomain `gorm:"foreignKey:RootDomainID" json:"root_domain,omitempty"`
+	Tier          string      `gorm:"size:16;not null" json:"tier"`
+	Reason        string      `gorm:"size:32;not null" json:"reason"` // trademark, brand, system, premium
+	PricePerYear  *float64    `gorm:"type:decimal(10,2)" json:"price_per_year"`
+	LifetimePrice *float64    `gorm:"type:decimal(10,2)" json:"lifetime_price"`
+	Note          string      `gorm:"type:text" json:"note"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ReservedName) TableName() string {
+	return "reserved_names"
+}
+
+// IsPremium 是否为可购买的溢价名称
+func (r *ReservedName) IsPremium() bool {
+	return r.Tier == ReservedTierPremium
+}
+
+// ReservedNameCreateRequest 创建规则请求
+type ReservedNameCreateRequest struct {
+	Pattern       string   `json:"pattern" binding:"required,max=255"`
+	MatchType     string   `json:"match_type" binding:"omitempty,oneof=exact prefix regex"`
+	RootDomainID  *uint    `json:"root_domain_id"`
+	Tier          string   `json:"tier" binding:"omitempty,oneof=reserved premium"`
+	Reason        string   `json:"reason" binding:"omitempty,oneof=trademark brand system premium"`
+	PricePerYear  *float64 `json:"price_per_year"`
+	LifetimePrice *float64 `json:"lifetime_price"`
+	Note          string   `json:"note"`
+}
+
+// ReservedNameUpdateRequest 更新规则请求，root_domain_id 为 0 表示改为全局规则
+type ReservedNameUpdateRequest struct {
+	Pattern       *string  `json:"pattern" binding:"omitempty,max=255"`
+	MatchType     *string  `json:"match_type" binding:"omitempty,oneof=exact prefix regex"`
+	RootDomainID  *uint    `json:"root_domain_id"`
+	Tier          *string  `json:"tier" binding:"omitempty,oneof=reserved premium"`
+	Reason        *string  `json:"reason" binding:"omitempty,oneof=trademark brand system premium"`
+	PricePerYear  *float64 `json:"price_per_year"`
+	LifetimePrice *float64 `json:"lifetime_price"`
+	Note          *string  `json:"note"`
+}
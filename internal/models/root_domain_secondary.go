@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// RootDomainSecondary 根域名 zone 的二级 DNS 服务器（隐藏主服务器模式）
+// 通过 AXFR 从 PowerDNS 拉取 zone，PowerDNS 在 zone 变更时向其发送 NOTIFY
+type RootDomainSecondary struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	RootDomainID uint      `gorm:"not null;index" json:"root_domain_id"`
+	Name         string    `gorm:"size:100;not null" json:"name"`
+	Address      string    `gorm:"size:64;not null" json:"address"`
+	Port         int       `gorm:"not null" json:"port"`
+	TSIGKeyName  string    `gorm:"column:tsig_key_name;size:255" json:"tsig_key_name"` // 设置后只允许使用该密钥签名的 AXFR
+	Notify       bool      `json:"notify"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RootDomainSecondary) TableName() string {
+	return "root_domain_secondaries"
+}
+
+// RootDomainSecondaryRequest 添加或修改二级 DNS 服务器请求
+type RootDomainSecondaryRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Address     string `json:"address" binding:"required,ip"`
+	Port        int    `json:"port" binding:"omitempty,min=1,max=65535"`
+	TSIGKeyName string `json:"tsig_key_name" binding:"max=255"`
+	Notify      *bool  `json:"notify"`
+}
+
+// TSIGKeyCreateRequest 创建 TSIG 密钥请求，key 为空时自动生成
+type TSIGKeyCreateRequest struct {
+	Name      string `json:"name" binding:"required,max=255"`
+	Algorithm string `json:"algorithm" binding:"omitempty,oneof=hmac-sha1 hmac-sha224 hmac-sha256 hmac-sha384 hmac-sha512"`
+	Key       string `json:"key" binding:"omitempty,base64"`
+}
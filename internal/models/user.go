@@ -23,11 +23,13 @@ type User struct {
 	IsAdmin       bool           `gorm:"default:false" json:"is_admin"`
 	UserLevel     string         `gorm:"size:20;default:normal" json:"user_level"` // normal/basic/member/regular/leader
 	DomainQuota      int            `gorm:"default:2" json:"domain_quota"`
+	Balance          float64        `gorm:"type:decimal(10,2);default:0" json:"balance"` // 账户余额，用于自动续费
 	InviteCode       string         `gorm:"size:20;not null;uniqueIndex" json:"invite_code"`
 	InvitedBy        *uint          `json:"invited_by,omitempty"`
 	TotalInvites     int            `gorm:"default:0" json:"total_invites"`
 	SuccessfulInvites int           `gorm:"default:0" json:"successful_invites"`
 	Status           string         `gorm:"size:20;default:active" json:"status"` // active/frozen/banned
+	Locale           string         `gorm:"size:10;default:zh-CN" json:"locale"` // 邮件通知使用的语言
 	LastLoginAt   *time.Time     `json:"last_login_at,omitempty"`
 	LastLoginIP   *string        `gorm:"size:45" json:"last_login_ip,omitempty"`
 	CreatedAt     time.Time      `json:"created_at"`
@@ -64,10 +66,12 @@ type UserResponse struct {
 	IsAdmin           bool       `json:"is_admin"`
 	UserLevel         string     `json:"user_level"`
 	DomainQuota       int        `json:"domain_quota"`
+	Balance           float64    `json:"balance"`
 	InviteCode        string     `json:"invite_code"`
 	TotalInvites      int        `json:"total_invites"`
 	SuccessfulInvites int        `json:"successful_invites"`
 	Status            string     `json:"status"`
+	Locale            string     `json:"locale"`
 	CreatedAt         time.Time  `json:"created_at"`
 	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`
 }
@@ -83,10 +87,12 @@ func (u *User) ToResponse() *UserResponse {
 		IsAdmin:           u.IsAdmin,
 		UserLevel:         u.UserLevel,
 		DomainQuota:       u.DomainQuota,
+		Balance:           u.Balance,
 		InviteCode:        u.InviteCode,
 		TotalInvites:      u.TotalInvites,
 		SuccessfulInvites: u.SuccessfulInvites,
 		Status:            u.Status,
+		Locale:            u.Locale,
 		CreatedAt:         u.CreatedAt,
 		LastLoginAt:       u.LastLoginAt,
 	}
@@ -0,0 +1,250 @@
+package redirect
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"gorm.io/gorm"
+
+	"opendomain/internal/config"
+	"opendomain/internal/models"
+	"opendomain/pkg/logger"
+)
+
+// cacheTTL 主机名查询结果的缓存时间，记录修改后最多延迟这么久生效
+const cacheTTL = time.Minute
+
+// frameTemplate frame 模式返回的页面，地址栏保持原域名
+var frameTemplate = template.Must(template.New("frame").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Host}}</title>
+<style>html,body{margin:0;padding:0;height:100%;overflow:hidden}iframe{border:0;width:100%;height:100%}</style>
+</head>
+<body>
+<iframe src="{{.Target}}" allowfullscreen></iframe>
+</body>
+</html>
+`))
+
+// Server 根据 URL 记录为用户域名提供 301/302 重定向和 frame 转发
+// HTTP 监听同时处理 ACME HTTP-01 验证，HTTPS 证书按需从 Let's Encrypt 申请
+type Server struct {
+	db  *gorm.DB
+	cfg *config.Config
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+
+	httpServer  *http.Server
+	httpsServer *http.Server
+}
+
+type cacheEntry struct {
+	target    *models.DNSRecordData // nil 表示该主机没有 URL 记录
+	expiresAt time.Time
+}
+
+// NewServer 创建重定向服务
+func NewServer(db *gorm.DB, cfg *config.Config) *Server {
+	return &Server{
+		db:    db,
+		cfg:   cfg,
+		cache: make(map[string]cacheEntry),
+	}
+}
+
+// Start 在后台启动 HTTP 和 HTTPS 监听
+func (s *Server) Start() {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(s.cfg.Redirect.CertCacheDir),
+		HostPolicy: s.hostPolicy,
+		Email:      s.cfg.Redirect.ACMEEmail,
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              s.cfg.Redirect.HTTPAddr,
+		Handler:           manager.HTTPHandler(s),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	s.httpsServer = &http.Server{
+		Addr:              s.cfg.Redirect.HTTPSAddr,
+		Handler:           s,
+		TLSConfig:         &tls.Config{GetCertificate: manager.GetCertificate, MinVersion: tls.VersionTLS12},
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	go func() {
+		logger.Infof("Redirect service listening on %s (HTTP)", s.cfg.Redirect.HTTPAddr)
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Redirect HTTP listener stopped: %v", err)
+		}
+	}()
+	go func() {
+		logger.Infof("Redirect service listening on %s (HTTPS)", s.cfg.Redirect.HTTPSAddr)
+		if err := s.httpsServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Redirect HTTPS listener stopped: %v", err)
+		}
+	}()
+}
+
+// Shutdown 优雅关闭两个监听
+func (s *Server) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, srv := range []*http.Server{s.httpServer, s.httpsServer} {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ServeHTTP 按 Host 查找 URL 记录并重定向
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := normalizeHost(r.Host)
+	target := s.lookup(host)
+	if target == nil {
+		http.Error(w, "No redirect is configured for this host", http.StatusNotFound)
+		return
+	}
+
+	location := target.URL
+	if target.PreservePath {
+		location = joinTargetPath(target.URL, r.URL)
+	}
+
+	switch target.Redirect {
+	case models.RedirectFrame:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := frameTemplate.Execute(w, struct{ Host, Target string }{host, location}); err != nil {
+			logger.Warnf("Failed to render redirect frame for %s: %v", host, err)
+		}
+	case models.RedirectTemporary:
+		http.Redirect(w, r, location, http.StatusFound)
+	default:
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+	}
+}
+
+// hostPolicy 只为存在活跃 URL 记录的主机申请证书
+func (s *Server) hostPolicy(_ context.Context, host string) error {
+	if s.lookup(normalizeHost(host)) == nil {
+		return fmt.Errorf("host %q has no URL record", host)
+	}
+	return nil
+}
+
+// lookup 查找主机对应的 URL 记录，结果缓存 cacheTTL
+func (s *Server) lookup(host string) *models.DNSRecordData {
+	if host == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	entry, ok := s.cache[host]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.target
+	}
+
+	target, err := s.findTarget(host)
+	if err != nil {
+		// 数据库错误不缓存，下次请求重新查询
+		logger.Warnf("Failed to look up redirect for %s: %v", host, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	s.cache[host] = cacheEntry{target: target, expiresAt: time.Now().Add(cacheTTL)}
+	s.mu.Unlock()
+	return target
+}
+
+// findTarget 找到主机所属的最具体的域名，再查找该名称下的 URL 记录
+func (s *Server) findTarget(host string) (*models.DNSRecordData, error) {
+	labels := strings.Split(host, ".")
+	candidates := make([]string, 0, len(labels))
+	for i := 0; i < len(labels)-1; i++ {
+		candidates = append(candidates, strings.Join(labels[i:], "."))
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var domains []models.Domain
+	if err := s.db.Where("full_domain IN ? AND status = ? AND use_default_nameservers = ?", candidates, "active", true).
+		Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, nil
+	}
+
+	domain := domains[0]
+	for _, d := range domains[1:] {
+		if len(d.FullDomain) > len(domain.FullDomain) {
+			domain = d
+		}
+	}
+
+	name := "@"
+	if host != domain.FullDomain {
+		name = strings.TrimSuffix(host, "."+domain.FullDomain)
+	}
+
+	var record models.DNSRecord
+	err := s.db.Where("domain_id = ? AND name = ? AND type = ? AND is_active = ?", domain.ID, name, "URL", true).
+		Order("id ASC").First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.ParseDNSRecordData(record.Type, record.Content), nil
+}
+
+// normalizeHost 去掉端口和结尾的点并转为小写
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// joinTargetPath 将请求路径和查询参数追加到目标地址
+// https://example.com/base + /a?b=1 → https://example.com/base/a?b=1
+func joinTargetPath(target string, req *url.URL) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	if req.Path != "" && req.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + req.Path
+		u.RawPath = ""
+	}
+	if req.RawQuery != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&" + req.RawQuery
+		} else {
+			u.RawQuery = req.RawQuery
+		}
+	}
+	return u.String()
+}
@@ -48,6 +48,7 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 		domainHandler := handler.NewDomainHandler(db, cfg)
 		dnsHandler := handler.NewDNSHandler(db, cfg)
 		couponHandler := handler.NewCouponHandler(db, cfg)
+		reservedNameHandler := handler.NewReservedNameHandler(db, cfg)
 		invitationHandler := handler.NewInvitationHandler(db, cfg)
 		announcementHandler := handler.NewAnnouncementHandler(db, cfg)
 		domainScanHandler := handler.NewDomainScanHandler(db, cfg)
@@ -77,12 +78,35 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 			public.GET("/pages/:slug", pageHandler.GetPublicPageBySlug)
 			// 待激活域名列表(公开)
 			public.GET("/pending-domains", fossBillingSyncHandler.GetPublicPendingDomains)
+			// 到期提醒邮件中的续期链接：GET 只跳转到前端确认页面，确认后 POST 令牌完成续期
+			public.GET("/domains/renew", domainHandler.RenewFromLink)
+			public.POST("/domains/renew", domainHandler.ConfirmRenewFromLink)
 		}
 
 		// 支付回调路由（公开，通过签名验证）
 		api.GET("/payments/callback", paymentHandler.HandleCallback)
 		api.GET("/payments/return", paymentHandler.HandleReturn)
 
+		// DynDNS2 动态 DNS 更新（公开，通过域名令牌验证）
+		r.GET("/nic/update", dnsHandler.DynDNSUpdate)
+		api.GET("/nic/update", dnsHandler.DynDNSUpdate)
+
+		// RDAP 查询（RFC 9083），挂在根路径下
+		rdapHandler := handler.NewRDAPHandler(db, cfg)
+		rdap := r.Group("/rdap")
+		{
+			rdap.GET("/domain/:name", rdapHandler.GetDomain)
+			rdap.GET("/entity/:handle", rdapHandler.GetEntity)
+			rdap.GET("/help", rdapHandler.Help)
+		}
+
+		// ACME DNS-01 验证（兼容 lego httpreq，通过域名令牌验证）
+		acme := api.Group("/acme")
+		{
+			acme.POST("/present", dnsHandler.ACMEPresent)
+			acme.POST("/cleanup", dnsHandler.ACMECleanup)
+		}
+
 		// 认证路由
 		auth := api.Group("/auth")
 		{
@@ -109,6 +133,7 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 				user.GET("/profile", userHandler.GetProfile)
 				user.PUT("/profile", userHandler.UpdateProfile)
 				user.PUT("/change-password", userHandler.ChangePassword)
+				user.GET("/balance", userHandler.GetBalance)
 				// FOSSBilling 同步
 				user.POST("/sync-from-fossbilling", fossBillingSyncHandler.SyncFromFOSSBilling)
 				user.GET("/sync-status", fossBillingSyncHandler.GetSyncStatus)
@@ -118,14 +143,29 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 			domains := protected.Group("/domains")
 			{
 				domains.GET("/search", domainHandler.SearchDomain)
+				domains.GET("/suggestions", domainHandler.SuggestDomains)
 				domains.POST("", domainHandler.RegisterDomain)
 				domains.GET("", domainHandler.ListMyDomains)
 				domains.GET("/:id", domainHandler.GetDomain)
+				domains.PATCH("/:id", domainHandler.UpdateDomainSettings)
 				domains.DELETE("/:id", domainHandler.DeleteDomain)
 				domains.PUT("/:id/nameservers", domainHandler.ModifyNameservers)
 				domains.POST("/:id/renew", domainHandler.RenewDomain)
 				domains.POST("/:id/transfer", domainHandler.TransferDomain)
+				domains.GET("/:id/ds-records", domainHandler.ListDSRecords)
+				domains.POST("/:id/ds-records", domainHandler.CreateDSRecord)
+				domains.DELETE("/:id/ds-records/:dsId", domainHandler.DeleteDSRecord)
+			}
+
+			// 域名转移
+			transfers := protected.Group("/domain-transfers")
+			{
+				transfers.GET("", domainHandler.ListDomainTransfers)
+				transfers.POST("/:id/accept", domainHandler.AcceptDomainTransfer)
+				transfers.POST("/:id/decline", domainHandler.DeclineDomainTransfer)
+				transfers.POST("/:id/cancel", domainHandler.CancelDomainTransfer)
 			}
+			protected.GET("/domain-history", domainHandler.ListDomainHistory)
 
 			// 域名扫描记录
 			protected.GET("/domain-scans/:id", domainScanHandler.GetDomainScanRecords)
@@ -141,6 +181,31 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 				dns.DELETE("/:recordId", dnsHandler.DeleteRecord)
 			}
 
+			// DNS 模板
+			protected.GET("/dns-templates", dnsHandler.ListDNSTemplates)
+
+			// DNS 区域管理
+			zone := protected.Group("/dns/:domainId")
+			{
+				zone.GET("/zonefile", dnsHandler.ExportZoneFile)
+				zone.POST("/zonefile", dnsHandler.ImportZoneFile)
+				zone.POST("/changes", dnsHandler.ApplyChanges)
+				zone.GET("/conflicts", dnsHandler.ListConflicts)
+				zone.POST("/apply-template", dnsHandler.ApplyTemplate)
+				zone.GET("/failover", dnsHandler.ListFailoverGroups)
+				zone.POST("/failover", dnsHandler.CreateFailoverGroup)
+				zone.GET("/failover/events", dnsHandler.ListFailoverEvents)
+				zone.GET("/failover/:groupId", dnsHandler.GetFailoverGroup)
+				zone.PUT("/failover/:groupId", dnsHandler.UpdateFailoverGroup)
+				zone.DELETE("/failover/:groupId", dnsHandler.DeleteFailoverGroup)
+				zone.GET("/history", dnsHandler.GetHistory)
+				zone.GET("/history/:version", dnsHandler.GetSnapshot)
+				zone.POST("/history/:version/restore", dnsHandler.RestoreSnapshot)
+				zone.GET("/tokens", dnsHandler.ListDomainTokens)
+				zone.POST("/tokens", dnsHandler.CreateDomainToken)
+				zone.DELETE("/tokens/:tokenId", dnsHandler.DeleteDomainToken)
+			}
+
 			// 优惠券
 			coupons := protected.Group("/coupons")
 			{
@@ -195,6 +260,7 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 			admin.GET("/users", userHandler.ListUsers)
 			admin.PUT("/users/:id", userHandler.AdminUpdateUser)
 			admin.PUT("/users/:id/status", userHandler.AdminUpdateUserStatus)
+			admin.POST("/users/:id/balance", userHandler.AdminAdjustBalance)
 			admin.DELETE("/users/:id", userHandler.AdminDeleteUser)
 			admin.GET("/domains", domainHandler.ListAllDomains)
 			admin.GET("/domains/stats", domainHandler.GetDomainStatusStats)
@@ -211,6 +277,36 @@ func Setup(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *gin.Engine {
 			admin.PUT("/root-domains/:id", domainHandler.UpdateRootDomain)
 			admin.DELETE("/root-domains/:id", domainHandler.DeleteRootDomain)
 			admin.GET("/root-domains/:id/domains", domainHandler.ListDomainsByRootDomain)
+			admin.GET("/root-domains/:id/dnssec", domainHandler.GetRootDomainDNSSEC)
+			admin.POST("/root-domains/:id/dnssec", domainHandler.EnableRootDomainDNSSEC)
+			admin.DELETE("/root-domains/:id/dnssec", domainHandler.DisableRootDomainDNSSEC)
+			admin.GET("/root-domains/:id/secondaries", domainHandler.GetRootDomainSecondaries)
+			admin.POST("/root-domains/:id/secondaries", domainHandler.AddRootDomainSecondary)
+			admin.PUT("/root-domains/:id/secondaries/:secondaryId", domainHandler.UpdateRootDomainSecondary)
+			admin.DELETE("/root-domains/:id/secondaries/:secondaryId", domainHandler.DeleteRootDomainSecondary)
+			admin.POST("/root-domains/:id/notify", domainHandler.NotifyRootDomainSecondaries)
+
+			// TSIG 密钥管理（二级 DNS 的 AXFR 认证）
+			admin.GET("/tsig-keys", domainHandler.ListTSIGKeys)
+			admin.POST("/tsig-keys", domainHandler.CreateTSIGKey)
+			admin.DELETE("/tsig-keys/:name", domainHandler.DeleteTSIGKey)
+
+			// DNS 对账
+			admin.GET("/dns/drift", dnsHandler.GetDNSDriftSummary)
+
+			// DNS 模板管理
+			admin.GET("/dns-templates", dnsHandler.AdminListDNSTemplates)
+			admin.POST("/dns-templates", dnsHandler.AdminCreateDNSTemplate)
+			admin.PUT("/dns-templates/:id", dnsHandler.AdminUpdateDNSTemplate)
+			admin.DELETE("/dns-templates/:id", dnsHandler.AdminDeleteDNSTemplate)
+
+			// 保留名称和溢价名称管理
+			admin.GET("/reserved-names", reservedNameHandler.ListReservedNames)
+			admin.POST("/reserved-names", reservedNameHandler.CreateReservedName)
+			admin.GET("/reserved-names/check", reservedNameHandler.CheckReservedName)
+			admin.POST("/reserved-names/import", reservedNameHandler.ImportReservedNames)
+			admin.PUT("/reserved-names/:id", reservedNameHandler.UpdateReservedName)
+			admin.DELETE("/reserved-names/:id", reservedNameHandler.DeleteReservedName)
 
 			// 优惠券管理
 			admin.GET("/coupons", couponHandler.ListCoupons)
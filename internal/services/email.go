@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+
+	"opendomain/internal/config"
+	"opendomain/pkg/timeutil"
+)
+
+type EmailService struct {
+	cfg *config.Config
+}
+
+func NewEmailService(cfg *config.Config) *EmailService {
+	return &EmailService{
+		cfg: cfg,
+	}
+}
+
+// Enabled reports whether SMTP is configured
+func (s *EmailService) Enabled() bool {
+	return s.cfg.Email.Host != "" && s.cfg.Email.From != ""
+}
+
+// Send sends a plain text email, it is a no-op when SMTP is not configured
+func (s *EmailService) Send(to, subject, body string) error {
+	if !s.Enabled() || to == "" {
+		return nil
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address %q", to)
+	}
+
+	port := s.cfg.Email.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := fmt.Sprintf("%s:%d", s.cfg.Email.Host, port)
+
+	var auth smtp.Auth
+	if s.cfg.Email.User != "" {
+		auth = smtp.PlainAuth("", s.cfg.Email.User, s.cfg.Email.Password, s.cfg.Email.Host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.Email.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", timeutil.Now().Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(addr, auth, s.cfg.Email.From, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
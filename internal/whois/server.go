@@ -0,0 +1,339 @@
+package whois
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"opendomain/internal/config"
+	"opendomain/internal/handler"
+	"opendomain/internal/models"
+	"opendomain/pkg/idn"
+	"opendomain/pkg/logger"
+	"opendomain/pkg/timeutil"
+)
+
+const (
+	// connTimeout 单个连接的读写超时
+	connTimeout = 10 * time.Second
+	// maxQueryLength 查询行的最大长度
+	maxQueryLength = 512
+	// maxConns 同时处理的最大连接数
+	maxConns = 100
+	// rateWindow 限流计数窗口
+	rateWindow = time.Minute
+)
+
+var queryPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Server WHOIS (RFC 3912) 查询服务，回答已启用根域名下的子域名查询
+// 按客户端 IP 在 Redis 中限流，注册人信息按 RDAP 配置隐藏
+type Server struct {
+	db  *gorm.DB
+	rdb *redis.Client
+	cfg *config.Config
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	sem      chan struct{}
+	closing  chan struct{}
+}
+
+// NewServer 创建 WHOIS 服务
+func NewServer(db *gorm.DB, rdb *redis.Client, cfg *config.Config) *Server {
+	return &Server{
+		db:      db,
+		rdb:     rdb,
+		cfg:     cfg,
+		sem:     make(chan struct{}, maxConns),
+		closing: make(chan struct{}),
+	}
+}
+
+// Start 在后台启动 TCP 监听
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.cfg.WHOIS.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		logger.Infof("WHOIS service listening on %s", s.cfg.WHOIS.Addr)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				select {
+				case <-s.closing:
+					return
+				default:
+				}
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					time.Sleep(100 * time.Millisecond)
+					continue
+				}
+				logger.Errorf("WHOIS listener stopped: %v", err)
+				return
+			}
+
+			select {
+			case s.sem <- struct{}{}:
+			default:
+				conn.Close() // 连接数已满
+				continue
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer func() { <-s.sem }()
+				s.handleConn(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Shutdown 停止监听并等待正在处理的查询完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.listener == nil {
+		return nil
+	}
+	close(s.closing)
+	err := s.listener.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handleConn 读取一行查询并写回结果后关闭连接
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connTimeout))
+
+	// 超长的查询行只读取前 maxQueryLength 字节，随后会被判定为无效查询
+	line, err := bufio.NewReaderSize(conn, maxQueryLength).ReadSlice('\n')
+	if err != nil && len(line) == 0 {
+		return
+	}
+
+	var response string
+	if s.allow(conn.RemoteAddr()) {
+		response = s.lookup(normalizeQuery(string(line)))
+	} else {
+		response = "% Query rate limit exceeded. Please try again later.\n"
+	}
+	conn.Write([]byte(strings.ReplaceAll(response, "\n", "\r\n")))
+}
+
+// allow 按客户端 IP 限流，Redis 不可用时放行
+func (s *Server) allow(addr net.Addr) bool {
+	if s.rdb == nil || s.cfg.WHOIS.RateLimit <= 0 {
+		return true
+	}
+	ip := addr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// 计数器创建时即带过期时间，并与 INCR 在同一事务中执行，计数不会永久残留
+	key := "whois:rate:" + ip
+	var incr *redis.IntCmd
+	if _, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, rateWindow)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	}); err != nil {
+		logger.Warnf("WHOIS rate limiter unavailable: %v", err)
+		return true
+	}
+	return incr.Val() <= int64(s.cfg.WHOIS.RateLimit)
+}
+
+// normalizeQuery 去掉首尾空白和末尾的点，转为小写
+func normalizeQuery(query string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(query)), ".")
+}
+
+// lookup 生成查询结果文本
+func (s *Server) lookup(query string) string {
+	if ascii, err := idn.ToASCII(query); err == nil {
+		query = ascii // 支持以 Unicode 形式查询国际化域名
+	}
+	if query == "" || len(query) > 253 || !queryPattern.MatchString(query) {
+		return s.withFooter("% Invalid query. Send a domain name followed by CRLF.\n")
+	}
+
+	var roots []models.RootDomain
+	if err := s.db.Where("is_active = ?", true).Find(&roots).Error; err != nil {
+		logger.Errorf("WHOIS failed to load root domains: %v", err)
+		return "% Internal error. Please try again later.\n"
+	}
+
+	// 匹配最长的根域名后缀，查询更深层级的名称时返回其所属的注册域名
+	var root *models.RootDomain
+	for i := range roots {
+		suffix := "." + strings.ToLower(roots[i].Domain)
+		if strings.HasSuffix(query, suffix) && (root == nil || len(roots[i].Domain) > len(root.Domain)) {
+			root = &roots[i]
+		}
+	}
+	if root == nil {
+		names := make([]string, 0, len(roots))
+		for _, r := range roots {
+			names = append(names, r.Domain)
+		}
+		return s.withFooter(fmt.Sprintf("%% This server only answers queries for names under: %s\n", strings.Join(names, ", ")))
+	}
+
+	labels := strings.Split(strings.TrimSuffix(query, "."+strings.ToLower(root.Domain)), ".")
+	subdomain := labels[len(labels)-1]
+	fullDomain := subdomain + "." + strings.ToLower(root.Domain)
+
+	var domain models.Domain
+	err := s.db.Preload("User").Where("full_domain = ?", fullDomain).First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.withFooter(s.formatUnregistered(subdomain, fullDomain, root.ID))
+	}
+	if err != nil {
+		logger.Errorf("WHOIS failed to query %s: %v", fullDomain, err)
+		return "% Internal error. Please try again later.\n"
+	}
+
+	return s.withFooter(s.formatDomain(&domain))
+}
+
+// formatUnregistered 未注册名称的说明，区分保留名称和溢价名称
+func (s *Server) formatUnregistered(subdomain, fullDomain string, rootDomainID uint) string {
+	rule, err := handler.FindReservedName(s.db, subdomain, rootDomainID)
+	if err != nil {
+		logger.Warnf("WHOIS failed to check reserved names for %s: %v", fullDomain, err)
+	}
+
+	switch {
+	case rule != nil && rule.IsPremium():
+		return fmt.Sprintf("No match for \"%s\".\n%% This is a premium name and may be available for purchase at a premium price.\n", strings.ToUpper(fullDomain))
+	case rule != nil:
+		return fmt.Sprintf("Domain Name: %s\nDomain Status: reserved\nReserved Reason: %s\n%% This name is reserved by the registry and is not available for registration.\n",
+			strings.ToUpper(fullDomain), rule.Reason)
+	default:
+		return fmt.Sprintf("No match for \"%s\".\n%% This name is not registered and may be available.\n", strings.ToUpper(fullDomain))
+	}
+}
+
+// formatDomain 已注册域名的详细信息
+func (s *Server) formatDomain(domain *models.Domain) string {
+	var pendingTransfers int64
+	s.db.Model(&models.DomainTransfer{}).
+		Where("domain_id = ? AND status = ?", domain.ID, models.TransferStatusPending).
+		Count(&pendingTransfers)
+
+	var b strings.Builder
+	field := func(name, value string) {
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+
+	field("Domain Name", strings.ToUpper(domain.FullDomain))
+	field("Registry Domain ID", fmt.Sprintf("D%d", domain.ID))
+	field("Registrar", s.cfg.SiteName)
+	field("Registrar URL", s.cfg.FrontendURL)
+	field("Updated Date", formatTime(domain.UpdatedAt))
+	field("Creation Date", formatTime(domain.RegisteredAt))
+	field("Registry Expiry Date", formatTime(domain.ExpiresAt))
+	if s.cfg.RDAP.AbuseEmail != "" {
+		field("Registrar Abuse Contact Email", s.cfg.RDAP.AbuseEmail)
+	}
+	for _, status := range eppStatus(domain.Status, pendingTransfers > 0) {
+		field("Domain Status", status)
+	}
+
+	name, email := "REDACTED FOR PRIVACY", "REDACTED FOR PRIVACY"
+	if !s.cfg.RDAP.RedactPersonalData && domain.User != nil {
+		name, email = domain.User.Username, domain.User.Email
+		if domain.User.RealName != nil && *domain.User.RealName != "" {
+			name = *domain.User.RealName
+		}
+	}
+	field("Registrant Name", name)
+	field("Registrant Email", email)
+
+	var nameservers []string
+	if err := json.Unmarshal([]byte(domain.Nameservers), &nameservers); err == nil {
+		for _, ns := range nameservers {
+			field("Name Server", strings.ToUpper(strings.TrimSuffix(ns, ".")))
+		}
+	}
+	var dsRecords int64
+	s.db.Model(&models.DomainDSRecord{}).Where("domain_id = ?", domain.ID).Count(&dsRecords)
+	if dsRecords > 0 {
+		field("DNSSEC", "signedDelegation")
+	} else {
+		field("DNSSEC", "unsigned")
+	}
+	if s.cfg.RDAP.BaseURL != "" {
+		field("RDAP URL", strings.TrimSuffix(s.cfg.RDAP.BaseURL, "/")+"/domain/"+domain.FullDomain)
+	}
+	return b.String()
+}
+
+// withFooter 附加数据库更新时间和使用条款
+func (s *Server) withFooter(body string) string {
+	return fmt.Sprintf("%s>>> Last update of WHOIS database: %s <<<\n\n"+
+		"%% This data is provided by %s for information purposes only.\n"+
+		"%% It may not be used for unsolicited advertising or automated bulk collection.\n",
+		body, formatTime(timeutil.Now()), s.cfg.SiteName)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// eppStatus 将域名状态映射为 EPP 状态码（RFC 5731 / RFC 3915）
+func eppStatus(status string, pendingTransfer bool) []string {
+	var result []string
+	switch status {
+	case models.DomainStatusActive:
+		result = []string{"ok"}
+	case models.DomainStatusGrace:
+		result = []string{"autoRenewPeriod"}
+	case models.DomainStatusRedemption:
+		result = []string{"redemptionPeriod"}
+	case models.DomainStatusPendingDelete:
+		result = []string{"pendingDelete"}
+	case "suspended":
+		result = []string{"serverHold"}
+	default:
+		result = []string{"inactive"}
+	}
+	if pendingTransfer {
+		if status == models.DomainStatusActive {
+			return []string{"pendingTransfer"} // ok 不能与其他状态同时出现
+		}
+		result = append(result, "pendingTransfer")
+	}
+	return result
+}
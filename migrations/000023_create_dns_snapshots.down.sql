@@ -0,0 +1,2 @@
+-- Drop dns_snapshots table
+DROP TABLE IF EXISTS dns_snapshots;
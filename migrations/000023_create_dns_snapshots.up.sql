@@ -0,0 +1,13 @@
+-- Create dns_snapshots table
+CREATE TABLE IF NOT EXISTS dns_snapshots (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    records TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (domain_id, version)
+);
+
+-- Create indexes
+CREATE INDEX idx_dns_snapshots_domain_id ON dns_snapshots(domain_id);
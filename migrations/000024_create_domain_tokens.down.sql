@@ -0,0 +1,2 @@
+-- Drop domain_tokens table
+DROP TABLE IF EXISTS domain_tokens;
@@ -0,0 +1,17 @@
+-- Create domain_tokens table
+CREATE TABLE IF NOT EXISTS domain_tokens (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('dyndns')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(12) NOT NULL,
+    description VARCHAR(255),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_domain_tokens_domain_id ON domain_tokens(domain_id);
+CREATE INDEX idx_domain_tokens_user_id ON domain_tokens(user_id);
@@ -0,0 +1,7 @@
+-- Drop acme_challenges table
+DROP TABLE IF EXISTS acme_challenges;
+
+-- Restore domain_tokens scope constraint
+DELETE FROM domain_tokens WHERE scope = 'acme';
+ALTER TABLE domain_tokens DROP CONSTRAINT IF EXISTS domain_tokens_scope_check;
+ALTER TABLE domain_tokens ADD CONSTRAINT domain_tokens_scope_check CHECK (scope IN ('dyndns'));
@@ -0,0 +1,17 @@
+-- Allow acme scope for domain tokens
+ALTER TABLE domain_tokens DROP CONSTRAINT IF EXISTS domain_tokens_scope_check;
+ALTER TABLE domain_tokens ADD CONSTRAINT domain_tokens_scope_check CHECK (scope IN ('dyndns', 'acme'));
+
+-- Create acme_challenges table
+CREATE TABLE IF NOT EXISTS acme_challenges (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_acme_challenges_domain_id ON acme_challenges(domain_id);
+CREATE INDEX idx_acme_challenges_expires_at ON acme_challenges(expires_at);
@@ -0,0 +1,5 @@
+-- Drop domain_ds_records table
+DROP TABLE IF EXISTS domain_ds_records;
+
+-- Remove DNSSEC flag from root_domains
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dnssec_enabled;
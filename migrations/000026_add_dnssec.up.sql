@@ -0,0 +1,17 @@
+-- Add DNSSEC flag to root_domains
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dnssec_enabled BOOLEAN DEFAULT FALSE;
+
+-- Create domain_ds_records table
+CREATE TABLE IF NOT EXISTS domain_ds_records (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    key_tag INTEGER NOT NULL CHECK (key_tag BETWEEN 0 AND 65535),
+    algorithm INTEGER NOT NULL,
+    digest_type INTEGER NOT NULL,
+    digest VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (domain_id, key_tag, algorithm, digest_type, digest)
+);
+
+-- Create indexes
+CREATE INDEX idx_domain_ds_records_domain_id ON domain_ds_records(domain_id);
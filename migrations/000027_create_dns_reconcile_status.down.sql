@@ -0,0 +1,2 @@
+-- Drop dns_reconcile_status table
+DROP TABLE IF EXISTS dns_reconcile_status;
@@ -0,0 +1,15 @@
+-- Create dns_reconcile_status table
+CREATE TABLE IF NOT EXISTS dns_reconcile_status (
+    domain_id INTEGER PRIMARY KEY REFERENCES domains(id) ON DELETE CASCADE,
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    consecutive_failures INTEGER DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    drift_count INTEGER DEFAULT 0,
+    drift TEXT,
+    last_error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_dns_reconcile_status_next_attempt_at ON dns_reconcile_status(next_attempt_at);
@@ -0,0 +1,5 @@
+-- Restore dns_records type constraint
+DELETE FROM dns_records WHERE type IN ('HTTPS', 'SVCB', 'TLSA', 'SSHFP', 'DS', 'NAPTR');
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_type_check;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_type_check
+    CHECK (type IN ('A', 'AAAA', 'CNAME', 'MX', 'TXT', 'NS', 'SRV', 'CAA'));
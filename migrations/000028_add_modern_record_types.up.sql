@@ -0,0 +1,4 @@
+-- Allow HTTPS, SVCB, TLSA, SSHFP, DS and NAPTR records
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_type_check;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_type_check
+    CHECK (type IN ('A', 'AAAA', 'CNAME', 'MX', 'TXT', 'NS', 'SRV', 'CAA', 'HTTPS', 'SVCB', 'TLSA', 'SSHFP', 'DS', 'NAPTR'));
@@ -0,0 +1,7 @@
+-- Allow URL redirect pseudo records
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_type_check;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_type_check
+    CHECK (type IN ('A', 'AAAA', 'CNAME', 'MX', 'TXT', 'NS', 'SRV', 'CAA', 'HTTPS', 'SVCB', 'TLSA', 'SSHFP', 'DS', 'NAPTR', 'URL'));
+
+-- Redirect service looks up URL records by host
+CREATE INDEX IF NOT EXISTS idx_dns_records_url ON dns_records(domain_id, name) WHERE type = 'URL' AND deleted_at IS NULL;
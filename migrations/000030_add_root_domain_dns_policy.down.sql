@@ -0,0 +1,7 @@
+-- Remove per-root-domain DNS policy
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_apex_only_types;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_allow_wildcard;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_max_records;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_max_ttl;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_min_ttl;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS dns_allowed_types;
@@ -0,0 +1,7 @@
+-- Add per-root-domain DNS policy
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_allowed_types TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_min_ttl INTEGER NOT NULL DEFAULT 60;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_max_ttl INTEGER NOT NULL DEFAULT 86400;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_max_records INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_allow_wildcard BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS dns_apex_only_types TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+-- Drop dns_templates table
+DROP TABLE IF EXISTS dns_templates;
@@ -0,0 +1,63 @@
+-- Create dns_templates table
+CREATE TABLE IF NOT EXISTS dns_templates (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(32) NOT NULL CHECK (category IN ('mail', 'verification', 'website', 'other')),
+    variables JSONB NOT NULL DEFAULT '[]',
+    records JSONB NOT NULL,
+    is_builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dns_templates_category ON dns_templates(category);
+
+-- Built-in templates
+INSERT INTO dns_templates (slug, name, description, category, variables, records, is_builtin) VALUES
+('google-workspace', 'Google Workspace', 'Receive mail with Google Workspace (Gmail) and publish its SPF policy.', 'mail',
+ '[]',
+ '[{"name":"{{subdomain}}","type":"MX","content":"smtp.google.com","priority":1},
+   {"name":"{{subdomain}}","type":"TXT","content":"v=spf1 include:_spf.google.com ~all"}]',
+ TRUE),
+('microsoft-365', 'Microsoft 365', 'Exchange Online mail routing, SPF and Outlook autodiscover.', 'mail',
+ '[{"name":"mx_token","label":"MX token","description":"The part before .mail.protection.outlook.com shown in the Microsoft 365 admin center","required":true}]',
+ '[{"name":"{{subdomain}}","type":"MX","content":"{{mx_token}}.mail.protection.outlook.com","priority":0},
+   {"name":"{{subdomain}}","type":"TXT","content":"v=spf1 include:spf.protection.outlook.com -all"},
+   {"name":"autodiscover.{{subdomain}}","type":"CNAME","content":"autodiscover.outlook.com"}]',
+ TRUE),
+('zoho-mail', 'Zoho Mail', 'Zoho Mail MX records and SPF policy.', 'mail',
+ '[]',
+ '[{"name":"{{subdomain}}","type":"MX","content":"mx.zoho.com","priority":10},
+   {"name":"{{subdomain}}","type":"MX","content":"mx2.zoho.com","priority":20},
+   {"name":"{{subdomain}}","type":"MX","content":"mx3.zoho.com","priority":50},
+   {"name":"{{subdomain}}","type":"TXT","content":"v=spf1 include:zoho.com ~all"}]',
+ TRUE),
+('self-hosted-mail', 'Self-hosted mail server', 'MX, SPF and a monitoring DMARC policy for your own mail server.', 'mail',
+ '[{"name":"ip","label":"Mail server IPv4 address","required":true},
+   {"name":"dmarc_email","label":"DMARC report address","description":"Mailbox that receives aggregate DMARC reports","required":true}]',
+ '[{"name":"mail.{{subdomain}}","type":"A","content":"{{ip}}"},
+   {"name":"{{subdomain}}","type":"MX","content":"mail.{{host}}","priority":10},
+   {"name":"{{subdomain}}","type":"TXT","content":"v=spf1 mx -all"},
+   {"name":"_dmarc.{{subdomain}}","type":"TXT","content":"v=DMARC1; p=none; rua=mailto:{{dmarc_email}}"}]',
+ TRUE),
+('google-site-verification', 'Google site verification', 'Verify ownership for Google Search Console and other Google services.', 'verification',
+ '[{"name":"token","label":"Verification token","description":"The value after google-site-verification=","required":true}]',
+ '[{"name":"{{subdomain}}","type":"TXT","content":"google-site-verification={{token}}"}]',
+ TRUE),
+('github-pages', 'GitHub Pages', 'Point the domain at GitHub Pages and verify it for your account or organization.', 'website',
+ '[{"name":"owner","label":"GitHub user or organization","required":true},
+   {"name":"token","label":"Verification code","required":true}]',
+ '[{"name":"{{subdomain}}","type":"A","content":"185.199.108.153"},
+   {"name":"{{subdomain}}","type":"A","content":"185.199.109.153"},
+   {"name":"{{subdomain}}","type":"A","content":"185.199.110.153"},
+   {"name":"{{subdomain}}","type":"A","content":"185.199.111.153"},
+   {"name":"_github-pages-challenge-{{owner}}.{{subdomain}}","type":"TXT","content":"{{token}}"}]',
+ TRUE),
+('website', 'Website', 'Point the name and its www alias at a web server.', 'website',
+ '[{"name":"ip","label":"Web server IPv4 address","required":true}]',
+ '[{"name":"{{subdomain}}","type":"A","content":"{{ip}}"},
+   {"name":"www.{{subdomain}}","type":"CNAME","content":"{{host}}"}]',
+ TRUE);
@@ -0,0 +1,5 @@
+-- Remove DNS failover
+DROP INDEX IF EXISTS idx_dns_records_failover_group_id;
+ALTER TABLE dns_records DROP COLUMN IF EXISTS failover_group_id;
+DROP TABLE IF EXISTS dns_failover_events;
+DROP TABLE IF EXISTS dns_failover_groups;
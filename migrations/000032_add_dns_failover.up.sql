@@ -0,0 +1,46 @@
+-- Create dns_failover_groups table
+CREATE TABLE IF NOT EXISTS dns_failover_groups (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    record_id INTEGER,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('A', 'AAAA')),
+    ttl INTEGER NOT NULL DEFAULT 60,
+    primary_target VARCHAR(64) NOT NULL,
+    backups JSONB NOT NULL DEFAULT '[]',
+    active_target VARCHAR(64) NOT NULL,
+    probe_type VARCHAR(10) NOT NULL CHECK (probe_type IN ('http', 'https', 'tcp')),
+    probe_port INTEGER NOT NULL,
+    probe_path VARCHAR(255) NOT NULL DEFAULT '',
+    probe_host VARCHAR(255) NOT NULL DEFAULT '',
+    check_interval INTEGER NOT NULL DEFAULT 60,
+    fail_threshold INTEGER NOT NULL DEFAULT 3,
+    recover_threshold INTEGER NOT NULL DEFAULT 2,
+    auto_failback BOOLEAN NOT NULL DEFAULT TRUE,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    target_health JSONB NOT NULL DEFAULT '{}',
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (domain_id, name, type)
+);
+
+-- Create dns_failover_events table
+CREATE TABLE IF NOT EXISTS dns_failover_events (
+    id SERIAL PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES dns_failover_groups(id) ON DELETE CASCADE,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    from_target VARCHAR(64) NOT NULL DEFAULT '',
+    to_target VARCHAR(64) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Mark DNS records managed by a failover group
+ALTER TABLE dns_records ADD COLUMN IF NOT EXISTS failover_group_id INTEGER REFERENCES dns_failover_groups(id) ON DELETE SET NULL;
+
+-- Create indexes
+CREATE INDEX idx_dns_failover_groups_domain_id ON dns_failover_groups(domain_id);
+CREATE INDEX idx_dns_failover_events_group_id ON dns_failover_events(group_id);
+CREATE INDEX idx_dns_failover_events_domain_id ON dns_failover_events(domain_id);
+CREATE INDEX idx_dns_records_failover_group_id ON dns_records(failover_group_id) WHERE failover_group_id IS NOT NULL;
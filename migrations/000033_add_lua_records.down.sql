@@ -0,0 +1,8 @@
+-- Drop LUA record switch
+ALTER TABLE root_domains DROP COLUMN IF EXISTS lua_records_enabled;
+
+-- Restore dns_records type constraint
+DELETE FROM dns_records WHERE type = 'LUA';
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_type_check;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_type_check
+    CHECK (type IN ('A', 'AAAA', 'CNAME', 'MX', 'TXT', 'NS', 'SRV', 'CAA', 'HTTPS', 'SVCB', 'TLSA', 'SSHFP', 'DS', 'NAPTR', 'URL'));
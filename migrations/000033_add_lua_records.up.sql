@@ -0,0 +1,7 @@
+-- Allow PowerDNS LUA records
+ALTER TABLE dns_records DROP CONSTRAINT IF EXISTS dns_records_type_check;
+ALTER TABLE dns_records ADD CONSTRAINT dns_records_type_check
+    CHECK (type IN ('A', 'AAAA', 'CNAME', 'MX', 'TXT', 'NS', 'SRV', 'CAA', 'HTTPS', 'SVCB', 'TLSA', 'SSHFP', 'DS', 'NAPTR', 'URL', 'LUA'));
+
+-- LUA records require enable-lua-records on the PowerDNS side, so they are opt-in per root domain
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS lua_records_enabled BOOLEAN DEFAULT FALSE;
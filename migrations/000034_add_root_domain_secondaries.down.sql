@@ -0,0 +1,2 @@
+-- Drop root_domain_secondaries table
+DROP TABLE IF EXISTS root_domain_secondaries;
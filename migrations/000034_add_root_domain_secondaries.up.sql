@@ -0,0 +1,16 @@
+-- Create root_domain_secondaries table
+CREATE TABLE IF NOT EXISTS root_domain_secondaries (
+    id SERIAL PRIMARY KEY,
+    root_domain_id INTEGER NOT NULL REFERENCES root_domains(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(64) NOT NULL,
+    port INTEGER NOT NULL DEFAULT 53 CHECK (port BETWEEN 1 AND 65535),
+    tsig_key_name VARCHAR(255) NOT NULL DEFAULT '',
+    notify BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (root_domain_id, address, port)
+);
+
+-- Create indexes
+CREATE INDEX idx_root_domain_secondaries_root_domain_id ON root_domain_secondaries(root_domain_id);
@@ -0,0 +1,3 @@
+-- Drop domain transfer tables
+DROP TABLE IF EXISTS domain_histories;
+DROP TABLE IF EXISTS domain_transfers;
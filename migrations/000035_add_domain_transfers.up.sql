@@ -0,0 +1,34 @@
+-- Create domain_transfers table
+CREATE TABLE IF NOT EXISTS domain_transfers (
+    id SERIAL PRIMARY KEY,
+    domain_id INTEGER NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    from_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled', 'expired')),
+    message VARCHAR(500) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one pending transfer per domain
+CREATE UNIQUE INDEX idx_domain_transfers_pending ON domain_transfers(domain_id) WHERE status = 'pending';
+CREATE INDEX idx_domain_transfers_from_user_id ON domain_transfers(from_user_id);
+CREATE INDEX idx_domain_transfers_to_user_id ON domain_transfers(to_user_id);
+CREATE INDEX idx_domain_transfers_expires_at ON domain_transfers(expires_at) WHERE status = 'pending';
+
+-- Create domain_histories table
+CREATE TABLE IF NOT EXISTS domain_histories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    domain_id INTEGER NOT NULL,
+    domain_name VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    transfer_id INTEGER REFERENCES domain_transfers(id) ON DELETE SET NULL,
+    details TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_domain_histories_user_id ON domain_histories(user_id, created_at);
+CREATE INDEX idx_domain_histories_domain_id ON domain_histories(domain_id);
@@ -0,0 +1,10 @@
+-- Drop balance_transactions table
+DROP TABLE IF EXISTS balance_transactions;
+
+-- Drop auto-renewal state
+ALTER TABLE domains DROP COLUMN IF EXISTS auto_renew_error;
+ALTER TABLE domains DROP COLUMN IF EXISTS auto_renew_attempted_at;
+ALTER TABLE domains DROP COLUMN IF EXISTS auto_renew_failures;
+
+-- Drop account balance
+ALTER TABLE users DROP COLUMN IF EXISTS balance;
@@ -0,0 +1,21 @@
+-- Account balance used to pay for automatic renewals
+ALTER TABLE users ADD COLUMN IF NOT EXISTS balance DECIMAL(10,2) NOT NULL DEFAULT 0.00 CHECK (balance >= 0);
+
+-- Auto-renewal state per domain
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS auto_renew_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS auto_renew_attempted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS auto_renew_error TEXT;
+
+-- Create balance_transactions table
+CREATE TABLE IF NOT EXISTS balance_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL,
+    balance_after DECIMAL(10,2) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('admin_adjust', 'auto_renew')),
+    order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_balance_transactions_user_id ON balance_transactions(user_id, created_at);
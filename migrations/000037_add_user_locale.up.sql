@@ -0,0 +1,2 @@
+-- Preferred language for notification emails (expiry reminders)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'zh-CN';
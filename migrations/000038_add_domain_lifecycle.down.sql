@@ -0,0 +1,13 @@
+-- Restore the global unique constraint (fails if a released name was registered again)
+DROP INDEX IF EXISTS idx_domains_full_domain_live;
+ALTER TABLE domains ADD CONSTRAINT domains_full_domain_key UNIQUE (full_domain);
+
+ALTER TABLE root_domains DROP COLUMN IF EXISTS redemption_fee;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS pending_delete_days;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS redemption_period_days;
+ALTER TABLE root_domains DROP COLUMN IF EXISTS grace_period_days;
+
+UPDATE domains SET status = 'expired' WHERE status IN ('grace', 'redemption', 'pending_delete');
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_status_check;
+ALTER TABLE domains ADD CONSTRAINT domains_status_check
+    CHECK (status IN ('active', 'expired', 'suspended', 'deleted'));
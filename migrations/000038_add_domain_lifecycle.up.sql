@@ -0,0 +1,14 @@
+-- Expiry lifecycle: active -> grace -> redemption -> pending_delete -> released (soft-deleted)
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_status_check;
+ALTER TABLE domains ADD CONSTRAINT domains_status_check
+    CHECK (status IN ('active', 'expired', 'grace', 'redemption', 'pending_delete', 'suspended', 'deleted'));
+
+-- Lifecycle durations per root domain (defaults keep the previous 30-day deletion window)
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS grace_period_days INTEGER NOT NULL DEFAULT 15;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS redemption_period_days INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS pending_delete_days INTEGER NOT NULL DEFAULT 5;
+ALTER TABLE root_domains ADD COLUMN IF NOT EXISTS redemption_fee DECIMAL(10,2) NOT NULL DEFAULT 0.00;
+
+-- Released domains are soft-deleted, so only live rows need a unique name
+ALTER TABLE domains DROP CONSTRAINT IF EXISTS domains_full_domain_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_full_domain_live ON domains(full_domain) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Drop reserved_names table
+DROP TABLE IF EXISTS reserved_names;
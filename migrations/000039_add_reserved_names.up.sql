@@ -0,0 +1,47 @@
+-- Create reserved_names table: reserved and premium subdomain rules managed by admins
+CREATE TABLE IF NOT EXISTS reserved_names (
+    id SERIAL PRIMARY KEY,
+    pattern VARCHAR(255) NOT NULL,
+    match_type VARCHAR(16) NOT NULL DEFAULT 'exact' CHECK (match_type IN ('exact', 'prefix', 'regex')),
+    root_domain_id INTEGER REFERENCES root_domains(id) ON DELETE CASCADE,
+    tier VARCHAR(16) NOT NULL DEFAULT 'reserved' CHECK (tier IN ('reserved', 'premium')),
+    reason VARCHAR(32) NOT NULL DEFAULT 'system' CHECK (reason IN ('trademark', 'brand', 'system', 'premium')),
+    price_per_year DECIMAL(10, 2),
+    lifetime_price DECIMAL(10, 2),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (tier = 'reserved' OR price_per_year > 0)
+);
+
+-- One rule per pattern and scope (global rules use root_domain_id = 0)
+CREATE UNIQUE INDEX idx_reserved_names_rule ON reserved_names(pattern, match_type, COALESCE(root_domain_id, 0));
+CREATE INDEX idx_reserved_names_root_domain_id ON reserved_names(root_domain_id);
+
+-- Previously hardcoded blacklist
+INSERT INTO reserved_names (pattern, match_type, tier, reason) VALUES
+('admin', 'exact', 'reserved', 'system'),
+('root', 'exact', 'reserved', 'system'),
+('api', 'exact', 'reserved', 'system'),
+('www', 'exact', 'reserved', 'system'),
+('mail', 'exact', 'reserved', 'system'),
+('smtp', 'exact', 'reserved', 'system'),
+('ftp', 'exact', 'reserved', 'system'),
+('ssh', 'exact', 'reserved', 'system'),
+('dns', 'exact', 'reserved', 'system'),
+('test', 'exact', 'reserved', 'system'),
+('demo', 'exact', 'reserved', 'system'),
+('dev', 'exact', 'reserved', 'system'),
+('stage', 'exact', 'reserved', 'system'),
+('prod', 'exact', 'reserved', 'system'),
+('blog', 'exact', 'reserved', 'system'),
+('forum', 'exact', 'reserved', 'system'),
+('shop', 'exact', 'reserved', 'system'),
+('status', 'exact', 'reserved', 'system'),
+('support', 'exact', 'reserved', 'system'),
+('help', 'exact', 'reserved', 'system'),
+('docs', 'exact', 'reserved', 'system'),
+('cdn', 'exact', 'reserved', 'system'),
+('static', 'exact', 'reserved', 'system'),
+('assets', 'exact', 'reserved', 'system')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE domains DROP COLUMN IF EXISTS full_domain_unicode;
+ALTER TABLE domains DROP COLUMN IF EXISTS subdomain_unicode;
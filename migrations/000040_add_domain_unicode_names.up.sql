@@ -0,0 +1,5 @@
+-- Unicode (display) form of internationalized domain names; subdomain and full_domain keep the punycode form
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS subdomain_unicode VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE domains ADD COLUMN IF NOT EXISTS full_domain_unicode VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE domains SET subdomain_unicode = subdomain, full_domain_unicode = full_domain;
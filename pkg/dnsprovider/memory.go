@@ -0,0 +1,462 @@
+package dnsprovider
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"opendomain/pkg/powerdns"
+)
+
+// MemoryProvider 进程内的 DNS 后端，不对外提供解析，用于本地开发和测试
+// 行为尽量与 PowerDNS API 一致：PATCH 原子生效，zone 不存在时返回 not found 错误
+type MemoryProvider struct {
+	mu       sync.Mutex
+	zones    map[string]*memoryZone
+	tsigKeys map[string]powerdns.TSIGKey
+}
+
+type memoryZone struct {
+	serial         uint32
+	notifiedSerial uint32
+	rrsets         map[rrsetID]powerdns.RRset
+	keys           []powerdns.Cryptokey
+	nextKeyID      int
+	metadata       map[string][]string
+}
+
+type rrsetID struct {
+	name       string
+	recordType string
+}
+
+// dnssecAlgorithms 支持的 DNSSEC 算法：算法编号和公钥长度
+var dnssecAlgorithms = map[string]struct {
+	number  int
+	keySize int
+}{
+	"ECDSAP256SHA256": {13, 64},
+	"ECDSAP384SHA384": {14, 96},
+	"ED25519":         {15, 32},
+}
+
+// NewMemoryProvider 创建空的内存 DNS 后端
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{zones: make(map[string]*memoryZone), tsigKeys: make(map[string]powerdns.TSIGKey)}
+}
+
+// CreateZone 创建 zone，同时生成 SOA 和 apex NS 记录集
+func (m *MemoryProvider) CreateZone(domain string, nameservers []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	if _, exists := m.zones[zoneName]; exists {
+		return fmt.Errorf("zone %s already exists", zoneName)
+	}
+
+	zone := &memoryZone{serial: 1, rrsets: make(map[rrsetID]powerdns.RRset), nextKeyID: 1, metadata: make(map[string][]string)}
+	primary := "ns." + zoneName
+	if len(nameservers) > 0 {
+		primary = canonicalName(nameservers[0])
+	}
+	zone.rrsets[rrsetID{zoneName, "SOA"}] = powerdns.RRset{
+		Name:    zoneName,
+		Type:    "SOA",
+		TTL:     3600,
+		Records: []powerdns.Record{{Content: fmt.Sprintf("%s hostmaster.%s 1 10800 3600 604800 3600", primary, zoneName)}},
+	}
+	if len(nameservers) > 0 {
+		records := make([]powerdns.Record, 0, len(nameservers))
+		for _, ns := range nameservers {
+			records = append(records, powerdns.Record{Content: canonicalName(ns)})
+		}
+		zone.rrsets[rrsetID{zoneName, "NS"}] = powerdns.RRset{Name: zoneName, Type: "NS", TTL: 3600, Records: records}
+	}
+
+	m.zones[zoneName] = zone
+	return nil
+}
+
+// DeleteZone 删除 zone
+func (m *MemoryProvider) DeleteZone(domain string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	if _, exists := m.zones[zoneName]; !exists {
+		return zoneNotFound(zoneName)
+	}
+	delete(m.zones, zoneName)
+	return nil
+}
+
+// GetZone 获取 zone 的副本，记录集按名称和类型排序
+func (m *MemoryProvider) GetZone(domain string) (*powerdns.Zone, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return nil, zoneNotFound(zoneName)
+	}
+
+	result := &powerdns.Zone{
+		ID:             zoneName,
+		Name:           zoneName,
+		Kind:           "Master",
+		DNSsec:         len(zone.keys) > 0,
+		Serial:         zone.serial,
+		NotifiedSerial: zone.notifiedSerial,
+		EditedSerial:   zone.serial,
+		RRsets:         make([]powerdns.RRset, 0, len(zone.rrsets)),
+	}
+	for _, rrset := range zone.rrsets {
+		rrset.Records = append([]powerdns.Record(nil), rrset.Records...)
+		result.RRsets = append(result.RRsets, rrset)
+	}
+	sort.Slice(result.RRsets, func(i, j int) bool {
+		if result.RRsets[i].Name != result.RRsets[j].Name {
+			return result.RRsets[i].Name < result.RRsets[j].Name
+		}
+		return result.RRsets[i].Type < result.RRsets[j].Type
+	})
+	return result, nil
+}
+
+// SetRecords 替换某个 name+type 的完整记录集
+func (m *MemoryProvider) SetRecords(domain, name, recordType string, entries []powerdns.RecordEntry, ttl int) error {
+	return m.PatchRRsets(domain, []powerdns.RRset{powerdns.BuildRRset(name, recordType, entries, ttl)})
+}
+
+// DeleteRRset 删除某个 name+type 的所有记录
+func (m *MemoryProvider) DeleteRRset(domain, name, recordType string) error {
+	return m.PatchRRsets(domain, []powerdns.RRset{powerdns.BuildRRset(name, recordType, nil, 0)})
+}
+
+// PatchRRsets 先校验全部变更再统一应用，任一记录集无效时不做任何修改
+func (m *MemoryProvider) PatchRRsets(domain string, rrsets []powerdns.RRset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return zoneNotFound(zoneName)
+	}
+
+	for _, rrset := range rrsets {
+		name := canonicalName(rrset.Name)
+		if name != zoneName && !strings.HasSuffix(name, "."+zoneName) {
+			return fmt.Errorf("RRset %s IN %s: name is out of zone %s", name, rrset.Type, zoneName)
+		}
+		switch rrset.ChangeType {
+		case "DELETE":
+		case "REPLACE":
+			if len(rrset.Records) == 0 {
+				return fmt.Errorf("RRset %s IN %s: REPLACE requires at least one record", name, rrset.Type)
+			}
+		default:
+			return fmt.Errorf("RRset %s IN %s: unknown changetype %q", name, rrset.Type, rrset.ChangeType)
+		}
+	}
+
+	for _, rrset := range rrsets {
+		id := rrsetID{canonicalName(rrset.Name), strings.ToUpper(rrset.Type)}
+		if rrset.ChangeType == "DELETE" {
+			delete(zone.rrsets, id)
+			continue
+		}
+		zone.rrsets[id] = powerdns.RRset{
+			Name:    id.name,
+			Type:    id.recordType,
+			TTL:     rrset.TTL,
+			Records: append([]powerdns.Record(nil), rrset.Records...),
+		}
+	}
+	zone.serial++
+	return nil
+}
+
+// SetSubdomainDisabled 将子域名及其下级名称的记录设为 disabled/enabled（不包括 SOA/NS）
+func (m *MemoryProvider) SetSubdomainDisabled(rootDomain, subdomain string, disabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(rootDomain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return fmt.Errorf("failed to get zone: %w", zoneNotFound(zoneName))
+	}
+
+	suffix := canonicalName(subdomain)
+	for id, rrset := range zone.rrsets {
+		if id.recordType == "SOA" || id.recordType == "NS" {
+			continue
+		}
+		if id.name != suffix && !strings.HasSuffix(id.name, "."+suffix) {
+			continue
+		}
+		records := make([]powerdns.Record, len(rrset.Records))
+		for i, r := range rrset.Records {
+			records[i] = powerdns.Record{Content: r.Content, Disabled: disabled}
+		}
+		rrset.Records = records
+		zone.rrsets[id] = rrset
+	}
+	zone.serial++
+	return nil
+}
+
+// ListCryptokeys 获取 zone 的 DNSSEC 密钥
+func (m *MemoryProvider) ListCryptokeys(domain string) ([]powerdns.Cryptokey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return nil, zoneNotFound(zoneName)
+	}
+	return append([]powerdns.Cryptokey{}, zone.keys...), nil
+}
+
+// CreateCryptokey 生成随机公钥并计算 DNSKEY 和 DS，不会真正对 zone 签名
+func (m *MemoryProvider) CreateCryptokey(domain, keyType, algorithm string, bits int) (*powerdns.Cryptokey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return nil, zoneNotFound(zoneName)
+	}
+
+	alg, ok := dnssecAlgorithms[strings.ToUpper(algorithm)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+	}
+	flags := 257
+	switch keyType {
+	case "ksk", "csk":
+	case "zsk":
+		flags = 256
+	default:
+		return nil, fmt.Errorf("invalid key type %s", keyType)
+	}
+
+	publicKey := make([]byte, alg.keySize)
+	if _, err := rand.Read(publicKey); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	// DNSKEY RDATA: flags(2) protocol(1) algorithm(1) public key
+	rdata := make([]byte, 4, 4+len(publicKey))
+	binary.BigEndian.PutUint16(rdata, uint16(flags))
+	rdata[2] = 3
+	rdata[3] = byte(alg.number)
+	rdata = append(rdata, publicKey...)
+
+	key := powerdns.Cryptokey{
+		ID:        zone.nextKeyID,
+		Type:      "Cryptokey",
+		KeyType:   keyType,
+		Active:    true,
+		Published: true,
+		DNSKey:    fmt.Sprintf("%d 3 %d %s", flags, alg.number, base64.StdEncoding.EncodeToString(publicKey)),
+		Algorithm: strings.ToUpper(algorithm),
+		Bits:      alg.keySize * 4,
+	}
+	if flags == 257 {
+		digest := sha256.Sum256(append(wireName(zoneName), rdata...))
+		key.DS = []string{fmt.Sprintf("%d %d 2 %s", keyTag(rdata), alg.number, hex.EncodeToString(digest[:]))}
+	}
+
+	zone.nextKeyID++
+	zone.keys = append(zone.keys, key)
+	return &key, nil
+}
+
+// DeleteCryptokey 删除 zone 的 DNSSEC 密钥
+func (m *MemoryProvider) DeleteCryptokey(domain string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return zoneNotFound(zoneName)
+	}
+	for i, key := range zone.keys {
+		if key.ID == id {
+			zone.keys = append(zone.keys[:i], zone.keys[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("cryptokey %d not found", id)
+}
+
+// RectifyZone 内存后端不需要计算 DNSSEC 数据，只检查 zone 是否存在
+func (m *MemoryProvider) RectifyZone(domain string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	if _, exists := m.zones[zoneName]; !exists {
+		return zoneNotFound(zoneName)
+	}
+	return nil
+}
+
+// GetMetadata 获取 zone 某一类元数据，未设置时返回空列表
+func (m *MemoryProvider) GetMetadata(domain, kind string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return nil, zoneNotFound(zoneName)
+	}
+	return append([]string{}, zone.metadata[strings.ToUpper(kind)]...), nil
+}
+
+// SetMetadata 替换 zone 某一类元数据
+func (m *MemoryProvider) SetMetadata(domain, kind string, values []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return zoneNotFound(zoneName)
+	}
+	zone.metadata[strings.ToUpper(kind)] = append([]string(nil), values...)
+	return nil
+}
+
+// DeleteMetadata 删除 zone 某一类元数据
+func (m *MemoryProvider) DeleteMetadata(domain, kind string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return zoneNotFound(zoneName)
+	}
+	delete(zone.metadata, strings.ToUpper(kind))
+	return nil
+}
+
+// NotifyZone 内存后端不发送 NOTIFY，只记录当前 serial 为已通知
+func (m *MemoryProvider) NotifyZone(domain string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	zoneName := canonicalName(domain)
+	zone, exists := m.zones[zoneName]
+	if !exists {
+		return zoneNotFound(zoneName)
+	}
+	zone.notifiedSerial = zone.serial
+	return nil
+}
+
+// ListTSIGKeys 获取所有 TSIG 密钥，按名称排序，不含密钥内容
+func (m *MemoryProvider) ListTSIGKeys() ([]powerdns.TSIGKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]powerdns.TSIGKey, 0, len(m.tsigKeys))
+	for _, key := range m.tsigKeys {
+		key.Key = ""
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return keys, nil
+}
+
+// CreateTSIGKey 创建 TSIG 密钥，secret 为空时生成 32 字节随机密钥
+func (m *MemoryProvider) CreateTSIGKey(name, algorithm, secret string) (*powerdns.TSIGKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := canonicalName(name)
+	if _, exists := m.tsigKeys[id]; exists {
+		return nil, fmt.Errorf("a TSIG key with name %s already exists", name)
+	}
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate key: %w", err)
+		}
+		secret = base64.StdEncoding.EncodeToString(buf)
+	} else if _, err := base64.StdEncoding.DecodeString(secret); err != nil {
+		return nil, fmt.Errorf("TSIG key is not valid base64")
+	}
+
+	key := powerdns.TSIGKey{ID: id, Name: name, Algorithm: strings.ToLower(algorithm), Key: secret, Type: "TSIGKey"}
+	m.tsigKeys[id] = key
+	return &key, nil
+}
+
+// DeleteTSIGKey 删除 TSIG 密钥
+func (m *MemoryProvider) DeleteTSIGKey(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.tsigKeys[id]; !exists {
+		return fmt.Errorf("TSIG key %s not found", id)
+	}
+	delete(m.tsigKeys, id)
+	return nil
+}
+
+// zoneNotFound 返回与 PowerDNS 一致的 zone 不存在错误
+func zoneNotFound(zoneName string) error {
+	return fmt.Errorf("Could not find domain '%s' (404 not found)", zoneName)
+}
+
+// canonicalName 将名称转换为小写并以点结尾
+func canonicalName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// wireName 将名称编码为 DNS wire 格式
+func wireName(name string) []byte {
+	var buf []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		buf = append(buf, byte(len(label)))
+		buf = append(buf, label...)
+	}
+	return append(buf, 0)
+}
+
+// keyTag 按 RFC 4034 附录 B 计算 DNSKEY 的 key tag
+func keyTag(rdata []byte) int {
+	var ac uint32
+	for i, b := range rdata {
+		if i&1 == 1 {
+			ac += uint32(b)
+		} else {
+			ac += uint32(b) << 8
+		}
+	}
+	ac += ac >> 16 & 0xFFFF
+	return int(ac & 0xFFFF)
+}
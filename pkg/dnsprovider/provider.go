@@ -0,0 +1,72 @@
+package dnsprovider
+
+import (
+	"fmt"
+
+	"opendomain/pkg/powerdns"
+)
+
+// Provider DNS 后端接口，处理器只通过它操作权威 DNS
+// zone、记录集等数据结构沿用 PowerDNS API 的表示（名称以点结尾，内容为 presentation 格式）
+type Provider interface {
+	// CreateZone 创建 zone，nameservers 为 apex NS
+	CreateZone(domain string, nameservers []string) error
+	// DeleteZone 删除 zone 及其所有记录
+	DeleteZone(domain string) error
+	// GetZone 获取 zone 及其所有记录集
+	GetZone(domain string) (*powerdns.Zone, error)
+
+	// SetRecords 替换某个 name+type 的完整记录集，entries 为空时删除
+	SetRecords(domain, name, recordType string, entries []powerdns.RecordEntry, ttl int) error
+	// DeleteRRset 删除某个 name+type 的所有记录
+	DeleteRRset(domain, name, recordType string) error
+	// PatchRRsets 原子地提交多个记录集变更
+	PatchRRsets(domain string, rrsets []powerdns.RRset) error
+	// SetSubdomainDisabled 停用或恢复某个子域名下的所有记录
+	SetSubdomainDisabled(rootDomain, subdomain string, disabled bool) error
+
+	// ListCryptokeys 获取 zone 的 DNSSEC 密钥
+	ListCryptokeys(domain string) ([]powerdns.Cryptokey, error)
+	// CreateCryptokey 为 zone 创建 DNSSEC 密钥
+	CreateCryptokey(domain, keyType, algorithm string, bits int) (*powerdns.Cryptokey, error)
+	// DeleteCryptokey 删除 zone 的 DNSSEC 密钥
+	DeleteCryptokey(domain string, id int) error
+	// RectifyZone 修改密钥或委派后重新计算 DNSSEC 数据
+	RectifyZone(domain string) error
+
+	// GetMetadata 获取 zone 某一类元数据（如 ALLOW-AXFR-FROM）
+	GetMetadata(domain, kind string) ([]string, error)
+	// SetMetadata 替换 zone 某一类元数据
+	SetMetadata(domain, kind string, values []string) error
+	// DeleteMetadata 删除 zone 某一类元数据
+	DeleteMetadata(domain, kind string) error
+	// NotifyZone 向二级服务器发送 NOTIFY
+	NotifyZone(domain string) error
+
+	// ListTSIGKeys 获取所有 TSIG 密钥（不含密钥内容）
+	ListTSIGKeys() ([]powerdns.TSIGKey, error)
+	// CreateTSIGKey 创建 TSIG 密钥，secret 为空时自动生成
+	CreateTSIGKey(name, algorithm, secret string) (*powerdns.TSIGKey, error)
+	// DeleteTSIGKey 删除 TSIG 密钥
+	DeleteTSIGKey(id string) error
+}
+
+// PowerDNS 客户端是默认实现
+var _ Provider = (*powerdns.Client)(nil)
+
+// 支持的后端名称，对应配置 DNS_PROVIDER
+const (
+	NamePowerDNS = "powerdns"
+	NameMemory   = "memory"
+)
+
+// New 根据名称创建 DNS 后端，名称为空时使用 PowerDNS
+func New(name, apiURL, apiKey string) (Provider, error) {
+	switch name {
+	case "", NamePowerDNS:
+		return powerdns.NewClient(apiURL, apiKey), nil
+	case NameMemory:
+		return NewMemoryProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown DNS provider %q", name)
+}
@@ -0,0 +1,140 @@
+package idn
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+var (
+	// ErrInvalidLabel 标签不符合 IDNA2008 / UTS #46 规则
+	ErrInvalidLabel = errors.New("invalid domain label")
+	// ErrMixedScript 标签混用了不允许组合的文字
+	ErrMixedScript = errors.New("domain label mixes characters from different scripts")
+	// ErrConfusable 标签全部由与拉丁字母形似的其他文字字符组成
+	ErrConfusable = errors.New("domain label consists only of characters that look like Latin letters")
+)
+
+// lookup 用户输入的转换规则：UTS #46 非过渡处理（大小写、全角字符映射），按 IDNA2008 校验
+var lookup = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(true),
+	idna.VerifyDNSLength(true),
+	idna.Transitional(false),
+	idna.ValidateLabels(true),
+	idna.CheckHyphens(true),
+	idna.CheckJoiners(true),
+)
+
+// allowedScriptSets 可以在同一标签中组合的文字（UTS #39 Highly Restrictive）
+var allowedScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"}, // 日文
+	{"Latin", "Han", "Bopomofo"},             // 中文
+	{"Latin", "Han", "Hangul"},               // 韩文
+}
+
+// latinLookalikes 与拉丁小写字母形似的西里尔和希腊字母
+var latinLookalikes = map[string]string{
+	"Cyrillic": "аеорсухіјѕһԁԛԝӏүԍ",
+	"Greek":    "αικνορυχ",
+}
+
+// NormalizeLabel 将用户输入的单个标签（可以是 Unicode 或 punycode）转换为
+// 用于存储和 DNS 的 ASCII 形式以及用于显示的 Unicode 形式，并拒绝同形异义攻击
+func NormalizeLabel(label string) (ascii, display string, err error) {
+	ascii, err = lookup.ToASCII(strings.TrimSpace(label))
+	if err != nil || ascii == "" || strings.Contains(ascii, ".") {
+		return "", "", ErrInvalidLabel
+	}
+
+	display, err = lookup.ToUnicode(ascii)
+	if err != nil {
+		return "", "", ErrInvalidLabel
+	}
+	if err := CheckScripts(display); err != nil {
+		return "", "", err
+	}
+	return ascii, display, nil
+}
+
+// ToASCII 将完整域名转换为 ASCII 形式，用于查询
+func ToASCII(name string) (string, error) {
+	return lookup.ToASCII(name)
+}
+
+// ToUnicode 将 ASCII 域名转换为显示用的 Unicode 形式，无法转换时原样返回
+func ToUnicode(name string) string {
+	display, err := idna.Display.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return display
+}
+
+// CheckScripts 检查标签使用的文字：只允许单一文字或常见的中日韩与拉丁字母组合，
+// 并拒绝完全由形似拉丁字母的西里尔或希腊字母组成的标签
+func CheckScripts(label string) error {
+	scripts := make(map[string]bool)
+	for _, r := range label {
+		if script := scriptOf(r); script != "" {
+			scripts[script] = true
+		}
+	}
+
+	if len(scripts) > 1 && !scriptsAllowed(scripts) {
+		return ErrMixedScript
+	}
+
+	if len(scripts) == 1 {
+		for script, lookalikes := range latinLookalikes {
+			if scripts[script] && onlyLookalikes(label, lookalikes) {
+				return ErrConfusable
+			}
+		}
+	}
+	return nil
+}
+
+// scriptOf 返回字符所属的文字，数字、连字符等通用字符返回空字符串
+func scriptOf(r rune) string {
+	if unicode.In(r, unicode.Common, unicode.Inherited) {
+		return ""
+	}
+	if r < unicode.MaxASCII {
+		return "Latin"
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+func scriptsAllowed(scripts map[string]bool) bool {
+	for _, set := range allowedScriptSets {
+		matched := 0
+		for _, script := range set {
+			if scripts[script] {
+				matched++
+			}
+		}
+		if matched == len(scripts) {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyLookalikes 标签中的字母是否全部属于形似字符
+func onlyLookalikes(label, lookalikes string) bool {
+	for _, r := range label {
+		if unicode.IsLetter(r) && !strings.ContainsRune(lookalikes, r) {
+			return false
+		}
+	}
+	return true
+}
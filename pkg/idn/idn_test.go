@@ -0,0 +1,76 @@
+package idn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		ascii   string
+		display string
+		err     error
+	}{
+		{name: "ascii", input: "example", ascii: "example", display: "example"},
+		{name: "uppercase and fullwidth are mapped", input: "ＥｘａｍＰＬＥ", ascii: "example", display: "example"},
+		{name: "chinese", input: "中文", ascii: "xn--fiq228c", display: "中文"},
+		{name: "punycode input", input: "xn--fiq228c", ascii: "xn--fiq228c", display: "中文"},
+		{name: "han and hiragana", input: "東京とうきょう", ascii: "xn--p8jau0izgx56pgixb", display: "東京とうきょう"},
+		{name: "latin and han", input: "abc中文", ascii: "xn--abc-x68do18h", display: "abc中文"},
+		{name: "cyrillic word", input: "пример", ascii: "xn--e1afmkfd", display: "пример"},
+		{name: "cyrillic lookalikes", input: "аррӏе", err: ErrConfusable},
+		{name: "punycode of cyrillic lookalikes", input: "xn--80ak6aa92e", err: ErrConfusable},
+		{name: "greek lookalikes", input: "ορα", err: ErrConfusable},
+		{name: "latin and cyrillic", input: "pаypal", err: ErrMixedScript},
+		{name: "latin and greek", input: "gοogle", err: ErrMixedScript},
+		{name: "hangul and hiragana", input: "한국ひらがな", err: ErrMixedScript},
+		{name: "leading hyphen", input: "-example", err: ErrInvalidLabel},
+		{name: "multiple labels", input: "foo.bar", err: ErrInvalidLabel},
+		{name: "empty", input: " ", err: ErrInvalidLabel},
+		{name: "invalid punycode", input: "xn--zz", err: ErrInvalidLabel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ascii, display, err := NormalizeLabel(tt.input)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("NormalizeLabel(%q) error = %v, want %v", tt.input, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeLabel(%q) unexpected error: %v", tt.input, err)
+			}
+			if ascii != tt.ascii || display != tt.display {
+				t.Errorf("NormalizeLabel(%q) = (%q, %q), want (%q, %q)", tt.input, ascii, display, tt.ascii, tt.display)
+			}
+		})
+	}
+}
+
+func TestCheckScripts(t *testing.T) {
+	tests := []struct {
+		label string
+		err   error
+	}{
+		{label: "example-123", err: nil},
+		{label: "中文域名", err: nil},
+		{label: "日本語ドメイン", err: nil},
+		{label: "한국어", err: nil},
+		{label: "δοκιμή", err: nil},
+		{label: "аррӏе", err: ErrConfusable},
+		{label: "сосо", err: ErrConfusable},
+		{label: "xοх", err: ErrMixedScript},
+		{label: "中文ру", err: ErrMixedScript},
+		{label: "ไทยabc", err: ErrMixedScript},
+	}
+
+	for _, tt := range tests {
+		if err := CheckScripts(tt.label); !errors.Is(err, tt.err) {
+			t.Errorf("CheckScripts(%q) = %v, want %v", tt.label, err, tt.err)
+		}
+	}
+}
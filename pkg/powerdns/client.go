@@ -32,13 +32,15 @@ func NewClient(baseURL, apiKey string) *Client {
 
 // Zone 表示一个 DNS Zone
 type Zone struct {
-	ID          string   `json:"id,omitempty"`
-	Name        string   `json:"name"`
-	Kind        string   `json:"kind"` // Master, Slave, Native
-	DNSsec      bool     `json:"dnssec,omitempty"`
-	Serial      uint32   `json:"serial,omitempty"`
-	Nameservers []string `json:"nameservers,omitempty"`
-	RRsets      []RRset  `json:"rrsets,omitempty"`
+	ID             string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Kind           string   `json:"kind"` // Master, Slave, Native
+	DNSsec         bool     `json:"dnssec,omitempty"`
+	Serial         uint32   `json:"serial,omitempty"`
+	NotifiedSerial uint32   `json:"notified_serial,omitempty"` // 最近一次发送 NOTIFY 时的 serial
+	EditedSerial   uint32   `json:"edited_serial,omitempty"`
+	Nameservers    []string `json:"nameservers,omitempty"`
+	RRsets         []RRset  `json:"rrsets,omitempty"`
 }
 
 // RRset 表示资源记录集
@@ -119,28 +121,53 @@ func (c *Client) SetRecords(domain, name, recordType string, entries []RecordEnt
 	zoneName := ensureTrailingDot(domain)
 	recordName := ensureTrailingDot(name)
 
+	return c.patchRRset(zoneName, BuildRRset(recordName, recordType, entries, ttl))
+}
+
+// FormatContent 将数据库中的记录内容转换为 PowerDNS 要求的格式
+// MX 记录会带上优先级，CNAME/NS/MX 的目标会补全结尾的点
+func FormatContent(recordType, content string, priority *int) string {
+	if recordType == "MX" && priority != nil {
+		return fmt.Sprintf("%d %s", *priority, ensureTrailingDot(content))
+	}
+	if recordType == "CNAME" || recordType == "NS" || recordType == "MX" {
+		return ensureTrailingDot(content)
+	}
+	return content
+}
+
+// BuildRRset 构造替换某个 name+type 完整记录集的 RRset
+// entries 为空时构造删除该记录集的 RRset
+func BuildRRset(name, recordType string, entries []RecordEntry, ttl int) RRset {
+	if len(entries) == 0 {
+		return RRset{
+			Name:       ensureTrailingDot(name),
+			Type:       recordType,
+			ChangeType: "DELETE",
+		}
+	}
+
 	records := make([]Record, 0, len(entries))
 	for _, e := range entries {
-		var content string
-		if recordType == "MX" && e.Priority != nil {
-			content = fmt.Sprintf("%d %s", *e.Priority, ensureTrailingDot(e.Content))
-		} else if recordType == "CNAME" || recordType == "NS" || recordType == "MX" {
-			content = ensureTrailingDot(e.Content)
-		} else {
-			content = e.Content
-		}
-		records = append(records, Record{Content: content, Disabled: false})
+		records = append(records, Record{Content: FormatContent(recordType, e.Content, e.Priority), Disabled: false})
 	}
 
-	rrset := RRset{
-		Name:       recordName,
+	return RRset{
+		Name:       ensureTrailingDot(name),
 		Type:       recordType,
 		TTL:        ttl,
 		ChangeType: "REPLACE",
 		Records:    records,
 	}
+}
 
-	return c.patchRRset(zoneName, rrset)
+// PatchRRsets 在一次 PATCH 请求中提交多个 RRset 变更
+// PowerDNS 对单次 PATCH 是原子的：任一 RRset 无效时整个请求都不会生效
+func (c *Client) PatchRRsets(domain string, rrsets []RRset) error {
+	if len(rrsets) == 0 {
+		return nil
+	}
+	return c.patchRRsets(ensureTrailingDot(domain), rrsets)
 }
 
 // DeleteRRset 删除某个 name+type 的所有记录
@@ -0,0 +1,78 @@
+package powerdns
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Cryptokey 表示 zone 的 DNSSEC 密钥
+type Cryptokey struct {
+	ID        int      `json:"id,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	KeyType   string   `json:"keytype"` // ksk, zsk, csk
+	Active    bool     `json:"active"`
+	Published bool     `json:"published"`
+	DNSKey    string   `json:"dnskey,omitempty"`
+	DS        []string `json:"ds,omitempty"`
+	Algorithm string   `json:"algorithm,omitempty"`
+	Bits      int      `json:"bits,omitempty"`
+}
+
+// ListCryptokeys 获取 zone 的所有 DNSSEC 密钥（包含 DNSKEY 和 DS）
+func (c *Client) ListCryptokeys(domain string) ([]Cryptokey, error) {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/cryptokeys", c.BaseURL, c.ServerID, ensureTrailingDot(domain))
+
+	respBody, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Cryptokey
+	if err := json.Unmarshal(respBody, &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cryptokeys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateCryptokey 为 zone 创建 DNSSEC 密钥
+// keyType 为 ksk/zsk/csk，algorithm 如 ECDSAP256SHA256，bits 为 0 时使用算法默认值
+func (c *Client) CreateCryptokey(domain, keyType, algorithm string, bits int) (*Cryptokey, error) {
+	key := &Cryptokey{
+		KeyType:   keyType,
+		Active:    true,
+		Published: true,
+		Algorithm: algorithm,
+		Bits:      bits,
+	}
+
+	body, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cryptokey: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/cryptokeys", c.BaseURL, c.ServerID, ensureTrailingDot(domain))
+	respBody, err := c.doRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Cryptokey
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cryptokey: %w", err)
+	}
+	return &created, nil
+}
+
+// DeleteCryptokey 删除 zone 的 DNSSEC 密钥
+func (c *Client) DeleteCryptokey(domain string, id int) error {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/cryptokeys/%d", c.BaseURL, c.ServerID, ensureTrailingDot(domain), id)
+	_, err := c.doRequest("DELETE", url, nil)
+	return err
+}
+
+// RectifyZone 重新计算 zone 的 DNSSEC 排序和认证数据，修改密钥后需要调用
+func (c *Client) RectifyZone(domain string) error {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/rectify", c.BaseURL, c.ServerID, ensureTrailingDot(domain))
+	_, err := c.doRequest("PUT", url, nil)
+	return err
+}
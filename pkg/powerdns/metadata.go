@@ -0,0 +1,116 @@
+package powerdns
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 二级 DNS（AXFR/NOTIFY）相关的 zone 元数据类型
+const (
+	MetadataAllowAXFRFrom = "ALLOW-AXFR-FROM"
+	MetadataAlsoNotify    = "ALSO-NOTIFY"
+	MetadataTSIGAllowAXFR = "TSIG-ALLOW-AXFR"
+)
+
+// Metadata 表示 zone 的一项元数据
+type Metadata struct {
+	Kind     string   `json:"kind"`
+	Metadata []string `json:"metadata"`
+}
+
+// TSIGKey 表示服务器上的 TSIG 密钥，列表接口不返回 Key
+type TSIGKey struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	Key       string `json:"key,omitempty"` // base64，为空时由 PowerDNS 生成
+	Type      string `json:"type,omitempty"`
+}
+
+// GetMetadata 获取 zone 某一类元数据的值，未设置时返回空列表
+func (c *Client) GetMetadata(domain, kind string) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/metadata/%s", c.BaseURL, c.ServerID, ensureTrailingDot(domain), kind)
+
+	respBody, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal(respBody, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	if metadata.Metadata == nil {
+		return []string{}, nil
+	}
+	return metadata.Metadata, nil
+}
+
+// SetMetadata 替换 zone 某一类元数据的全部值
+func (c *Client) SetMetadata(domain, kind string, values []string) error {
+	body, err := json.Marshal(&Metadata{Kind: kind, Metadata: values})
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/metadata/%s", c.BaseURL, c.ServerID, ensureTrailingDot(domain), kind)
+	_, err = c.doRequest("PUT", url, body)
+	return err
+}
+
+// DeleteMetadata 删除 zone 某一类元数据
+func (c *Client) DeleteMetadata(domain, kind string) error {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/metadata/%s", c.BaseURL, c.ServerID, ensureTrailingDot(domain), kind)
+	_, err := c.doRequest("DELETE", url, nil)
+	return err
+}
+
+// NotifyZone 向 zone 的所有二级服务器（NS 记录和 ALSO-NOTIFY）发送 NOTIFY，zone 类型需为 Master
+func (c *Client) NotifyZone(domain string) error {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/zones/%s/notify", c.BaseURL, c.ServerID, ensureTrailingDot(domain))
+	_, err := c.doRequest("PUT", url, nil)
+	return err
+}
+
+// ListTSIGKeys 获取服务器上的所有 TSIG 密钥（不含密钥内容）
+func (c *Client) ListTSIGKeys() ([]TSIGKey, error) {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/tsigkeys", c.BaseURL, c.ServerID)
+
+	respBody, err := c.doRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []TSIGKey
+	if err := json.Unmarshal(respBody, &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tsigkeys: %w", err)
+	}
+	return keys, nil
+}
+
+// CreateTSIGKey 创建 TSIG 密钥，secret 为空时由 PowerDNS 生成，返回值包含密钥内容
+func (c *Client) CreateTSIGKey(name, algorithm, secret string) (*TSIGKey, error) {
+	body, err := json.Marshal(&TSIGKey{Name: name, Algorithm: algorithm, Key: secret})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tsigkey: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/servers/%s/tsigkeys", c.BaseURL, c.ServerID)
+	respBody, err := c.doRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var created TSIGKey
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tsigkey: %w", err)
+	}
+	return &created, nil
+}
+
+// DeleteTSIGKey 删除 TSIG 密钥，id 为 ListTSIGKeys 返回的 ID
+func (c *Client) DeleteTSIGKey(id string) error {
+	url := fmt.Sprintf("%s/api/v1/servers/%s/tsigkeys/%s", c.BaseURL, c.ServerID, id)
+	_, err := c.doRequest("DELETE", url, nil)
+	return err
+}
@@ -0,0 +1,406 @@
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record 表示 zone 文件中的一条资源记录
+type Record struct {
+	Name  string // 完整域名，以点结尾
+	TTL   int
+	Class string
+	Type  string
+	Data  string // presentation 格式的 RDATA
+}
+
+// line 表示一条逻辑行（括号内的换行已合并）
+type line struct {
+	number   int
+	tokens   []string
+	indented bool // 以空白开头，表示沿用上一条记录的 owner
+}
+
+// Parse 解析 RFC 1035 格式的 zone 文件
+// origin 为默认的 $ORIGIN，defaultTTL 在文件未指定 $TTL 且记录未写 TTL 时使用
+// 不支持 $INCLUDE 和 $GENERATE 指令
+func Parse(r io.Reader, origin string, defaultTTL int) ([]Record, error) {
+	lines, err := splitLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	origin = canonicalName(origin)
+	ttl := defaultTTL
+	lastOwner := ""
+	var records []Record
+
+	for _, l := range lines {
+		if len(l.tokens) == 0 {
+			continue
+		}
+
+		// 指令
+		if strings.HasPrefix(l.tokens[0], "$") && !l.indented {
+			switch strings.ToUpper(l.tokens[0]) {
+			case "$ORIGIN":
+				if len(l.tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN requires a domain name", l.number)
+				}
+				origin = qualify(l.tokens[1], origin)
+			case "$TTL":
+				if len(l.tokens) < 2 {
+					return nil, fmt.Errorf("line %d: $TTL requires a value", l.number)
+				}
+				v, ok := parseTTL(l.tokens[1])
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid $TTL value %q", l.number, l.tokens[1])
+				}
+				ttl = v
+			default:
+				return nil, fmt.Errorf("line %d: unsupported directive %s", l.number, l.tokens[0])
+			}
+			continue
+		}
+
+		tokens := l.tokens
+		owner := lastOwner
+		if !l.indented {
+			owner = qualify(tokens[0], origin)
+			tokens = tokens[1:]
+		}
+		if owner == "" {
+			return nil, fmt.Errorf("line %d: record has no owner name", l.number)
+		}
+
+		recordTTL := ttl
+		class := "IN"
+		// TTL 和 class 可以以任意顺序出现在类型之前
+		for i := 0; i < 2 && len(tokens) > 0; i++ {
+			if v, ok := parseTTL(tokens[0]); ok {
+				recordTTL = v
+				tokens = tokens[1:]
+			} else if isClass(tokens[0]) {
+				class = strings.ToUpper(tokens[0])
+				tokens = tokens[1:]
+			}
+		}
+
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("line %d: missing record type", l.number)
+		}
+		recordType := strings.ToUpper(tokens[0])
+		if !isValidTypeToken(recordType) {
+			return nil, fmt.Errorf("line %d: invalid record type %q", l.number, tokens[0])
+		}
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("line %d: %s record has no data", l.number, recordType)
+		}
+
+		records = append(records, Record{
+			Name:  owner,
+			TTL:   recordTTL,
+			Class: class,
+			Type:  recordType,
+			Data:  qualifyData(recordType, tokens[1:], origin),
+		})
+		lastOwner = owner
+	}
+
+	return records, nil
+}
+
+// standardTypes BIND 等标准实现能够解析的记录类型
+var standardTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CNAME": true, "DNAME": true, "DNSKEY": true, "DS": true,
+	"HTTPS": true, "MX": true, "NAPTR": true, "NS": true, "PTR": true, "SOA": true, "SRV": true,
+	"SSHFP": true, "SVCB": true, "TLSA": true, "TXT": true,
+}
+
+// IsStandardType 判断记录类型能否写入标准 zone 文件
+func IsStandardType(recordType string) bool {
+	return standardTypes[strings.ToUpper(recordType)]
+}
+
+// Write 将记录写为 zone 文件，owner 相对 origin 输出
+// 非标准类型（如 PowerDNS 专有类型）以注释形式输出，保证文件能被 named-checkzone 等工具解析
+func Write(w io.Writer, origin string, defaultTTL int, records []Record) error {
+	origin = canonicalName(origin)
+
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			// apex 排在最前
+			if sorted[i].Name == origin {
+				return true
+			}
+			if sorted[j].Name == origin {
+				return false
+			}
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n", origin)
+	fmt.Fprintf(bw, "$TTL %d\n", defaultTTL)
+	for _, rec := range sorted {
+		class := rec.Class
+		if class == "" {
+			class = "IN"
+		}
+		prefix := ""
+		if !IsStandardType(rec.Type) {
+			prefix = "; "
+		}
+		fmt.Fprintf(bw, "%s%s\t%d\t%s\t%s\t%s\n", prefix, relativeName(rec.Name, origin), rec.TTL, class, rec.Type, rec.Data)
+	}
+	return bw.Flush()
+}
+
+// splitLines 将输入拆分为逻辑行，处理注释、引号和括号续行
+func splitLines(r io.Reader) ([]line, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zone file: %w", err)
+	}
+
+	var (
+		lines     []line
+		current   line
+		token     strings.Builder
+		inToken   bool
+		inQuote   bool
+		escaped   bool
+		literal   bool
+		inComment bool
+		depth     int
+		lineNo    = 1
+		atStart   = true
+	)
+	current.number = 1
+
+	flushToken := func() {
+		if inToken {
+			current.tokens = append(current.tokens, token.String())
+			token.Reset()
+			inToken = false
+		}
+	}
+
+	for _, ch := range string(data) {
+		if inComment {
+			if ch != '\n' {
+				continue
+			}
+			inComment = false
+		}
+
+		if literal {
+			// 引号外的转义字符（如 \;）原样保留
+			token.WriteRune(ch)
+			literal = false
+			atStart = false
+			continue
+		}
+
+		if inQuote {
+			token.WriteRune(ch)
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inQuote = false
+			case ch == '\n':
+				lineNo++
+			}
+			continue
+		}
+
+		switch ch {
+		case '\r':
+			continue
+		case ';':
+			flushToken()
+			inComment = true
+		case '"':
+			inToken = true
+			inQuote = true
+			token.WriteRune(ch)
+		case '(':
+			flushToken()
+			depth++
+		case ')':
+			flushToken()
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced parenthesis", lineNo)
+			}
+			depth--
+		case ' ', '\t':
+			if atStart && depth == 0 {
+				current.indented = true
+			}
+			flushToken()
+		case '\n':
+			flushToken()
+			lineNo++
+			if depth == 0 {
+				lines = append(lines, current)
+				current = line{number: lineNo}
+				atStart = true
+				continue
+			}
+		case '\\':
+			inToken = true
+			literal = true
+			token.WriteRune(ch)
+		default:
+			inToken = true
+			token.WriteRune(ch)
+		}
+		atStart = false
+	}
+
+	if inQuote {
+		return nil, fmt.Errorf("line %d: unterminated quoted string", lineNo)
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("line %d: unbalanced parenthesis", lineNo)
+	}
+	flushToken()
+	lines = append(lines, current)
+
+	return lines, nil
+}
+
+// qualifyData 将 RDATA 中的相对域名补全为完整域名
+func qualifyData(recordType string, fields []string, origin string) string {
+	out := make([]string, len(fields))
+	copy(out, fields)
+
+	qualifyAt := func(idx int) {
+		if idx < len(out) {
+			out[idx] = qualify(out[idx], origin)
+		}
+	}
+
+	switch recordType {
+	case "CNAME", "NS", "PTR", "DNAME":
+		qualifyAt(0)
+	case "MX":
+		qualifyAt(1)
+	case "SRV":
+		qualifyAt(3)
+	case "HTTPS", "SVCB":
+		qualifyAt(1)
+	case "NAPTR":
+		qualifyAt(5)
+	}
+
+	return strings.Join(out, " ")
+}
+
+// qualify 将相对名称补全为以点结尾的完整名称
+func qualify(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return strings.ToLower(name)
+	}
+	if origin == "" {
+		return strings.ToLower(name) + "."
+	}
+	return strings.ToLower(name) + "." + origin
+}
+
+// relativeName 将完整名称转换为相对 origin 的名称
+func relativeName(name, origin string) string {
+	name = canonicalName(name)
+	if name == origin {
+		return "@"
+	}
+	if strings.HasSuffix(name, "."+origin) {
+		return strings.TrimSuffix(name, "."+origin)
+	}
+	return name
+}
+
+// canonicalName 转为小写并确保以点结尾
+func canonicalName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// parseTTL 解析 TTL，支持 1h30m 这样的 BIND 单位写法
+func parseTTL(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if v, err := strconv.Atoi(s); err == nil {
+		return v, v >= 0
+	}
+
+	total := 0
+	num := ""
+	for _, ch := range strings.ToLower(s) {
+		if ch >= '0' && ch <= '9' {
+			num += string(ch)
+			continue
+		}
+		if num == "" {
+			return 0, false
+		}
+		n, _ := strconv.Atoi(num)
+		switch ch {
+		case 's':
+			total += n
+		case 'm':
+			total += n * 60
+		case 'h':
+			total += n * 3600
+		case 'd':
+			total += n * 86400
+		case 'w':
+			total += n * 604800
+		default:
+			return 0, false
+		}
+		num = ""
+	}
+	if num != "" {
+		return 0, false
+	}
+	return total, true
+}
+
+func isClass(s string) bool {
+	switch strings.ToUpper(s) {
+	case "IN", "CH", "CS", "HS":
+		return true
+	}
+	return false
+}
+
+func isValidTypeToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, ch := range s {
+		if !(ch >= 'A' && ch <= 'Z') && !(ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+	return s[0] >= 'A' && s[0] <= 'Z'
+}
@@ -0,0 +1,139 @@
+package zonefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		want []Record
+	}{
+		{
+			name: "origin and ttl directives",
+			zone: "$ORIGIN example.com.\n$TTL 1h\n@ IN A 192.0.2.1\nwww 300 IN CNAME @\n$ORIGIN sub.example.com.\napi IN A 192.0.2.2\n",
+			want: []Record{
+				{Name: "example.com.", TTL: 3600, Class: "IN", Type: "A", Data: "192.0.2.1"},
+				{Name: "www.example.com.", TTL: 300, Class: "IN", Type: "CNAME", Data: "example.com."},
+				{Name: "api.sub.example.com.", TTL: 3600, Class: "IN", Type: "A", Data: "192.0.2.2"},
+			},
+		},
+		{
+			name: "default origin and ttl",
+			zone: "www A 192.0.2.1\nmail.example.com. MX 10 mx\n",
+			want: []Record{
+				{Name: "www.example.com.", TTL: 600, Class: "IN", Type: "A", Data: "192.0.2.1"},
+				{Name: "mail.example.com.", TTL: 600, Class: "IN", Type: "MX", Data: "10 mx.example.com."},
+			},
+		},
+		{
+			name: "parentheses span lines",
+			zone: "@ IN SOA ns1 hostmaster (\n  2024010101 ; serial\n  3600 900\n  604800 300 )\n",
+			want: []Record{
+				{Name: "example.com.", TTL: 600, Class: "IN", Type: "SOA", Data: "ns1 hostmaster 2024010101 3600 900 604800 300"},
+			},
+		},
+		{
+			name: "quoted semicolon is not a comment",
+			zone: `@ TXT "v=spf1 -all; note" ; real comment` + "\n",
+			want: []Record{
+				{Name: "example.com.", TTL: 600, Class: "IN", Type: "TXT", Data: `"v=spf1 -all; note"`},
+			},
+		},
+		{
+			name: "implicit owner continues previous record",
+			zone: "www 300 IN A 192.0.2.1\n    300 IN A 192.0.2.2\n\tAAAA 2001:db8::1\n",
+			want: []Record{
+				{Name: "www.example.com.", TTL: 300, Class: "IN", Type: "A", Data: "192.0.2.1"},
+				{Name: "www.example.com.", TTL: 300, Class: "IN", Type: "A", Data: "192.0.2.2"},
+				{Name: "www.example.com.", TTL: 600, Class: "IN", Type: "AAAA", Data: "2001:db8::1"},
+			},
+		},
+		{
+			name: "class before ttl",
+			zone: "www IN 1d2h A 192.0.2.1\n",
+			want: []Record{
+				{Name: "www.example.com.", TTL: 93600, Class: "IN", Type: "A", Data: "192.0.2.1"},
+			},
+		},
+		{
+			name: "escaped semicolon outside quotes",
+			zone: `@ TXT a\;b` + "\n",
+			want: []Record{
+				{Name: "example.com.", TTL: 600, Class: "IN", Type: "TXT", Data: `a\;b`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.zone), "example.com", 600)
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		want string
+	}{
+		{name: "unbalanced open parenthesis", zone: "@ SOA ns1 hostmaster ( 1 2 3\n", want: "unbalanced parenthesis"},
+		{name: "unbalanced close parenthesis", zone: "@ A 192.0.2.1 )\n", want: "unbalanced parenthesis"},
+		{name: "unterminated quote", zone: "@ TXT \"abc\n", want: "unterminated quoted string"},
+		{name: "include directive", zone: "$INCLUDE other.zone\n", want: "unsupported directive"},
+		{name: "invalid ttl directive", zone: "$TTL soon\n", want: "invalid $TTL"},
+		{name: "implicit owner on first record", zone: "  A 192.0.2.1\n", want: "no owner"},
+		{name: "missing data", zone: "www A\n", want: "has no data"},
+		{name: "invalid type", zone: "www 300 IN a-record 192.0.2.1\n", want: "invalid record type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.zone), "example.com", 600)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	records := []Record{
+		{Name: "www.example.com.", TTL: 300, Type: "A", Data: "192.0.2.1"},
+		{Name: "go.example.com.", TTL: 300, Class: "IN", Type: "URL", Data: `"https://example.org/"`},
+		{Name: "example.com.", TTL: 3600, Class: "IN", Type: "MX", Data: "10 mail.example.com."},
+	}
+
+	var buf strings.Builder
+	if err := Write(&buf, "example.com", 3600, records); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	want := "$ORIGIN example.com.\n" +
+		"$TTL 3600\n" +
+		"@\t3600\tIN\tMX\t10 mail.example.com.\n" +
+		"; go\t300\tIN\tURL\t\"https://example.org/\"\n" +
+		"www\t300\tIN\tA\t192.0.2.1\n"
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// 导出的文件可以重新解析，注释输出的非标准类型被忽略
+	parsed, err := Parse(strings.NewReader(buf.String()), "example.com", 3600)
+	if err != nil {
+		t.Fatalf("Parse() of written zone error: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Type != "MX" || parsed[1].Type != "A" {
+		t.Errorf("round trip = %+v", parsed)
+	}
+}
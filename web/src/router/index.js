@@ -130,6 +130,11 @@ const routes = [
     name: 'PaymentFailure',
     component: () => import('../views/PaymentCallback.vue'),
   },
+  {
+    path: '/renew',
+    name: 'RenewConfirm',
+    component: () => import('../views/RenewConfirm.vue'),
+  },
   {
     path: '/admin/pages',
     name: 'AdminPages',